@@ -4,9 +4,12 @@ package rest
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -15,6 +18,8 @@ import (
 
 const defaultLimit = "10"
 
+const defaultHistoryLimit = 20
+
 type PingResponse struct {
 	Replies map[string]string `json:"replies"`
 }
@@ -75,12 +80,41 @@ func NewLoginHandler(log *slog.Logger, auth Authenticator) http.HandlerFunc {
 
 func NewUpdateHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if err := updater.Update(r.Context()); err != nil {
-			if code := status.Code(err); code == codes.AlreadyExists {
-				w.WriteHeader(http.StatusAccepted)
+		var opts core.UpdateOptions
+		for _, param := range []struct {
+			name string
+			flag *bool
+		}{
+			{"reconcile", &opts.Reconcile},
+			{"resume", &opts.Resume},
+			{"newest_first", &opts.NewestFirst},
+		} {
+			value := r.URL.Query().Get(param.name)
+			if value == "" {
+				continue
+			}
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				http.Error(w, "Unexpected '"+param.name+"' parameter", http.StatusBadRequest)
+				return
+			}
+			*param.flag = parsed
+		}
+
+		if value := r.URL.Query().Get("dry_run"); value != "" {
+			dryRun, err := strconv.ParseBool(value)
+			if err != nil {
+				http.Error(w, "Unexpected 'dry_run' parameter", http.StatusBadRequest)
+				return
+			}
+			if dryRun {
+				writePlan(log, w, r, updater, opts)
 				return
 			}
-			http.Error(w, "NewUpdateHandler:"+err.Error(), http.StatusInternalServerError)
+		}
+
+		if err := updater.Update(r.Context(), opts); err != nil {
+			writeUpdateError(w, "NewUpdateHandler", err)
 			return
 		}
 
@@ -88,6 +122,238 @@ func NewUpdateHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	}
 }
 
+// SourceRangeResponse - диапазон ID источника в БД, LatestID - номер
+// последнего комикса в самом источнике
+type SourceRangeResponse struct {
+	Source   string `json:"source"`
+	LatestID int    `json:"latest_id"`
+	From     int    `json:"from"`
+	To       int    `json:"to"`
+}
+
+type UpdatePlanResponse struct {
+	Mode       string                `json:"mode"`
+	Sources    []SourceRangeResponse `json:"sources"`
+	Missing    []int                 `json:"missing"`
+	Tombstoned []int                 `json:"tombstoned"`
+	Failed     []int                 `json:"failed"`
+	Estimate   string                `json:"estimate"`
+}
+
+// writePlan отвечает на POST /api/db/update?dry_run=true: план без записи в БД
+func writePlan(log *slog.Logger, w http.ResponseWriter, r *http.Request, updater core.Updater, opts core.UpdateOptions) {
+	plan, err := updater.Plan(r.Context(), opts)
+	if err != nil {
+		writeUpdateError(w, "NewUpdateHandler", err)
+		return
+	}
+
+	response := UpdatePlanResponse{
+		Mode:       plan.Mode,
+		Sources:    make([]SourceRangeResponse, 0, len(plan.Ranges)),
+		Missing:    plan.Missing,
+		Tombstoned: plan.Tombstoned,
+		Failed:     plan.Failed,
+		Estimate:   plan.Estimate.String(),
+	}
+	for _, rng := range plan.Ranges {
+		response.Sources = append(response.Sources, SourceRangeResponse{
+			Source:   rng.Source,
+			LatestID: rng.To - rng.From + 1,
+			From:     rng.From,
+			To:       rng.To,
+		})
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("NewUpdateHandler", "error", err)
+	}
+}
+
+func writeUpdateError(w http.ResponseWriter, handler string, err error) {
+	switch status.Code(err) {
+	case codes.AlreadyExists:
+		w.WriteHeader(http.StatusAccepted)
+	case codes.Canceled:
+		http.Error(w, "update cancelled", http.StatusConflict)
+	case codes.Unavailable:
+		http.Error(w, "xkcd is unavailable", http.StatusServiceUnavailable)
+	case codes.InvalidArgument:
+		http.Error(w, status.Convert(err).Message(), http.StatusBadRequest)
+	case codes.NotFound:
+		http.Error(w, status.Convert(err).Message(), http.StatusNotFound)
+	default:
+		http.Error(w, handler+":"+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type FailureResponse struct {
+	ID       int       `json:"id"`
+	Stage    string    `json:"stage"`
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+func NewFailuresHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		failures, err := updater.Failures(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		response := struct {
+			Failures []FailureResponse `json:"failures"`
+		}{
+			Failures: make([]FailureResponse, 0, len(failures)),
+		}
+		for _, failure := range failures {
+			response.Failures = append(response.Failures, FailureResponse{
+				ID:       failure.ID,
+				Stage:    failure.Stage,
+				Error:    failure.Error,
+				Attempts: failure.Attempts,
+				FailedAt: failure.FailedAt,
+			})
+		}
+
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error("NewFailuresHandler", "error", err)
+		}
+	}
+}
+
+func NewRetryFailedHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := updater.RetryFailed(r.Context()); err != nil {
+			writeUpdateError(w, "NewRetryFailedHandler", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+type RefreshResponse struct {
+	Checked int `json:"checked"`
+	Changed int `json:"changed"`
+}
+
+func NewRefreshHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var opts core.RefreshOptions
+
+		// границы диапазона необязательны, 0 - без ограничения
+		for name, bound := range map[string]*int{"from": &opts.From, "to": &opts.To} {
+			value := r.URL.Query().Get(name)
+			if value == "" {
+				continue
+			}
+			id, err := strconv.Atoi(value)
+			if err != nil || id < 0 {
+				http.Error(w, fmt.Sprintf("Unexpected '%s' parameter", name), http.StatusBadRequest)
+				return
+			}
+			*bound = id
+		}
+
+		result, err := updater.Refresh(r.Context(), opts)
+		if err != nil {
+			writeUpdateError(w, "NewRefreshHandler", err)
+			return
+		}
+
+		response := RefreshResponse{Checked: result.Checked, Changed: result.Changed}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error("NewRefreshHandler", "error", err)
+		}
+	}
+}
+
+type ImportResponse struct {
+	Imported int      `json:"imported"`
+	Skipped  int      `json:"skipped"`
+	Errors   []string `json:"errors"`
+}
+
+// countingWriter запоминает, начали ли мы уже отдавать тело ответа.
+type countingWriter struct {
+	w       io.Writer
+	written int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.written += int64(n)
+	return n, err
+}
+
+func NewExportHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", `attachment; filename="comics.ndjson.gz"`)
+
+		out := &countingWriter{w: w}
+		if err := updater.Export(r.Context(), out); err != nil {
+			// после начала передачи статус уже не поменять, клиент получит обрезанный gzip
+			if out.written > 0 {
+				log.Error("NewExportHandler", "error", err, "written", out.written)
+				return
+			}
+			w.Header().Del("Content-Disposition")
+			w.Header().Del("Content-Type")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+func NewImportHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// архив может загружаться дольше общего ReadTimeout сервера
+		_ = http.NewResponseController(w).SetReadDeadline(time.Time{})
+
+		result, err := updater.Import(r.Context(), r.Body)
+		if err != nil {
+			switch status.Code(err) {
+			case codes.AlreadyExists:
+				http.Error(w, "update is running", http.StatusConflict)
+			case codes.InvalidArgument:
+				http.Error(w, status.Convert(err).Message(), http.StatusBadRequest)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		response := ImportResponse{Imported: result.Imported, Skipped: result.Skipped, Errors: result.Errors}
+		if response.Errors == nil {
+			response.Errors = []string{}
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error("NewImportHandler", "error", err)
+		}
+	}
+}
+
+func NewCancelHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		state, err := updater.Cancel(r.Context())
+		if err != nil {
+			if code := status.Code(err); code == codes.NotFound {
+				http.Error(w, "update is not running", http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(updateStatusResponse(state)); err != nil {
+			log.Error("NewCancelHandler", "error", err)
+		}
+	}
+}
+
 func NewUpdateStatsHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		stats, err := updater.Stats(r.Context())
@@ -117,37 +383,503 @@ func NewUpdateStatsHandler(log *slog.Logger, updater core.Updater) http.HandlerF
 	}
 }
 
+type ProgressResponse struct {
+	Total      int        `json:"total"`
+	Fetched    int        `json:"fetched"`
+	Normalized int        `json:"normalized"`
+	Stored     int        `json:"stored"`
+	Failed     int        `json:"failed"`
+	Unchanged  int        `json:"unchanged"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+}
+
+type XKCDStateResponse struct {
+	Retries int               `json:"retries"`
+	Breaker core.BreakerState `json:"breaker"`
+}
+
+type StageResponse struct {
+	Workers    int     `json:"workers"`
+	Buffer     int     `json:"buffer"`
+	Queue      int     `json:"queue"`
+	Processed  int     `json:"processed"`
+	Throughput float64 `json:"throughput"`
+	Latency    string  `json:"latency"`
+}
+
+// PipelineResponse - метрики стадий конвейера: по очередям и задержкам видно,
+// что тормозит обновление - xkcd, words или Postgres
+type PipelineResponse struct {
+	Fetch StageResponse `json:"fetch"`
+	Norm  StageResponse `json:"norm"`
+	Add   StageResponse `json:"add"`
+}
+
+func stageResponse(stats core.StageStats) StageResponse {
+	return StageResponse{
+		Workers:    stats.Workers,
+		Buffer:     stats.Buffer,
+		Queue:      stats.Queue,
+		Processed:  stats.Processed,
+		Throughput: stats.Throughput,
+		Latency:    stats.Latency.Round(time.Millisecond).String(),
+	}
+}
+
+type UpdateStatusResponse struct {
+	Status   core.UpdateStatus  `json:"status"`
+	Progress ProgressResponse   `json:"progress"`
+	XKCD     XKCDStateResponse  `json:"xkcd"`
+	Replica  string             `json:"replica,omitempty"`
+	LastRun  *UpdateRunResponse `json:"last_run,omitempty"`
+	Pipeline *PipelineResponse  `json:"pipeline,omitempty"`
+	// Scheduled - планировщик назначил следующий запуск на NextRun
+	Scheduled bool       `json:"scheduled"`
+	NextRun   *time.Time `json:"next_run,omitempty"`
+}
+
 func NewUpdateStatusHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
-		status, err := updater.Status(r.Context())
+		state, err := updater.Status(r.Context())
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		if err := json.NewEncoder(w).Encode(map[string]core.UpdateStatus{"status": status}); err != nil {
+		if err := json.NewEncoder(w).Encode(updateStatusResponse(state)); err != nil {
 			log.Error("NewUpdateStatusHandler", "error", err)
 		}
 	}
 }
 
-func NewDropHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+// NewUpdateEventsHandler шлёт состояние обновления до отключения клиента или
+// закрытия done: иначе открытый поток не даёт серверу остановиться
+func NewUpdateEventsHandler(log *slog.Logger, updater core.Updater, done <-chan struct{}) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := updater.Drop(r.Context())
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
 
+		states, err := updater.WatchUpdate(r.Context())
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
 		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-done:
+				return
+			case state, ok := <-states:
+				if !ok {
+					return
+				}
+
+				data, err := json.Marshal(updateStatusResponse(state))
+				if err != nil {
+					log.Error("NewUpdateEventsHandler", "error", err)
+					return
+				}
+
+				if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+					log.Error("NewUpdateEventsHandler", "error", err)
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func updateStatusResponse(state core.UpdateState) UpdateStatusResponse {
+	response := UpdateStatusResponse{
+		Status: state.Status,
+		Progress: ProgressResponse{
+			Total:      state.Progress.Total,
+			Fetched:    state.Progress.Fetched,
+			Normalized: state.Progress.Normalized,
+			Stored:     state.Progress.Stored,
+			Failed:     state.Progress.Failed,
+			Unchanged:  state.Progress.Unchanged,
+		},
+		XKCD: XKCDStateResponse{
+			Retries: state.XKCD.Retries,
+			Breaker: state.XKCD.Breaker,
+		},
+		Replica:   state.Replica,
+		Scheduled: state.Scheduled,
+	}
+	if !state.NextRun.IsZero() {
+		response.NextRun = &state.NextRun
+	}
+	if !state.Progress.StartedAt.IsZero() {
+		response.Progress.StartedAt = &state.Progress.StartedAt
+	}
+	if state.LastRun != nil {
+		run := updateRunResponse(*state.LastRun)
+		response.LastRun = &run
+	}
+	// у сервиса обновления всегда есть воркеры загрузки, нет их - нет и метрик
+	if state.Pipeline.Fetch.Workers > 0 {
+		response.Pipeline = &PipelineResponse{
+			Fetch: stageResponse(state.Pipeline.Fetch),
+			Norm:  stageResponse(state.Pipeline.Norm),
+			Add:   stageResponse(state.Pipeline.Add),
+		}
+	}
+
+	return response
+}
+
+type UpdateRunResponse struct {
+	ID         int64     `json:"id"`
+	Mode       string    `json:"mode"`
+	Trigger    string    `json:"trigger"`
+	Status     string    `json:"status"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   string    `json:"duration"`
+	Stored     int       `json:"stored"`
+	Failed     int       `json:"failed"`
+	Skipped    int       `json:"skipped"`
+	Error      string    `json:"error,omitempty"`
+	Summary    string    `json:"summary"`
+}
+
+func updateRunResponse(run core.UpdateRun) UpdateRunResponse {
+	return UpdateRunResponse{
+		ID:         run.ID,
+		Mode:       run.Mode,
+		Trigger:    run.Trigger,
+		Status:     run.Status,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		Duration:   run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond).String(),
+		Stored:     run.Stored,
+		Failed:     run.Failed,
+		Skipped:    run.Skipped,
+		Error:      run.Error,
+		Summary:    fmt.Sprintf("stored %d, failed %d, skipped %d", run.Stored, run.Failed, run.Skipped),
+	}
+}
+
+type HistoryResponse struct {
+	Runs   []UpdateRunResponse `json:"runs"`
+	Total  int                 `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+// NewHistoryHandler отдаёт журнал запусков обновления: GET /api/db/updates?limit=&offset=
+func NewHistoryHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		opts := core.HistoryOptions{Limit: defaultHistoryLimit}
+
+		for name, value := range map[string]*int{"limit": &opts.Limit, "offset": &opts.Offset} {
+			param := r.URL.Query().Get(name)
+			if param == "" {
+				continue
+			}
+			n, err := strconv.Atoi(param)
+			if err != nil || n < 0 {
+				http.Error(w, fmt.Sprintf("Unexpected '%s' parameter", name), http.StatusBadRequest)
+				return
+			}
+			*value = n
+		}
+
+		history, err := updater.History(r.Context(), opts)
+		if err != nil {
+			if status.Code(err) == codes.InvalidArgument {
+				http.Error(w, status.Convert(err).Message(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		response := HistoryResponse{
+			Runs:   make([]UpdateRunResponse, 0, len(history.Runs)),
+			Total:  history.Total,
+			Limit:  opts.Limit,
+			Offset: opts.Offset,
+		}
+		for _, run := range history.Runs {
+			response.Runs = append(response.Runs, updateRunResponse(run))
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error("NewHistoryHandler", "error", err)
+		}
+	}
+}
+
+type DeleteResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// NewDeleteComicsHandler удаляет один комикс: DELETE /api/comics/{id}
+func NewDeleteComicsHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || id < 1 {
+			http.Error(w, "Unexpected comics id", http.StatusBadRequest)
+			return
+		}
+
+		deleted, err := updater.Delete(r.Context(), core.DeleteOptions{IDs: []int{id}})
+		if err != nil {
+			writeDeleteError(w, err)
+			return
+		}
+		if deleted == 0 {
+			http.Error(w, "comics is not found", http.StatusNotFound)
+			return
+		}
+
+		writeDeleteResponse(w, log, deleted)
+	}
+}
+
+// NewDeleteComicsRangeHandler удаляет комиксы from..to включительно: DELETE /api/comics?from=&to=
+func NewDeleteComicsRangeHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var opts core.DeleteOptions
+
+		// обе границы обязательны, чтобы случайно не удалить всё
+		for name, bound := range map[string]*int{"from": &opts.From, "to": &opts.To} {
+			id, err := strconv.Atoi(r.URL.Query().Get(name))
+			if err != nil || id < 1 {
+				http.Error(w, fmt.Sprintf("Unexpected '%s' parameter", name), http.StatusBadRequest)
+				return
+			}
+			*bound = id
+		}
+
+		deleted, err := updater.Delete(r.Context(), opts)
+		if err != nil {
+			writeDeleteError(w, err)
+			return
+		}
+
+		writeDeleteResponse(w, log, deleted)
+	}
+}
+
+func writeDeleteError(w http.ResponseWriter, err error) {
+	if status.Code(err) == codes.InvalidArgument {
+		http.Error(w, status.Convert(err).Message(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func writeDeleteResponse(w http.ResponseWriter, log *slog.Logger, deleted int) {
+	log.Info("comics deleted", "deleted", deleted)
+	if err := json.NewEncoder(w).Encode(DeleteResponse{Deleted: deleted}); err != nil {
+		log.Error("DeleteComics", "error", err)
+	}
+}
+
+type SnapshotResponse struct {
+	Name      string    `json:"name"`
+	Comics    int       `json:"comics"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DropResponse - снимок, по которому можно откатить Drop; null, если он не создавался
+type DropResponse struct {
+	Snapshot *SnapshotResponse `json:"snapshot"`
+}
+
+func snapshotResponse(snapshot core.Snapshot) SnapshotResponse {
+	return SnapshotResponse{Name: snapshot.Name, Comics: snapshot.Comics, CreatedAt: snapshot.CreatedAt}
+}
+
+func NewDropHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshot, err := updater.Drop(r.Context())
+
+		if err != nil {
+			writeSnapshotError(w, err)
+			return
+		}
+
+		var response DropResponse
+		if snapshot.Name != "" {
+			s := snapshotResponse(snapshot)
+			response.Snapshot = &s
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error("NewDropHandler", "error", err)
+		}
+	}
+}
+
+func NewSnapshotsHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshots, err := updater.Snapshots(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		response := make([]SnapshotResponse, 0, len(snapshots))
+		for _, snapshot := range snapshots {
+			response = append(response, snapshotResponse(snapshot))
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error("NewSnapshotsHandler", "error", err)
+		}
+	}
+}
+
+func NewRestoreHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if err := updater.Restore(r.Context(), name); err != nil {
+			writeSnapshotError(w, err)
+			return
+		}
+		log.Info("NewRestoreHandler", "snapshot", name)
+	}
+}
+
+func NewDeleteSnapshotHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if err := updater.DeleteSnapshot(r.Context(), name); err != nil {
+			writeSnapshotError(w, err)
+			return
+		}
+		log.Info("NewDeleteSnapshotHandler", "snapshot", name)
+	}
+}
+
+func writeSnapshotError(w http.ResponseWriter, err error) {
+	switch status.Code(err) {
+	case codes.NotFound:
+		http.Error(w, "snapshot is not found", http.StatusNotFound)
+	case codes.AlreadyExists:
+		http.Error(w, "update is running", http.StatusConflict)
+	case codes.InvalidArgument:
+		http.Error(w, status.Convert(err).Message(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type ScheduleResponse struct {
+	Period  string     `json:"period"`
+	Paused  bool       `json:"paused"`
+	NextRun *time.Time `json:"next_run,omitempty"`
+	LastRun *time.Time `json:"last_run,omitempty"`
+}
+
+func NewScheduleHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		schedule, err := updater.Schedule(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeSchedule(w, log, schedule)
+	}
+}
+
+func NewSetScheduleHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Period *string `json:"period"`
+			Paused *bool   `json:"paused"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Unexpected schedule body", http.StatusBadRequest)
+			return
+		}
+
+		settings := core.ScheduleSettings{Paused: request.Paused}
+
+		if request.Period != nil {
+			period, err := time.ParseDuration(*request.Period)
+			if err != nil {
+				http.Error(w, "Unexpected 'period' value", http.StatusBadRequest)
+				return
+			}
+			settings.Period = &period
+		}
+
+		schedule, err := updater.SetSchedule(r.Context(), settings)
+		if err != nil {
+			if code := status.Code(err); code == codes.InvalidArgument {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("SetScheduleHandler", "result", schedule)
+
+		writeSchedule(w, log, schedule)
+	}
+}
+
+func writeSchedule(w http.ResponseWriter, log *slog.Logger, schedule core.UpdateSchedule) {
+	response := ScheduleResponse{
+		Period: schedule.Period.String(),
+		Paused: schedule.Paused,
+	}
+	if !schedule.NextRun.IsZero() {
+		response.NextRun = &schedule.NextRun
+	}
+	if !schedule.LastRun.IsZero() {
+		response.LastRun = &schedule.LastRun
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("writeSchedule", "error", err)
 	}
 }
 
 type Comics struct {
-	ID  int    `json:"id"`
-	URL string `json:"url"`
+	ID         int    `json:"id"`
+	URL        string `json:"url"`
+	Title      string `json:"title,omitempty"`
+	Alt        string `json:"alt,omitempty"`
+	Transcript string `json:"transcript,omitempty"`
+	Link       string `json:"link,omitempty"`
+	News       string `json:"news,omitempty"`
+	Published  string `json:"published,omitempty"`
+	Source     string `json:"source,omitempty"`
+}
+
+func comicsResponse(comics core.Comics) Comics {
+	response := Comics{
+		ID:         comics.ID,
+		URL:        comics.URL,
+		Title:      comics.Title,
+		Alt:        comics.Alt,
+		Transcript: comics.Transcript,
+		Link:       comics.Link,
+		News:       comics.News,
+		Source:     comics.Source,
+	}
+	if !comics.Published.IsZero() {
+		response.Published = comics.Published.Format(time.DateOnly)
+	}
+	return response
 }
 
 type ComicsResponse struct {
@@ -191,7 +923,7 @@ func NewSearchHandler(log *slog.Logger, searcher core.Searcher) http.HandlerFunc
 		var comicsRespose ComicsResponse
 
 		for _, x := range comics {
-			comicsRespose.Comics = append(comicsRespose.Comics, Comics{ID: x.ID, URL: x.URL})
+			comicsRespose.Comics = append(comicsRespose.Comics, comicsResponse(x))
 		}
 		comicsRespose.Total = len(comicsRespose.Comics)
 
@@ -241,7 +973,7 @@ func NewSearchIndexHandler(log *slog.Logger, searcher core.Searcher) http.Handle
 		var comicsRespose ComicsResponse
 
 		for _, x := range comics {
-			comicsRespose.Comics = append(comicsRespose.Comics, Comics{ID: x.ID, URL: x.URL})
+			comicsRespose.Comics = append(comicsRespose.Comics, comicsResponse(x))
 		}
 		comicsRespose.Total = len(comicsRespose.Comics)
 
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"log/slog"
 
@@ -122,7 +124,7 @@ func TestUpdateHandler(t *testing.T) {
 	t.Run("StatusOK", func(t *testing.T) {
 		mockUpdater.
 			EXPECT().
-			Update(gomock.Any()).
+			Update(gomock.Any(), core.UpdateOptions{}).
 			Return(nil)
 
 		handler := NewUpdateHandler(logger, mockUpdater)
@@ -143,7 +145,7 @@ func TestUpdateHandler(t *testing.T) {
 
 		mockUpdater.
 			EXPECT().
-			Update(gomock.Any()).
+			Update(gomock.Any(), core.UpdateOptions{}).
 			Return(alreadyExistsErr)
 
 		handler := NewUpdateHandler(logger, mockUpdater)
@@ -160,7 +162,7 @@ func TestUpdateHandler(t *testing.T) {
 		mockUpdater = mock_port.NewMockUpdater(ctrl)
 		mockUpdater.
 			EXPECT().
-			Update(gomock.Any()).
+			Update(gomock.Any(), core.UpdateOptions{}).
 			Return(errors.New("internal server"))
 
 		handler := NewUpdateHandler(logger, mockUpdater)
@@ -173,6 +175,300 @@ func TestUpdateHandler(t *testing.T) {
 		ctrl.Finish()
 	})
 
+	t.Run("Cancelled", func(t *testing.T) {
+		ctrl = gomock.NewController(t)
+		mockUpdater = mock_port.NewMockUpdater(ctrl)
+		mockUpdater.
+			EXPECT().
+			Update(gomock.Any(), core.UpdateOptions{}).
+			Return(status.Error(codes.Canceled, "update cancelled"))
+
+		handler := NewUpdateHandler(logger, mockUpdater)
+
+		req := httptest.NewRequest(http.MethodPost, "/update", nil)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Result().StatusCode)
+		ctrl.Finish()
+	})
+
+	t.Run("XKCDUnavailable", func(t *testing.T) {
+		ctrl = gomock.NewController(t)
+		mockUpdater = mock_port.NewMockUpdater(ctrl)
+		mockUpdater.
+			EXPECT().
+			Update(gomock.Any(), core.UpdateOptions{}).
+			Return(status.Error(codes.Unavailable, "xkcd is unavailable"))
+
+		handler := NewUpdateHandler(logger, mockUpdater)
+
+		req := httptest.NewRequest(http.MethodPost, "/update", nil)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Result().StatusCode)
+		ctrl.Finish()
+	})
+
+}
+
+func TestFailuresHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failedAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		Failures(gomock.Any()).
+		Return([]core.Failure{
+			{ID: 404, Stage: "fetch", Error: "not found", Attempts: 2, FailedAt: failedAt},
+		}, nil)
+
+	handler := NewFailuresHandler(logger, mockUpdater)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/db/failures", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	var response struct {
+		Failures []FailureResponse `json:"failures"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	require.Equal(t, []FailureResponse{
+		{ID: 404, Stage: "fetch", Error: "not found", Attempts: 2, FailedAt: failedAt},
+	}, response.Failures)
+
+	mockUpdater.
+		EXPECT().
+		Failures(gomock.Any()).
+		Return(nil, errors.New("db error"))
+
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusInternalServerError, rec.Result().StatusCode)
+}
+
+func TestRetryFailedHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		RetryFailed(gomock.Any()).
+		Return(nil)
+
+	handler := NewRetryFailedHandler(logger, mockUpdater)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/db/failures/retry", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	mockUpdater.
+		EXPECT().
+		RetryFailed(gomock.Any()).
+		Return(status.Error(codes.AlreadyExists, "update already runs"))
+
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Result().StatusCode)
+}
+
+func TestRefreshHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		Refresh(gomock.Any(), core.RefreshOptions{From: 100, To: 200}).
+		Return(core.RefreshResult{Checked: 101, Changed: 3}, nil)
+
+	handler := NewRefreshHandler(logger, mockUpdater)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/db/refresh?from=100&to=200", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	var response RefreshResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	require.Equal(t, RefreshResponse{Checked: 101, Changed: 3}, response)
+
+	mockUpdater.
+		EXPECT().
+		Refresh(gomock.Any(), core.RefreshOptions{}).
+		Return(core.RefreshResult{}, status.Error(codes.AlreadyExists, "update already runs"))
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/db/refresh", nil))
+	require.Equal(t, http.StatusAccepted, rec.Result().StatusCode)
+
+	mockUpdater.
+		EXPECT().
+		Refresh(gomock.Any(), core.RefreshOptions{From: 200, To: 100}).
+		Return(core.RefreshResult{}, status.Error(codes.InvalidArgument, "wrong range"))
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/db/refresh?from=200&to=100", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+
+	for _, query := range []string{"from=abc", "to=-1"} {
+		rec = httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/api/db/refresh?"+query, nil))
+		require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+	}
+}
+
+func TestUpdateHandler_Reconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		Update(gomock.Any(), core.UpdateOptions{Reconcile: true}).
+		Return(nil)
+
+	handler := NewUpdateHandler(logger, mockUpdater)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/db/update?reconcile=true", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/db/update?reconcile=maybe", nil)
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+}
+
+func TestUpdateHandler_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		Update(gomock.Any(), core.UpdateOptions{Resume: true, NewestFirst: true}).
+		Return(nil)
+
+	handler := NewUpdateHandler(logger, mockUpdater)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/db/update?resume=true&newest_first=1", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	// нет прерванного обновления
+	mockUpdater.
+		EXPECT().
+		Update(gomock.Any(), core.UpdateOptions{Resume: true}).
+		Return(status.Error(codes.NotFound, "not found: no interrupted update to resume"))
+
+	req = httptest.NewRequest(http.MethodPost, "/api/db/update?resume=true", nil)
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Result().StatusCode)
+	require.Contains(t, rec.Body.String(), "no interrupted update to resume")
+
+	req = httptest.NewRequest(http.MethodPost, "/api/db/update?newest_first=maybe", nil)
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+	require.Contains(t, rec.Body.String(), "newest_first")
+}
+
+func TestUpdateHandler_DryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// при dry_run Update не вызывается
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		Plan(gomock.Any(), core.UpdateOptions{Reconcile: true}).
+		Return(core.UpdatePlan{
+			Mode:       "reconcile",
+			Ranges:     []core.SourceRange{{Source: "xkcd", From: 1, To: 3050}, {Source: "mirror", From: 100001, To: 100020}},
+			Missing:    []int{3050, 100020},
+			Tombstoned: []int{404},
+			Failed:     []int{3050},
+			Estimate:   90 * time.Second,
+		}, nil)
+
+	handler := NewUpdateHandler(logger, mockUpdater)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/db/update?dry_run=true&reconcile=true", nil))
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	require.JSONEq(t, `{
+		"mode": "reconcile",
+		"sources": [
+			{"source": "xkcd", "latest_id": 3050, "from": 1, "to": 3050},
+			{"source": "mirror", "latest_id": 20, "from": 100001, "to": 100020}
+		],
+		"missing": [3050, 100020],
+		"tombstoned": [404],
+		"failed": [3050],
+		"estimate": "1m30s"
+	}`, rec.Body.String())
+
+	mockUpdater.
+		EXPECT().
+		Plan(gomock.Any(), core.UpdateOptions{}).
+		Return(core.UpdatePlan{}, status.Error(codes.Unavailable, "xkcd is unavailable"))
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/db/update?dry_run=1", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Result().StatusCode)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/db/update?dry_run=maybe", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+}
+
+func TestCancelHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		Cancel(gomock.Any()).
+		Return(core.UpdateState{
+			Status:   core.StatusUpdateCancelled,
+			Progress: core.UpdateProgress{Total: 10, Stored: 4},
+		}, nil)
+
+	handler := NewCancelHandler(logger, mockUpdater)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/db/update", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	var response UpdateStatusResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	require.Equal(t, core.StatusUpdateCancelled, response.Status)
+	require.Equal(t, 4, response.Progress.Stored)
+
+	mockUpdater.
+		EXPECT().
+		Cancel(gomock.Any()).
+		Return(core.UpdateState{}, status.Error(codes.NotFound, "update is not running"))
+
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+
+	require.Equal(t, http.StatusNotFound, rec.Result().StatusCode)
 }
 
 // Тестирование UpdateStatsHandler
@@ -230,7 +526,11 @@ func TestUpdateStatusHandler(t *testing.T) {
 	mockUpdater.
 		EXPECT().
 		Status(gomock.Any()).
-		Return(core.StatusUpdateRunning, nil)
+		Return(core.UpdateState{
+			Status:   core.StatusUpdateRunning,
+			Progress: core.UpdateProgress{Total: 10, Stored: 4, Failed: 1},
+			XKCD:     core.XKCDState{Retries: 5, Breaker: core.BreakerOpen},
+		}, nil)
 
 	handler := NewUpdateStatusHandler(logger, mockUpdater)
 
@@ -243,10 +543,150 @@ func TestUpdateStatusHandler(t *testing.T) {
 	defer res.Body.Close()
 	require.Equal(t, http.StatusOK, res.StatusCode)
 
-	var resp map[string]core.UpdateStatus
+	var resp UpdateStatusResponse
 	err := json.NewDecoder(res.Body).Decode(&resp)
 	require.NoError(t, err)
-	require.Equal(t, core.StatusUpdateRunning, resp["status"])
+	require.Equal(t, core.StatusUpdateRunning, resp.Status)
+	require.Equal(t, ProgressResponse{Total: 10, Stored: 4, Failed: 1}, resp.Progress)
+	require.Equal(t, XKCDStateResponse{Retries: 5, Breaker: core.BreakerOpen}, resp.XKCD)
+	require.Nil(t, resp.LastRun)
+	require.Nil(t, resp.Pipeline)
+
+	mockUpdater.
+		EXPECT().
+		Status(gomock.Any()).
+		Return(core.UpdateState{
+			Status: core.StatusUpdateRunning,
+			Pipeline: core.PipelineStats{
+				Fetch: core.StageStats{Workers: 10, Buffer: 10, Queue: 10, Processed: 40, Throughput: 4, Latency: 2345 * time.Microsecond},
+				Norm:  core.StageStats{Workers: 4, Buffer: 10, Processed: 38, Throughput: 3.8, Latency: time.Millisecond},
+				Add:   core.StageStats{Workers: 2, Buffer: 100, Processed: 30, Throughput: 3},
+			},
+		}, nil)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/updatestatus", nil))
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	pipeline, err := json.Marshal(body["pipeline"])
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"fetch": {"workers": 10, "buffer": 10, "queue": 10, "processed": 40, "throughput": 4, "latency": "2ms"},
+		"norm": {"workers": 4, "buffer": 10, "queue": 0, "processed": 38, "throughput": 3.8, "latency": "1ms"},
+		"add": {"workers": 2, "buffer": 100, "queue": 0, "processed": 30, "throughput": 3, "latency": "0s"}
+	}`, string(pipeline))
+
+	finishedAt := time.Date(2025, 1, 1, 10, 0, 30, 0, time.UTC)
+	mockUpdater.
+		EXPECT().
+		Status(gomock.Any()).
+		Return(core.UpdateState{
+			Status: core.StatusUpdateFailed,
+			LastRun: &core.UpdateRun{
+				ID:         2,
+				Status:     "failed",
+				StartedAt:  finishedAt.Add(-30 * time.Second),
+				FinishedAt: finishedAt,
+				Stored:     3,
+				Failed:     2,
+				Error:      "xkcd is unavailable",
+			},
+		}, nil)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/updatestatus", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	resp = UpdateStatusResponse{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Equal(t, core.StatusUpdateFailed, resp.Status)
+	require.NotNil(t, resp.LastRun)
+	require.Equal(t, "xkcd is unavailable", resp.LastRun.Error)
+	require.Equal(t, finishedAt, resp.LastRun.FinishedAt)
+	require.Equal(t, "30s", resp.LastRun.Duration)
+	require.Equal(t, "stored 3, failed 2, skipped 0", resp.LastRun.Summary)
+	require.False(t, resp.Scheduled)
+	require.Nil(t, resp.NextRun)
+
+	// простой с назначенным запуском остаётся idle, расписание - отдельными полями
+	nextRun := time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+	mockUpdater.
+		EXPECT().
+		Status(gomock.Any()).
+		Return(core.UpdateState{Status: core.StatusUpdateIdle, Scheduled: true, NextRun: nextRun}, nil)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/updatestatus", nil))
+	resp = UpdateStatusResponse{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Equal(t, core.StatusUpdateIdle, resp.Status)
+	require.True(t, resp.Scheduled)
+	require.Equal(t, nextRun, *resp.NextRun)
+}
+
+func TestUpdateEventsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	states := make(chan core.UpdateState, 2)
+	xkcd := core.XKCDState{Breaker: core.BreakerClosed}
+	states <- core.UpdateState{Status: core.StatusUpdateRunning, Progress: core.UpdateProgress{Total: 2, Stored: 1}, XKCD: xkcd}
+	states <- core.UpdateState{Status: core.StatusUpdateIdle, Progress: core.UpdateProgress{Total: 2, Stored: 2}, XKCD: xkcd}
+	close(states)
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		WatchUpdate(gomock.Any()).
+		Return((<-chan core.UpdateState)(states), nil)
+
+	handler := NewUpdateEventsHandler(logger, mockUpdater, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/db/update/events", nil)
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	res := rec.Result()
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t,
+		`data: {"status":"running","progress":{"total":2,"fetched":0,"normalized":0,"stored":1,"failed":0,"unchanged":0},"xkcd":{"retries":0,"breaker":"closed"},"scheduled":false}`+"\n\n"+
+			`data: {"status":"idle","progress":{"total":2,"fetched":0,"normalized":0,"stored":2,"failed":0,"unchanged":0},"xkcd":{"retries":0,"breaker":"closed"},"scheduled":false}`+"\n\n",
+		string(body))
+}
+
+func TestUpdateEventsHandler_Shutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// обновлений нет, поток сам не закончится
+	states := make(chan core.UpdateState)
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		WatchUpdate(gomock.Any()).
+		Return((<-chan core.UpdateState)(states), nil)
+
+	done := make(chan struct{})
+	handler := NewUpdateEventsHandler(logger, mockUpdater, done)
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/db/update/events", nil))
+	}()
+
+	close(done)
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("events stream is not stopped on shutdown")
+	}
 }
 
 func TestDropHandler(t *testing.T) {
@@ -258,7 +698,7 @@ func TestDropHandler(t *testing.T) {
 	mockUpdater.
 		EXPECT().
 		Drop(gomock.Any()).
-		Return(nil)
+		Return(core.Snapshot{Name: "drop-1", Comics: 3, CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}, nil)
 
 	handler := NewDropHandler(logger, mockUpdater)
 
@@ -266,6 +706,17 @@ func TestDropHandler(t *testing.T) {
 	rec := httptest.NewRecorder()
 	handler(rec, req)
 	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	require.JSONEq(t, `{"snapshot":{"name":"drop-1","comics":3,"created_at":"2026-01-02T00:00:00Z"}}`, rec.Body.String())
+
+	mockUpdater.
+		EXPECT().
+		Drop(gomock.Any()).
+		Return(core.Snapshot{}, nil)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/drop", nil))
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	require.JSONEq(t, `{"snapshot":null}`, rec.Body.String())
 	ctrl.Finish()
 
 	ctrl = gomock.NewController(t)
@@ -273,7 +724,7 @@ func TestDropHandler(t *testing.T) {
 	mockUpdater.
 		EXPECT().
 		Drop(gomock.Any()).
-		Return(errors.New("drop error"))
+		Return(core.Snapshot{}, errors.New("drop error"))
 
 	handler = NewDropHandler(logger, mockUpdater)
 
@@ -281,6 +732,16 @@ func TestDropHandler(t *testing.T) {
 	rec = httptest.NewRecorder()
 	handler(rec, req)
 	require.Equal(t, http.StatusInternalServerError, rec.Result().StatusCode)
+
+	// идёт обновление
+	mockUpdater.
+		EXPECT().
+		Drop(gomock.Any()).
+		Return(core.Snapshot{}, status.Error(codes.AlreadyExists, "update already runs"))
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/drop", nil))
+	require.Equal(t, http.StatusConflict, rec.Result().StatusCode)
 }
 
 func TestSearch(t *testing.T) {
@@ -343,6 +804,42 @@ func TestSearch(t *testing.T) {
 	require.Equal(t, expected, resp)
 }
 
+func TestSearch_Metadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSearcher := mock_port.NewMockSearcher(ctrl)
+	mockSearcher.EXPECT().
+		DbSearch(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]core.Comics{{
+			ID:        1,
+			URL:       "http://a",
+			Title:     "Barrel - Part 1",
+			Alt:       "Don't we all.",
+			Published: time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC),
+			Source:    "xkcd",
+		}}, nil)
+
+	handler := NewSearchHandler(logger, mockSearcher)
+
+	req := httptest.NewRequest(http.MethodGet, "/search?phrase=barrel", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	var resp ComicsResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Equal(t, []Comics{{
+		ID:        1,
+		URL:       "http://a",
+		Title:     "Barrel - Part 1",
+		Alt:       "Don't we all.",
+		Published: "2006-01-01",
+		Source:    "xkcd",
+	}}, resp.Comics)
+}
+
 func TestSearchIndexHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -401,3 +898,405 @@ func TestSearchIndexHandler(t *testing.T) {
 
 	require.Equal(t, expected, resp)
 }
+
+func TestScheduleHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nextRun := time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		Schedule(gomock.Any()).
+		Return(core.UpdateSchedule{Period: time.Hour, NextRun: nextRun}, nil)
+
+	handler := NewScheduleHandler(logger, mockUpdater)
+
+	req := httptest.NewRequest(http.MethodGet, "/schedule", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	res := rec.Result()
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var resp ScheduleResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+	require.Equal(t, "1h0m0s", resp.Period)
+	require.False(t, resp.Paused)
+	require.Nil(t, resp.LastRun)
+	require.Equal(t, nextRun, *resp.NextRun)
+}
+
+func TestSetScheduleHandler(t *testing.T) {
+	t.Run("StatusOK", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		period := 30 * time.Minute
+		paused := true
+
+		mockUpdater := mock_port.NewMockUpdater(ctrl)
+		mockUpdater.
+			EXPECT().
+			SetSchedule(gomock.Any(), core.ScheduleSettings{Period: &period, Paused: &paused}).
+			Return(core.UpdateSchedule{Period: period, Paused: true}, nil)
+
+		handler := NewSetScheduleHandler(logger, mockUpdater)
+
+		req := httptest.NewRequest(http.MethodPut, "/schedule", strings.NewReader(`{"period":"30m","paused":true}`))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		res := rec.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var resp ScheduleResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+		require.Equal(t, "30m0s", resp.Period)
+		require.True(t, resp.Paused)
+	})
+
+	t.Run("BadPeriod", func(t *testing.T) {
+		handler := NewSetScheduleHandler(logger, nil)
+
+		req := httptest.NewRequest(http.MethodPut, "/schedule", strings.NewReader(`{"period":"often"}`))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+	})
+
+	t.Run("InvalidArgument", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUpdater := mock_port.NewMockUpdater(ctrl)
+		mockUpdater.
+			EXPECT().
+			SetSchedule(gomock.Any(), gomock.Any()).
+			Return(core.UpdateSchedule{}, status.Error(codes.InvalidArgument, "period must be positive"))
+
+		handler := NewSetScheduleHandler(logger, mockUpdater)
+
+		req := httptest.NewRequest(http.MethodPut, "/schedule", strings.NewReader(`{"period":"-1m"}`))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+	})
+}
+
+func TestExportHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.EXPECT().
+		Export(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, w io.Writer) error {
+			_, err := w.Write([]byte("archive"))
+			return err
+		})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/db/export", nil)
+	w := httptest.NewRecorder()
+
+	NewExportHandler(logger, mockUpdater).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/gzip", w.Header().Get("Content-Type"))
+	require.Contains(t, w.Header().Get("Content-Disposition"), "comics.ndjson.gz")
+	require.Equal(t, "archive", w.Body.String())
+}
+
+func TestExportHandler_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.EXPECT().
+		Export(gomock.Any(), gomock.Any()).
+		Return(errors.New("db is down"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/db/export", nil)
+	w := httptest.NewRecorder()
+
+	NewExportHandler(logger, mockUpdater).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Empty(t, w.Header().Get("Content-Disposition"))
+}
+
+func TestImportHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   core.ImportResult
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "ok",
+			result:   core.ImportResult{Imported: 2, Skipped: 1, Errors: []string{"line 2: bad json"}},
+			wantCode: http.StatusOK,
+			wantBody: `{"imported":2,"skipped":1,"errors":["line 2: bad json"]}`,
+		},
+		{
+			name:     "no errors",
+			result:   core.ImportResult{Imported: 1},
+			wantCode: http.StatusOK,
+			wantBody: `{"imported":1,"skipped":0,"errors":[]}`,
+		},
+		{
+			name:     "not an archive",
+			err:      status.Error(codes.InvalidArgument, "not a gzip archive"),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "update is running",
+			err:      status.Error(codes.AlreadyExists, "already running"),
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "internal",
+			err:      errors.New("boom"),
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUpdater := mock_port.NewMockUpdater(ctrl)
+			mockUpdater.EXPECT().
+				Import(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, r io.Reader) (core.ImportResult, error) {
+					data, err := io.ReadAll(r)
+					require.NoError(t, err)
+					require.Equal(t, "archive", string(data))
+					return tt.result, tt.err
+				})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/db/import", strings.NewReader("archive"))
+			w := httptest.NewRecorder()
+
+			NewImportHandler(logger, mockUpdater).ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				require.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSnapshotsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		Snapshots(gomock.Any()).
+		Return([]core.Snapshot{{Name: "drop-1", Comics: 3, CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}}, nil)
+
+	rec := httptest.NewRecorder()
+	NewSnapshotsHandler(logger, mockUpdater)(rec, httptest.NewRequest(http.MethodGet, "/api/db/snapshots", nil))
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	require.JSONEq(t, `[{"name":"drop-1","comics":3,"created_at":"2026-01-02T00:00:00Z"}]`, rec.Body.String())
+
+	mockUpdater.
+		EXPECT().
+		Snapshots(gomock.Any()).
+		Return(nil, nil)
+
+	rec = httptest.NewRecorder()
+	NewSnapshotsHandler(logger, mockUpdater)(rec, httptest.NewRequest(http.MethodGet, "/api/db/snapshots", nil))
+	require.JSONEq(t, `[]`, rec.Body.String())
+}
+
+func TestRestoreHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{name: "ok", wantCode: http.StatusOK},
+		{name: "not found", err: status.Error(codes.NotFound, "snapshot drop-1"), wantCode: http.StatusNotFound},
+		{name: "update is running", err: status.Error(codes.AlreadyExists, "update already runs"), wantCode: http.StatusConflict},
+		{name: "internal", err: errors.New("boom"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUpdater := mock_port.NewMockUpdater(ctrl)
+			mockUpdater.
+				EXPECT().
+				Restore(gomock.Any(), "drop-1").
+				Return(tt.err)
+
+			mux := http.NewServeMux()
+			mux.Handle("POST /api/db/snapshots/{name}/restore", NewRestoreHandler(logger, mockUpdater))
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/db/snapshots/drop-1/restore", nil))
+			require.Equal(t, tt.wantCode, rec.Result().StatusCode)
+		})
+	}
+}
+
+func TestDeleteSnapshotHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mux := http.NewServeMux()
+	mux.Handle("DELETE /api/db/snapshots/{name}", NewDeleteSnapshotHandler(logger, mockUpdater))
+
+	mockUpdater.
+		EXPECT().
+		DeleteSnapshot(gomock.Any(), "drop-1").
+		Return(nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/db/snapshots/drop-1", nil))
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	mockUpdater.
+		EXPECT().
+		DeleteSnapshot(gomock.Any(), "drop-2").
+		Return(status.Error(codes.NotFound, "snapshot drop-2"))
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/db/snapshots/drop-2", nil))
+	require.Equal(t, http.StatusNotFound, rec.Result().StatusCode)
+}
+
+func TestDeleteComicsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mux := http.NewServeMux()
+	mux.Handle("DELETE /api/comics/{id}", NewDeleteComicsHandler(logger, mockUpdater))
+
+	mockUpdater.
+		EXPECT().
+		Delete(gomock.Any(), core.DeleteOptions{IDs: []int{42}}).
+		Return(1, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/comics/42", nil))
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	require.JSONEq(t, `{"deleted":1}`, rec.Body.String())
+
+	mockUpdater.
+		EXPECT().
+		Delete(gomock.Any(), core.DeleteOptions{IDs: []int{43}}).
+		Return(0, nil)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/comics/43", nil))
+	require.Equal(t, http.StatusNotFound, rec.Result().StatusCode)
+
+	for _, id := range []string{"abc", "0"} {
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/comics/"+id, nil))
+		require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+	}
+}
+
+func TestDeleteComicsRangeHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	handler := NewDeleteComicsRangeHandler(logger, mockUpdater)
+
+	mockUpdater.
+		EXPECT().
+		Delete(gomock.Any(), core.DeleteOptions{From: 10, To: 20}).
+		Return(11, nil)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodDelete, "/api/comics?from=10&to=20", nil))
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	require.JSONEq(t, `{"deleted":11}`, rec.Body.String())
+
+	mockUpdater.
+		EXPECT().
+		Delete(gomock.Any(), core.DeleteOptions{From: 20, To: 10}).
+		Return(0, status.Error(codes.InvalidArgument, "wrong range"))
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodDelete, "/api/comics?from=20&to=10", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+
+	for _, query := range []string{"", "from=1", "to=5", "from=abc&to=5", "from=0&to=5"} {
+		rec = httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodDelete, "/api/comics?"+query, nil))
+		require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode, query)
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	started := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	mockUpdater := mock_port.NewMockUpdater(ctrl)
+	mockUpdater.
+		EXPECT().
+		History(gomock.Any(), core.HistoryOptions{Limit: 20}).
+		Return(core.History{Total: 1, Runs: []core.UpdateRun{{
+			ID: 1, Mode: "update", Trigger: "scheduled", Status: "completed",
+			StartedAt: started, FinishedAt: started.Add(90 * time.Second), Stored: 3,
+		}}}, nil)
+
+	handler := NewHistoryHandler(logger, mockUpdater)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/db/updates", nil))
+	require.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	require.JSONEq(t, `{
+		"runs": [{
+			"id": 1, "mode": "update", "trigger": "scheduled", "status": "completed",
+			"started_at": "2026-01-02T03:00:00Z", "finished_at": "2026-01-02T03:01:30Z", "duration": "1m30s",
+			"stored": 3, "failed": 0, "skipped": 0, "summary": "stored 3, failed 0, skipped 0"
+		}],
+		"total": 1, "limit": 20, "offset": 0
+	}`, rec.Body.String())
+
+	mockUpdater.
+		EXPECT().
+		History(gomock.Any(), core.HistoryOptions{Limit: 5, Offset: 10}).
+		Return(core.History{Total: 3}, nil)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/db/updates?limit=5&offset=10", nil))
+	require.JSONEq(t, `{"runs": [], "total": 3, "limit": 5, "offset": 10}`, rec.Body.String())
+
+	mockUpdater.
+		EXPECT().
+		History(gomock.Any(), core.HistoryOptions{Limit: 1000}).
+		Return(core.History{}, status.Error(codes.InvalidArgument, "wrong page limit"))
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/db/updates?limit=1000", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+
+	for _, query := range []string{"limit=abc", "offset=-1"} {
+		rec = httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/api/db/updates?"+query, nil))
+		require.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+	}
+}
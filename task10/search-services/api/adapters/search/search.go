@@ -50,7 +50,7 @@ func (c Client) DbSearch(ctx context.Context, limit int, phrase string) ([]core.
 
 	var comics []core.Comics
 	for _, x := range comicsReply {
-		comics = append(comics, core.Comics{ID: int(x.Id), URL: x.Url})
+		comics = append(comics, comicsFromReply(x))
 	}
 
 	return comics, nil
@@ -69,8 +69,25 @@ func (c Client) IndexSearch(ctx context.Context, limit int, phrase string) ([]co
 
 	var comics []core.Comics
 	for _, x := range comicsReply {
-		comics = append(comics, core.Comics{ID: int(x.Id), URL: x.Url})
+		comics = append(comics, comicsFromReply(x))
 	}
 
 	return comics, nil
 }
+
+func comicsFromReply(reply *searchpb.Comics) core.Comics {
+	comics := core.Comics{
+		ID:         int(reply.GetId()),
+		URL:        reply.GetUrl(),
+		Title:      reply.GetTitle(),
+		Alt:        reply.GetAlt(),
+		Transcript: reply.GetTranscript(),
+		Link:       reply.GetLink(),
+		News:       reply.GetNews(),
+		Source:     reply.GetSource(),
+	}
+	if reply.GetPublished() != nil {
+		comics.Published = reply.GetPublished().AsTime()
+	}
+	return comics
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"log/slog"
 
@@ -72,6 +74,47 @@ func TestDbSearch(t *testing.T) {
 	require.Equal(t, expected, comics)
 }
 
+func TestDbSearch_Metadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock_search.NewMockSearchClient(ctrl)
+	c := Client{
+		log:    logger,
+		client: mockClient,
+	}
+
+	published := time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockClient.EXPECT().
+		DbSearch(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&searchpb.SearchReply{Comics: []*searchpb.Comics{{
+			Id:         1,
+			Url:        "http://example.com/1",
+			Title:      "Barrel - Part 1",
+			Alt:        "Don't we all.",
+			Transcript: "transcript",
+			Link:       "link",
+			News:       "news",
+			Published:  timestamppb.New(published),
+			Source:     "xkcd",
+		}}}, nil)
+
+	comics, err := c.DbSearch(context.Background(), 1, "barrel")
+	require.NoError(t, err)
+	require.Equal(t, []core.Comics{{
+		ID:         1,
+		URL:        "http://example.com/1",
+		Title:      "Barrel - Part 1",
+		Alt:        "Don't we all.",
+		Transcript: "transcript",
+		Link:       "link",
+		News:       "news",
+		Published:  published,
+		Source:     "xkcd",
+	}}, comics)
+}
+
 func TestIndexSearch(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
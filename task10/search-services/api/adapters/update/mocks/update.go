@@ -43,15 +43,75 @@ func (m *MockUpdateClient) EXPECT() *MockUpdateClientMockRecorder {
 	return m.recorder
 }
 
+// Cancel mocks base method.
+func (m *MockUpdateClient) Cancel(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*update.StatusReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Cancel", varargs...)
+	ret0, _ := ret[0].(*update.StatusReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockUpdateClientMockRecorder) Cancel(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockUpdateClient)(nil).Cancel), varargs...)
+}
+
+// Delete mocks base method.
+func (m *MockUpdateClient) Delete(ctx context.Context, in *update.DeleteRequest, opts ...grpc.CallOption) (*update.DeleteReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Delete", varargs...)
+	ret0, _ := ret[0].(*update.DeleteReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUpdateClientMockRecorder) Delete(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUpdateClient)(nil).Delete), varargs...)
+}
+
+// DeleteSnapshot mocks base method.
+func (m *MockUpdateClient) DeleteSnapshot(ctx context.Context, in *update.SnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteSnapshot", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSnapshot indicates an expected call of DeleteSnapshot.
+func (mr *MockUpdateClientMockRecorder) DeleteSnapshot(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSnapshot", reflect.TypeOf((*MockUpdateClient)(nil).DeleteSnapshot), varargs...)
+}
+
 // Drop mocks base method.
-func (m *MockUpdateClient) Drop(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (m *MockUpdateClient) Drop(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*update.Snapshot, error) {
 	m.ctrl.T.Helper()
 	varargs := []any{ctx, in}
 	for _, a := range opts {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "Drop", varargs...)
-	ret0, _ := ret[0].(*emptypb.Empty)
+	ret0, _ := ret[0].(*update.Snapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -63,6 +123,106 @@ func (mr *MockUpdateClientMockRecorder) Drop(ctx, in any, opts ...any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drop", reflect.TypeOf((*MockUpdateClient)(nil).Drop), varargs...)
 }
 
+// Export mocks base method.
+func (m *MockUpdateClient) Export(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[update.ArchiveChunk], error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Export", varargs...)
+	ret0, _ := ret[0].(grpc.ServerStreamingClient[update.ArchiveChunk])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockUpdateClientMockRecorder) Export(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockUpdateClient)(nil).Export), varargs...)
+}
+
+// Failures mocks base method.
+func (m *MockUpdateClient) Failures(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*update.FailuresReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Failures", varargs...)
+	ret0, _ := ret[0].(*update.FailuresReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Failures indicates an expected call of Failures.
+func (mr *MockUpdateClientMockRecorder) Failures(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Failures", reflect.TypeOf((*MockUpdateClient)(nil).Failures), varargs...)
+}
+
+// History mocks base method.
+func (m *MockUpdateClient) History(ctx context.Context, in *update.HistoryRequest, opts ...grpc.CallOption) (*update.HistoryReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "History", varargs...)
+	ret0, _ := ret[0].(*update.HistoryReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockUpdateClientMockRecorder) History(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockUpdateClient)(nil).History), varargs...)
+}
+
+// Import mocks base method.
+func (m *MockUpdateClient) Import(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[update.ArchiveChunk, update.ImportReply], error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Import", varargs...)
+	ret0, _ := ret[0].(grpc.ClientStreamingClient[update.ArchiveChunk, update.ImportReply])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockUpdateClientMockRecorder) Import(ctx any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockUpdateClient)(nil).Import), varargs...)
+}
+
+// ListSnapshots mocks base method.
+func (m *MockUpdateClient) ListSnapshots(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*update.SnapshotsReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListSnapshots", varargs...)
+	ret0, _ := ret[0].(*update.SnapshotsReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSnapshots indicates an expected call of ListSnapshots.
+func (mr *MockUpdateClientMockRecorder) ListSnapshots(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSnapshots", reflect.TypeOf((*MockUpdateClient)(nil).ListSnapshots), varargs...)
+}
+
 // Ping mocks base method.
 func (m *MockUpdateClient) Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	m.ctrl.T.Helper()
@@ -83,6 +243,106 @@ func (mr *MockUpdateClientMockRecorder) Ping(ctx, in any, opts ...any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockUpdateClient)(nil).Ping), varargs...)
 }
 
+// Refresh mocks base method.
+func (m *MockUpdateClient) Refresh(ctx context.Context, in *update.RefreshRequest, opts ...grpc.CallOption) (*update.RefreshReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Refresh", varargs...)
+	ret0, _ := ret[0].(*update.RefreshReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockUpdateClientMockRecorder) Refresh(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockUpdateClient)(nil).Refresh), varargs...)
+}
+
+// Restore mocks base method.
+func (m *MockUpdateClient) Restore(ctx context.Context, in *update.SnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Restore", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockUpdateClientMockRecorder) Restore(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUpdateClient)(nil).Restore), varargs...)
+}
+
+// RetryFailed mocks base method.
+func (m *MockUpdateClient) RetryFailed(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RetryFailed", varargs...)
+	ret0, _ := ret[0].(*emptypb.Empty)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryFailed indicates an expected call of RetryFailed.
+func (mr *MockUpdateClientMockRecorder) RetryFailed(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryFailed", reflect.TypeOf((*MockUpdateClient)(nil).RetryFailed), varargs...)
+}
+
+// Schedule mocks base method.
+func (m *MockUpdateClient) Schedule(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*update.ScheduleReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Schedule", varargs...)
+	ret0, _ := ret[0].(*update.ScheduleReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Schedule indicates an expected call of Schedule.
+func (mr *MockUpdateClientMockRecorder) Schedule(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedule", reflect.TypeOf((*MockUpdateClient)(nil).Schedule), varargs...)
+}
+
+// SetSchedule mocks base method.
+func (m *MockUpdateClient) SetSchedule(ctx context.Context, in *update.ScheduleRequest, opts ...grpc.CallOption) (*update.ScheduleReply, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetSchedule", varargs...)
+	ret0, _ := ret[0].(*update.ScheduleReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSchedule indicates an expected call of SetSchedule.
+func (mr *MockUpdateClientMockRecorder) SetSchedule(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSchedule", reflect.TypeOf((*MockUpdateClient)(nil).SetSchedule), varargs...)
+}
+
 // Stats mocks base method.
 func (m *MockUpdateClient) Stats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*update.StatsReply, error) {
 	m.ctrl.T.Helper()
@@ -124,14 +384,14 @@ func (mr *MockUpdateClientMockRecorder) Status(ctx, in any, opts ...any) *gomock
 }
 
 // Update mocks base method.
-func (m *MockUpdateClient) Update(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (m *MockUpdateClient) Update(ctx context.Context, in *update.UpdateRequest, opts ...grpc.CallOption) (*update.UpdateReply, error) {
 	m.ctrl.T.Helper()
 	varargs := []any{ctx, in}
 	for _, a := range opts {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "Update", varargs...)
-	ret0, _ := ret[0].(*emptypb.Empty)
+	ret0, _ := ret[0].(*update.UpdateReply)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -142,3 +402,23 @@ func (mr *MockUpdateClientMockRecorder) Update(ctx, in any, opts ...any) *gomock
 	varargs := append([]any{ctx, in}, opts...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUpdateClient)(nil).Update), varargs...)
 }
+
+// WatchUpdate mocks base method.
+func (m *MockUpdateClient) WatchUpdate(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[update.StatusReply], error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WatchUpdate", varargs...)
+	ret0, _ := ret[0].(grpc.ServerStreamingClient[update.StatusReply])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchUpdate indicates an expected call of WatchUpdate.
+func (mr *MockUpdateClientMockRecorder) WatchUpdate(ctx, in any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchUpdate", reflect.TypeOf((*MockUpdateClient)(nil).WatchUpdate), varargs...)
+}
@@ -3,15 +3,20 @@ package update
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"yadro.com/course/api/core"
 	updatepb "yadro.com/course/proto/update"
 )
 
+const chunkSize = 64 << 10
+
 type Client struct {
 	log    *slog.Logger
 	client updatepb.UpdateClient
@@ -37,25 +42,117 @@ func (c Client) Ping(ctx context.Context) error {
 	return nil
 }
 
-func (c Client) Status(ctx context.Context) (core.UpdateStatus, error) {
+func (c Client) Status(ctx context.Context) (core.UpdateState, error) {
 	statusReply, err := c.client.Status(ctx, &emptypb.Empty{})
 	if err != nil {
 		c.log.Error("Status", "", err)
-		return "", err
+		return core.UpdateState{}, err
 	}
 
-	var status core.UpdateStatus
+	return updateState(statusReply), nil
+}
+
+func (c Client) WatchUpdate(ctx context.Context) (<-chan core.UpdateState, error) {
+	stream, err := c.client.WatchUpdate(ctx, &emptypb.Empty{})
+	if err != nil {
+		c.log.Error("WatchUpdate", "error", err)
+		return nil, err
+	}
+
+	states := make(chan core.UpdateState)
+
+	go func() {
+		defer close(states)
+
+		for {
+			reply, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+					c.log.Error("WatchUpdate", "error", err)
+				}
+				return
+			}
 
-	switch statusReply.Status {
+			select {
+			case states <- updateState(reply):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return states, nil
+}
+
+func updateState(reply *updatepb.StatusReply) core.UpdateState {
+	var state core.UpdateState
+
+	switch reply.GetStatus() {
 	case updatepb.Status_STATUS_IDLE:
-		status = core.StatusUpdateIdle
+		state.Status = core.StatusUpdateIdle
 	case updatepb.Status_STATUS_RUNNING:
-		status = core.StatusUpdateRunning
+		state.Status = core.StatusUpdateRunning
+	case updatepb.Status_STATUS_CANCELLED:
+		state.Status = core.StatusUpdateCancelled
+	case updatepb.Status_STATUS_FAILED:
+		state.Status = core.StatusUpdateFailed
+	case updatepb.Status_STATUS_CANCELLING:
+		state.Status = core.StatusUpdateCancelling
 	default:
-		status = core.StatusUpdateUnknown
+		state.Status = core.StatusUpdateUnknown
 	}
 
-	return status, nil
+	progress := reply.GetProgress()
+	state.Progress = core.UpdateProgress{
+		Total:      int(progress.GetTotal()),
+		Fetched:    int(progress.GetFetched()),
+		Normalized: int(progress.GetNormalized()),
+		Stored:     int(progress.GetStored()),
+		Failed:     int(progress.GetFailed()),
+		Unchanged:  int(progress.GetUnchanged()),
+	}
+	if progress.GetStartedAt() != nil {
+		state.Progress.StartedAt = progress.GetStartedAt().AsTime()
+	}
+
+	state.Replica = reply.GetReplica()
+	state.Scheduled = reply.GetScheduled()
+	if reply.GetNextRun() != nil {
+		state.NextRun = reply.GetNextRun().AsTime()
+	}
+	if reply.GetLastRun() != nil {
+		run := updateRun(reply.GetLastRun())
+		state.LastRun = &run
+	}
+	state.Pipeline = core.PipelineStats{
+		Fetch: stageStats(reply.GetPipeline().GetFetch()),
+		Norm:  stageStats(reply.GetPipeline().GetNorm()),
+		Add:   stageStats(reply.GetPipeline().GetAdd()),
+	}
+	state.XKCD.Retries = int(reply.GetXkcd().GetRetries())
+	switch reply.GetXkcd().GetBreaker() {
+	case updatepb.BreakerState_BREAKER_STATE_CLOSED:
+		state.XKCD.Breaker = core.BreakerClosed
+	case updatepb.BreakerState_BREAKER_STATE_OPEN:
+		state.XKCD.Breaker = core.BreakerOpen
+	case updatepb.BreakerState_BREAKER_STATE_HALF_OPEN:
+		state.XKCD.Breaker = core.BreakerHalfOpen
+	default:
+		state.XKCD.Breaker = core.BreakerUnknown
+	}
+
+	return state
+}
+
+func stageStats(stats *updatepb.StageStats) core.StageStats {
+	return core.StageStats{
+		Workers:    int(stats.GetWorkers()),
+		Buffer:     int(stats.GetBuffer()),
+		Queue:      int(stats.GetQueue()),
+		Processed:  int(stats.GetProcessed()),
+		Throughput: stats.GetThroughput(),
+		Latency:    stats.GetLatency().AsDuration(),
+	}
 }
 
 func (c Client) Stats(ctx context.Context) (core.UpdateStats, error) {
@@ -74,12 +171,286 @@ func (c Client) Stats(ctx context.Context) (core.UpdateStats, error) {
 	}, nil
 }
 
-func (c Client) Update(ctx context.Context) error {
-	_, err := c.client.Update(ctx, &emptypb.Empty{})
+func (c Client) Update(ctx context.Context, opts core.UpdateOptions) error {
+	_, err := c.client.Update(ctx, updateRequest(opts, false))
+	return err
+}
+
+func updateRequest(opts core.UpdateOptions, dryRun bool) *updatepb.UpdateRequest {
+	return &updatepb.UpdateRequest{
+		Reconcile:   opts.Reconcile,
+		DryRun:      dryRun,
+		Resume:      opts.Resume,
+		NewestFirst: opts.NewestFirst,
+	}
+}
+
+func (c Client) Plan(ctx context.Context, opts core.UpdateOptions) (core.UpdatePlan, error) {
+	reply, err := c.client.Update(ctx, updateRequest(opts, true))
+	if err != nil {
+		return core.UpdatePlan{}, err
+	}
+
+	pb := reply.GetPlan()
+	plan := core.UpdatePlan{
+		Mode:       pb.GetMode(),
+		Ranges:     make([]core.SourceRange, 0, len(pb.GetRanges())),
+		Missing:    ints(pb.GetMissing()),
+		Tombstoned: ints(pb.GetTombstoned()),
+		Failed:     ints(pb.GetFailed()),
+		Estimate:   pb.GetEstimate().AsDuration(),
+	}
+	for _, r := range pb.GetRanges() {
+		plan.Ranges = append(plan.Ranges, core.SourceRange{Source: r.GetSource(), From: int(r.GetFrom()), To: int(r.GetTo())})
+	}
+	return plan, nil
+}
+
+func ints(ids []int64) []int {
+	out := make([]int, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, int(id))
+	}
+	return out
+}
+
+func (c Client) Cancel(ctx context.Context) (core.UpdateState, error) {
+	reply, err := c.client.Cancel(ctx, &emptypb.Empty{})
+	if err != nil {
+		c.log.Error("Cancel", "error", err)
+		return core.UpdateState{}, err
+	}
+
+	return updateState(reply), nil
+}
+
+func (c Client) RetryFailed(ctx context.Context) error {
+	_, err := c.client.RetryFailed(ctx, &emptypb.Empty{})
+	return err
+}
+
+func (c Client) Refresh(ctx context.Context, opts core.RefreshOptions) (core.RefreshResult, error) {
+	reply, err := c.client.Refresh(ctx, &updatepb.RefreshRequest{From: int64(opts.From), To: int64(opts.To)})
+	if err != nil {
+		return core.RefreshResult{}, err
+	}
+
+	return core.RefreshResult{Checked: int(reply.GetChecked()), Changed: int(reply.GetChanged())}, nil
+}
+
+// Export пишет в w архив комиксов в том виде, в каком его отдаёт update.
+func (c Client) Export(ctx context.Context, w io.Writer) error {
+	stream, err := c.client.Export(ctx, &emptypb.Empty{})
+	if err != nil {
+		c.log.Error("Export", "error", err)
+		return err
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			c.log.Error("Export", "error", err)
+			return err
+		}
+		if _, err := w.Write(chunk.GetData()); err != nil {
+			return err
+		}
+	}
+}
+
+// Import передаёт архив из r в update частями по chunkSize байт.
+func (c Client) Import(ctx context.Context, r io.Reader) (core.ImportResult, error) {
+	stream, err := c.client.Import(ctx)
+	if err != nil {
+		c.log.Error("Import", "error", err)
+		return core.ImportResult{}, err
+	}
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if sendErr := stream.Send(&updatepb.ArchiveChunk{Data: buf[:n]}); sendErr != nil {
+				// сервер уже ответил, настоящая ошибка придёт в CloseAndRecv
+				if errors.Is(sendErr, io.EOF) {
+					break
+				}
+				return core.ImportResult{}, sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return core.ImportResult{}, err
+		}
+	}
+
+	reply, err := stream.CloseAndRecv()
+	if err != nil {
+		c.log.Error("Import", "error", err)
+		return core.ImportResult{}, err
+	}
+
+	return core.ImportResult{
+		Imported: int(reply.GetImported()),
+		Skipped:  int(reply.GetSkipped()),
+		Errors:   reply.GetErrors(),
+	}, nil
+}
+
+func (c Client) Failures(ctx context.Context) ([]core.Failure, error) {
+	reply, err := c.client.Failures(ctx, &emptypb.Empty{})
+	if err != nil {
+		c.log.Error("Failures", "error", err)
+		return nil, err
+	}
+
+	failures := make([]core.Failure, 0, len(reply.GetFailures()))
+	for _, failure := range reply.GetFailures() {
+		failures = append(failures, core.Failure{
+			ID:       int(failure.GetId()),
+			Stage:    failure.GetStage(),
+			Error:    failure.GetError(),
+			Attempts: int(failure.GetAttempts()),
+			FailedAt: failure.GetFailedAt().AsTime(),
+		})
+	}
+
+	return failures, nil
+}
+
+func (c Client) History(ctx context.Context, opts core.HistoryOptions) (core.History, error) {
+	reply, err := c.client.History(ctx, &updatepb.HistoryRequest{Limit: int64(opts.Limit), Offset: int64(opts.Offset)})
+	if err != nil {
+		c.log.Error("History", "error", err)
+		return core.History{}, err
+	}
+
+	history := core.History{
+		Runs:  make([]core.UpdateRun, 0, len(reply.GetRuns())),
+		Total: int(reply.GetTotal()),
+	}
+	for _, run := range reply.GetRuns() {
+		history.Runs = append(history.Runs, updateRun(run))
+	}
+	return history, nil
+}
+
+func updateRun(run *updatepb.UpdateRun) core.UpdateRun {
+	return core.UpdateRun{
+		ID:         run.GetId(),
+		Mode:       run.GetMode(),
+		Trigger:    run.GetTrigger(),
+		Status:     run.GetStatus(),
+		StartedAt:  run.GetStartedAt().AsTime(),
+		FinishedAt: run.GetFinishedAt().AsTime(),
+		Stored:     int(run.GetStored()),
+		Failed:     int(run.GetFailed()),
+		Skipped:    int(run.GetSkipped()),
+		Error:      run.GetError(),
+	}
+}
+
+// Delete возвращает число удалённых комиксов
+func (c Client) Delete(ctx context.Context, opts core.DeleteOptions) (int, error) {
+	req := &updatepb.DeleteRequest{From: int64(opts.From), To: int64(opts.To)}
+	for _, id := range opts.IDs {
+		req.Ids = append(req.Ids, int64(id))
+	}
+
+	reply, err := c.client.Delete(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	return int(reply.GetDeleted()), nil
+}
+
+// Drop возвращает снимок, сделанный перед удалением, или пустой, если его не было
+func (c Client) Drop(ctx context.Context) (core.Snapshot, error) {
+	reply, err := c.client.Drop(ctx, &emptypb.Empty{})
+	if err != nil {
+		return core.Snapshot{}, err
+	}
+	return snapshot(reply), nil
+}
+
+func (c Client) Snapshots(ctx context.Context) ([]core.Snapshot, error) {
+	reply, err := c.client.ListSnapshots(ctx, &emptypb.Empty{})
+	if err != nil {
+		c.log.Error("Snapshots", "error", err)
+		return nil, err
+	}
+
+	snapshots := make([]core.Snapshot, 0, len(reply.GetSnapshots()))
+	for _, s := range reply.GetSnapshots() {
+		snapshots = append(snapshots, snapshot(s))
+	}
+	return snapshots, nil
+}
+
+func (c Client) Restore(ctx context.Context, name string) error {
+	_, err := c.client.Restore(ctx, &updatepb.SnapshotRequest{Name: name})
 	return err
 }
 
-func (c Client) Drop(ctx context.Context) error {
-	_, err := c.client.Drop(ctx, &emptypb.Empty{})
+func (c Client) DeleteSnapshot(ctx context.Context, name string) error {
+	_, err := c.client.DeleteSnapshot(ctx, &updatepb.SnapshotRequest{Name: name})
 	return err
 }
+
+func snapshot(reply *updatepb.Snapshot) core.Snapshot {
+	if reply.GetName() == "" {
+		return core.Snapshot{}
+	}
+	return core.Snapshot{
+		Name:      reply.GetName(),
+		Comics:    int(reply.GetComics()),
+		CreatedAt: reply.GetCreatedAt().AsTime(),
+	}
+}
+
+func (c Client) Schedule(ctx context.Context) (core.UpdateSchedule, error) {
+	reply, err := c.client.Schedule(ctx, &emptypb.Empty{})
+	if err != nil {
+		c.log.Error("Schedule", "error", err)
+		return core.UpdateSchedule{}, err
+	}
+
+	return schedule(reply), nil
+}
+
+func (c Client) SetSchedule(ctx context.Context, settings core.ScheduleSettings) (core.UpdateSchedule, error) {
+	var request updatepb.ScheduleRequest
+
+	if settings.Period != nil {
+		request.Period = durationpb.New(*settings.Period)
+	}
+	request.Paused = settings.Paused
+
+	reply, err := c.client.SetSchedule(ctx, &request)
+	if err != nil {
+		c.log.Error("SetSchedule", "error", err)
+		return core.UpdateSchedule{}, err
+	}
+
+	return schedule(reply), nil
+}
+
+func schedule(reply *updatepb.ScheduleReply) core.UpdateSchedule {
+	var schedule core.UpdateSchedule
+
+	schedule.Period = reply.GetPeriod().AsDuration()
+	schedule.Paused = reply.GetPaused()
+	if reply.GetNextRun() != nil {
+		schedule.NextRun = reply.GetNextRun().AsTime()
+	}
+	if reply.GetLastRun() != nil {
+		schedule.LastRun = reply.GetLastRun().AsTime()
+	}
+
+	return schedule
+}
@@ -1,13 +1,21 @@
 package update
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	updatepb "yadro.com/course/proto/update"
 
 	"log/slog"
@@ -55,6 +63,21 @@ func TestStatus(t *testing.T) {
 			reply:    &updatepb.StatusReply{Status: updatepb.Status_STATUS_RUNNING},
 			expected: core.StatusUpdateRunning,
 		},
+		{
+			name:     "UpdateCancelled",
+			reply:    &updatepb.StatusReply{Status: updatepb.Status_STATUS_CANCELLED},
+			expected: core.StatusUpdateCancelled,
+		},
+		{
+			name:     "UpdateFailed",
+			reply:    &updatepb.StatusReply{Status: updatepb.Status_STATUS_FAILED},
+			expected: core.StatusUpdateFailed,
+		},
+		{
+			name:     "UpdateCancelling",
+			reply:    &updatepb.StatusReply{Status: updatepb.Status_STATUS_CANCELLING},
+			expected: core.StatusUpdateCancelling,
+		},
 		{
 			name:     "UpdateUnknown",
 			reply:    &updatepb.StatusReply{Status: updatepb.Status_STATUS_UNSPECIFIED},
@@ -78,13 +101,136 @@ func TestStatus(t *testing.T) {
 				client: mockClient,
 			}
 
-			status, err := c.Status(context.Background())
+			state, err := c.Status(context.Background())
 			require.NoError(t, err)
-			require.Equal(t, tc.expected, status)
+			require.Equal(t, tc.expected, state.Status)
 		})
 	}
 }
 
+func TestStatus_Progress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	startedAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	nextRun := startedAt.Add(time.Hour)
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		Status(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
+		Return(&updatepb.StatusReply{
+			Status: updatepb.Status_STATUS_RUNNING,
+			Progress: &updatepb.Progress{
+				Total:      10,
+				Fetched:    5,
+				Normalized: 4,
+				Stored:     3,
+				Failed:     1,
+				StartedAt:  timestamppb.New(startedAt),
+			},
+			Xkcd:    &updatepb.XKCDState{Retries: 2, Breaker: updatepb.BreakerState_BREAKER_STATE_CLOSED},
+			Replica: "update-2",
+			LastRun: &updatepb.UpdateRun{
+				Id:         3,
+				Mode:       "update",
+				Status:     "failed",
+				StartedAt:  timestamppb.New(startedAt.Add(-time.Hour)),
+				FinishedAt: timestamppb.New(startedAt.Add(-time.Minute)),
+				Stored:     4,
+				Error:      "xkcd is unavailable",
+			},
+			Pipeline: &updatepb.PipelineStats{
+				Fetch: &updatepb.StageStats{Workers: 10, Buffer: 10, Queue: 6, Processed: 5, Throughput: 2.5, Latency: durationpb.New(time.Second)},
+				Add:   &updatepb.StageStats{Workers: 2, Buffer: 100, Processed: 3},
+			},
+			Scheduled: true,
+			NextRun:   timestamppb.New(nextRun),
+		}, nil)
+
+	c := Client{
+		log:    logger,
+		client: mockClient,
+	}
+
+	state, err := c.Status(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, core.UpdateState{
+		Status: core.StatusUpdateRunning,
+		Progress: core.UpdateProgress{
+			Total:      10,
+			Fetched:    5,
+			Normalized: 4,
+			Stored:     3,
+			Failed:     1,
+			StartedAt:  startedAt,
+		},
+		XKCD:    core.XKCDState{Retries: 2, Breaker: core.BreakerClosed},
+		Replica: "update-2",
+		LastRun: &core.UpdateRun{
+			ID:         3,
+			Mode:       "update",
+			Status:     "failed",
+			StartedAt:  startedAt.Add(-time.Hour),
+			FinishedAt: startedAt.Add(-time.Minute),
+			Stored:     4,
+			Error:      "xkcd is unavailable",
+		},
+		Pipeline: core.PipelineStats{
+			Fetch: core.StageStats{Workers: 10, Buffer: 10, Queue: 6, Processed: 5, Throughput: 2.5, Latency: time.Second},
+			Add:   core.StageStats{Workers: 2, Buffer: 100, Processed: 3},
+		},
+		Scheduled: true,
+		NextRun:   nextRun,
+	}, state)
+}
+
+type watchStream struct {
+	grpc.ClientStream
+	replies []*updatepb.StatusReply
+}
+
+func (s *watchStream) Recv() (*updatepb.StatusReply, error) {
+	if len(s.replies) == 0 {
+		return nil, io.EOF
+	}
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return reply, nil
+}
+
+func TestWatchUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stream := &watchStream{replies: []*updatepb.StatusReply{
+		{Status: updatepb.Status_STATUS_RUNNING, Progress: &updatepb.Progress{Total: 2, Stored: 1}},
+		{Status: updatepb.Status_STATUS_IDLE, Progress: &updatepb.Progress{Total: 2, Stored: 2}, Xkcd: &updatepb.XKCDState{Retries: 1, Breaker: updatepb.BreakerState_BREAKER_STATE_CLOSED}},
+	}}
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		WatchUpdate(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
+		Return(stream, nil)
+
+	c := Client{
+		log:    logger,
+		client: mockClient,
+	}
+
+	states, err := c.WatchUpdate(context.Background())
+	require.NoError(t, err)
+
+	var received []core.UpdateState
+	for state := range states {
+		received = append(received, state)
+	}
+
+	require.Equal(t, []core.UpdateState{
+		{Status: core.StatusUpdateRunning, Progress: core.UpdateProgress{Total: 2, Stored: 1}, XKCD: core.XKCDState{Breaker: core.BreakerUnknown}},
+		{Status: core.StatusUpdateIdle, Progress: core.UpdateProgress{Total: 2, Stored: 2}, XKCD: core.XKCDState{Retries: 1, Breaker: core.BreakerClosed}},
+	}, received)
+}
+
 func TestStats(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -124,16 +270,158 @@ func TestUpdate(t *testing.T) {
 
 	mockClient := mock_update.NewMockUpdateClient(ctrl)
 	mockClient.EXPECT().
-		Update(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
+		Update(gomock.Any(), &updatepb.UpdateRequest{Reconcile: true}, gomock.Any()).
+		Return(&updatepb.UpdateReply{}, nil)
+
+	cl := Client{
+		log:    logger,
+		client: mockClient,
+	}
+
+	err := cl.Update(context.Background(), core.UpdateOptions{Reconcile: true})
+	require.NoError(t, err)
+}
+
+func TestUpdate_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		Update(gomock.Any(), &updatepb.UpdateRequest{Resume: true, NewestFirst: true}, gomock.Any()).
+		Return(&updatepb.UpdateReply{}, nil)
+
+	cl := Client{
+		log:    logger,
+		client: mockClient,
+	}
+
+	err := cl.Update(context.Background(), core.UpdateOptions{Resume: true, NewestFirst: true})
+	require.NoError(t, err)
+}
+
+func TestClient_Plan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		Update(gomock.Any(), &updatepb.UpdateRequest{DryRun: true}, gomock.Any()).
+		Return(&updatepb.UpdateReply{Plan: &updatepb.UpdatePlan{
+			Mode:       "update",
+			Ranges:     []*updatepb.SourceRange{{Source: "xkcd", From: 1, To: 3050}},
+			Missing:    []int64{3049, 3050},
+			Tombstoned: []int64{404},
+			Failed:     []int64{3049},
+			Estimate:   durationpb.New(2 * time.Second),
+		}}, nil)
+
+	cl := Client{
+		log:    logger,
+		client: mockClient,
+	}
+
+	plan, err := cl.Plan(context.Background(), core.UpdateOptions{})
+	require.NoError(t, err)
+	require.Equal(t, core.UpdatePlan{
+		Mode:       "update",
+		Ranges:     []core.SourceRange{{Source: "xkcd", From: 1, To: 3050}},
+		Missing:    []int{3049, 3050},
+		Tombstoned: []int{404},
+		Failed:     []int{3049},
+		Estimate:   2 * time.Second,
+	}, plan)
+
+	mockClient.EXPECT().
+		Update(gomock.Any(), &updatepb.UpdateRequest{Reconcile: true, DryRun: true}, gomock.Any()).
+		Return(nil, status.Error(codes.Unavailable, "xkcd is unavailable"))
+
+	_, err = cl.Plan(context.Background(), core.UpdateOptions{Reconcile: true})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestClient_Cancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		Cancel(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
+		Return(&updatepb.StatusReply{
+			Status:   updatepb.Status_STATUS_CANCELLED,
+			Progress: &updatepb.Progress{Total: 10, Stored: 4},
+		}, nil)
+
+	cl := Client{
+		log:    logger,
+		client: mockClient,
+	}
+	state, err := cl.Cancel(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, core.UpdateState{
+		Status:   core.StatusUpdateCancelled,
+		Progress: core.UpdateProgress{Total: 10, Stored: 4},
+		XKCD:     core.XKCDState{Breaker: core.BreakerUnknown},
+	}, state)
+}
+
+func TestClient_RetryFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		RetryFailed(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
 		Return(&emptypb.Empty{}, nil)
 
 	cl := Client{
 		log:    logger,
 		client: mockClient,
 	}
+	require.NoError(t, cl.RetryFailed(context.Background()))
+}
+
+func TestClient_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-	err := cl.Update(context.Background())
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		Refresh(gomock.Any(), &updatepb.RefreshRequest{From: 1, To: 50}, gomock.Any()).
+		Return(&updatepb.RefreshReply{Checked: 50, Changed: 4}, nil)
+
+	cl := Client{
+		log:    logger,
+		client: mockClient,
+	}
+
+	result, err := cl.Refresh(context.Background(), core.RefreshOptions{From: 1, To: 50})
 	require.NoError(t, err)
+	require.Equal(t, core.RefreshResult{Checked: 50, Changed: 4}, result)
+}
+
+func TestClient_Failures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failedAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		Failures(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
+		Return(&updatepb.FailuresReply{Failures: []*updatepb.Failure{
+			{Id: 404, Stage: "fetch", Error: "not found", Attempts: 2, FailedAt: timestamppb.New(failedAt)},
+		}}, nil)
+
+	cl := Client{
+		log:    logger,
+		client: mockClient,
+	}
+	failures, err := cl.Failures(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []core.Failure{
+		{ID: 404, Stage: "fetch", Error: "not found", Attempts: 2, FailedAt: failedAt},
+	}, failures)
 }
 
 func TestClient_Drop(t *testing.T) {
@@ -143,14 +431,110 @@ func TestClient_Drop(t *testing.T) {
 	mockClient := mock_update.NewMockUpdateClient(ctrl)
 	mockClient.EXPECT().
 		Drop(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
+		Return(&updatepb.Snapshot{Name: "drop-1", Comics: 3, CreatedAt: timestamppb.New(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC))}, nil)
+
+	cl := Client{
+		log:    logger,
+		client: mockClient,
+	}
+	snapshot, err := cl.Drop(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, core.Snapshot{Name: "drop-1", Comics: 3, CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}, snapshot)
+
+	// без снимка
+	mockClient.EXPECT().
+		Drop(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
+		Return(&updatepb.Snapshot{}, nil)
+
+	snapshot, err = cl.Drop(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, core.Snapshot{}, snapshot)
+}
+
+func TestClient_History(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	started := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		History(gomock.Any(), &updatepb.HistoryRequest{Limit: 10, Offset: 20}, gomock.Any()).
+		Return(&updatepb.HistoryReply{Total: 21, Runs: []*updatepb.UpdateRun{{
+			Id: 1, Mode: "retry", Trigger: "manual", Status: "failed",
+			StartedAt: timestamppb.New(started), FinishedAt: timestamppb.New(started.Add(time.Second)),
+			Stored: 1, Failed: 2, Skipped: 3, Error: "db error",
+		}}}, nil)
+
+	cl := Client{
+		log:    logger,
+		client: mockClient,
+	}
+
+	history, err := cl.History(context.Background(), core.HistoryOptions{Limit: 10, Offset: 20})
+	require.NoError(t, err)
+	require.Equal(t, core.History{Total: 21, Runs: []core.UpdateRun{{
+		ID: 1, Mode: "retry", Trigger: "manual", Status: "failed",
+		StartedAt: started, FinishedAt: started.Add(time.Second),
+		Stored: 1, Failed: 2, Skipped: 3, Error: "db error",
+	}}}, history)
+}
+
+func TestClient_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		Delete(gomock.Any(), &updatepb.DeleteRequest{Ids: []int64{3, 7}}, gomock.Any()).
+		Return(&updatepb.DeleteReply{Deleted: 2}, nil)
+	mockClient.EXPECT().
+		Delete(gomock.Any(), &updatepb.DeleteRequest{From: 10, To: 20}, gomock.Any()).
+		Return(nil, errors.New("wrong range"))
+
+	cl := Client{
+		log:    logger,
+		client: mockClient,
+	}
+
+	deleted, err := cl.Delete(context.Background(), core.DeleteOptions{IDs: []int{3, 7}})
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	_, err = cl.Delete(context.Background(), core.DeleteOptions{From: 10, To: 20})
+	require.Error(t, err)
+}
+
+func TestClient_Snapshots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	created := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		ListSnapshots(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
+		Return(&updatepb.SnapshotsReply{Snapshots: []*updatepb.Snapshot{
+			{Name: "drop-2", Comics: 2, CreatedAt: timestamppb.New(created)},
+		}}, nil)
+	mockClient.EXPECT().
+		Restore(gomock.Any(), &updatepb.SnapshotRequest{Name: "drop-2"}, gomock.Any()).
 		Return(&emptypb.Empty{}, nil)
+	mockClient.EXPECT().
+		DeleteSnapshot(gomock.Any(), &updatepb.SnapshotRequest{Name: "drop-2"}, gomock.Any()).
+		Return(nil, errors.New("not found"))
 
 	cl := Client{
 		log:    logger,
 		client: mockClient,
 	}
-	err := cl.Drop(context.Background())
+
+	snapshots, err := cl.Snapshots(context.Background())
 	require.NoError(t, err)
+	require.Equal(t, []core.Snapshot{{Name: "drop-2", Comics: 2, CreatedAt: created}}, snapshots)
+
+	require.NoError(t, cl.Restore(context.Background(), "drop-2"))
+	require.Error(t, cl.DeleteSnapshot(context.Background(), "drop-2"))
 }
 
 func TestClient_Status_Error(t *testing.T) {
@@ -171,3 +555,132 @@ func TestClient_Status_Error(t *testing.T) {
 	require.Error(t, err)
 	require.Equal(t, expectedErr, err)
 }
+
+func TestClient_Schedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nextRun := time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		Schedule(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
+		Return(&updatepb.ScheduleReply{
+			Period:  durationpb.New(time.Hour),
+			NextRun: timestamppb.New(nextRun),
+		}, nil)
+
+	cl := Client{
+		log:    logger,
+		client: mockClient,
+	}
+
+	schedule, err := cl.Schedule(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, core.UpdateSchedule{Period: time.Hour, NextRun: nextRun}, schedule)
+}
+
+func TestClient_SetSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	paused := true
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		SetSchedule(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *updatepb.ScheduleRequest, _ ...any) (*updatepb.ScheduleReply, error) {
+			require.Nil(t, in.Period)
+			require.True(t, in.GetPaused())
+			return &updatepb.ScheduleReply{Period: durationpb.New(time.Hour), Paused: true}, nil
+		})
+
+	cl := Client{
+		log:    logger,
+		client: mockClient,
+	}
+
+	schedule, err := cl.SetSchedule(context.Background(), core.ScheduleSettings{Paused: &paused})
+	require.NoError(t, err)
+	require.Equal(t, core.UpdateSchedule{Period: time.Hour, Paused: true}, schedule)
+}
+
+type exportStream struct {
+	grpc.ClientStream
+	chunks []string
+	err    error
+}
+
+func (s *exportStream) Recv() (*updatepb.ArchiveChunk, error) {
+	if len(s.chunks) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return &updatepb.ArchiveChunk{Data: []byte(chunk)}, nil
+}
+
+func TestExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().
+		Export(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
+		Return(&exportStream{chunks: []string{"arch", "ive"}}, nil)
+
+	c := Client{log: logger, client: mockClient}
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Export(context.Background(), &buf))
+	require.Equal(t, "archive", buf.String())
+
+	mockClient.EXPECT().
+		Export(gomock.Any(), &emptypb.Empty{}, gomock.Any()).
+		Return(&exportStream{chunks: []string{"arch"}, err: errors.New("broken")}, nil)
+
+	require.Error(t, c.Export(context.Background(), &buf))
+}
+
+type importStream struct {
+	grpc.ClientStream
+	received bytes.Buffer
+	reply    *updatepb.ImportReply
+	err      error
+}
+
+func (s *importStream) Send(chunk *updatepb.ArchiveChunk) error {
+	s.received.Write(chunk.GetData())
+	return nil
+}
+
+func (s *importStream) CloseAndRecv() (*updatepb.ImportReply, error) {
+	return s.reply, s.err
+}
+
+func TestImport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// архив больше одного чанка
+	archive := strings.Repeat("x", chunkSize+10)
+	stream := &importStream{reply: &updatepb.ImportReply{Imported: 5, Skipped: 1, Errors: []string{"line 3: bad json"}}}
+
+	mockClient := mock_update.NewMockUpdateClient(ctrl)
+	mockClient.EXPECT().Import(gomock.Any()).Return(stream, nil)
+
+	c := Client{log: logger, client: mockClient}
+
+	result, err := c.Import(context.Background(), strings.NewReader(archive))
+	require.NoError(t, err)
+	require.Equal(t, core.ImportResult{Imported: 5, Skipped: 1, Errors: []string{"line 3: bad json"}}, result)
+	require.Equal(t, archive, stream.received.String())
+
+	mockClient.EXPECT().Import(gomock.Any()).Return(&importStream{err: errors.New("not a gzip archive")}, nil)
+
+	_, err = c.Import(context.Background(), strings.NewReader(archive))
+	require.Error(t, err)
+}
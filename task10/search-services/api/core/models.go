@@ -1,13 +1,80 @@
 package core
 
+import "time"
+
 type UpdateStatus string
 
 const (
-	StatusUpdateUnknown UpdateStatus = "unknown"
-	StatusUpdateIdle    UpdateStatus = "idle"
-	StatusUpdateRunning UpdateStatus = "running"
+	StatusUpdateUnknown    UpdateStatus = "unknown"
+	StatusUpdateIdle       UpdateStatus = "idle"
+	StatusUpdateRunning    UpdateStatus = "running"
+	StatusUpdateCancelled  UpdateStatus = "cancelled"
+	StatusUpdateFailed     UpdateStatus = "failed"
+	StatusUpdateCancelling UpdateStatus = "cancelling"
 )
 
+type UpdateProgress struct {
+	Total      int
+	Fetched    int
+	Normalized int
+	Stored     int
+	Failed     int
+	Unchanged  int
+	StartedAt  time.Time
+}
+
+type BreakerState string
+
+const (
+	BreakerUnknown  BreakerState = "unknown"
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+type XKCDState struct {
+	Retries int
+	Breaker BreakerState
+}
+
+// StageStats - метрики стадии конвейера обновления: Queue - глубина входной
+// очереди, Throughput - комиксов в секунду, Latency - среднее время операции
+type StageStats struct {
+	Workers    int
+	Buffer     int
+	Queue      int
+	Processed  int
+	Throughput float64
+	Latency    time.Duration
+}
+
+type PipelineStats struct {
+	Fetch StageStats
+	Norm  StageStats
+	Add   StageStats
+}
+
+type UpdateState struct {
+	Status   UpdateStatus
+	Progress UpdateProgress
+	XKCD     XKCDState
+	Replica  string
+	// LastRun - итог последнего запуска, nil - запусков ещё не было
+	LastRun  *UpdateRun
+	Pipeline PipelineStats
+	// Scheduled - планировщик назначил следующий запуск на NextRun
+	Scheduled bool
+	NextRun   time.Time
+}
+
+type Failure struct {
+	ID       int
+	Stage    string
+	Error    string
+	Attempts int
+	FailedAt time.Time
+}
+
 type UpdateStats struct {
 	WordsTotal    int
 	WordsUnique   int
@@ -15,8 +82,104 @@ type UpdateStats struct {
 	ComicsTotal   int
 }
 
+type UpdateSchedule struct {
+	Period  time.Duration
+	Paused  bool
+	NextRun time.Time
+	LastRun time.Time
+}
+
+type UpdateOptions struct {
+	Reconcile   bool
+	Resume      bool
+	NewestFirst bool
+}
+
+// UpdatePlan - что сделал бы запуск обновления, посчитанное без записи в БД
+type UpdatePlan struct {
+	Mode       string
+	Ranges     []SourceRange
+	Missing    []int
+	Tombstoned []int
+	Failed     []int
+	Estimate   time.Duration
+}
+
+// SourceRange - диапазон ID источника в БД, From - первый ID источника
+type SourceRange struct {
+	Source string
+	From   int
+	To     int
+}
+
+type RefreshOptions struct {
+	From int
+	To   int
+}
+
+type RefreshResult struct {
+	Checked int
+	Changed int
+}
+
+type ImportResult struct {
+	Imported int
+	Skipped  int
+	Errors   []string
+}
+
+// UpdateRun - запись журнала запусков обновления
+type UpdateRun struct {
+	ID         int64
+	Mode       string
+	Trigger    string
+	Status     string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Stored     int
+	Failed     int
+	Skipped    int
+	Error      string
+}
+
+type HistoryOptions struct {
+	Limit  int
+	Offset int
+}
+
+type History struct {
+	Runs  []UpdateRun
+	Total int
+}
+
+// DeleteOptions - удаляемые комиксы: список IDs или диапазон From..To включительно
+type DeleteOptions struct {
+	IDs  []int
+	From int
+	To   int
+}
+
+// Snapshot - копия комиксов, сохранённая перед Drop
+type Snapshot struct {
+	Name      string
+	Comics    int
+	CreatedAt time.Time
+}
+
+type ScheduleSettings struct {
+	Period *time.Duration
+	Paused *bool
+}
+
 type Comics struct {
-	ID    int
-	URL   string
-	Score int
+	ID         int
+	URL        string
+	Score      int
+	Title      string
+	Alt        string
+	Transcript string
+	Link       string
+	News       string
+	Published  time.Time
+	Source     string
 }
@@ -1,6 +1,9 @@
 package core
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 type Normalizer interface {
 	Norm(context.Context, string) ([]string, error)
@@ -11,10 +14,25 @@ type Pinger interface {
 }
 
 type Updater interface {
-	Update(context.Context) error
+	Update(context.Context, UpdateOptions) error
+	Plan(context.Context, UpdateOptions) (UpdatePlan, error)
+	Cancel(context.Context) (UpdateState, error)
+	RetryFailed(context.Context) error
+	Refresh(context.Context, RefreshOptions) (RefreshResult, error)
+	Export(context.Context, io.Writer) error
+	Import(context.Context, io.Reader) (ImportResult, error)
+	Failures(context.Context) ([]Failure, error)
+	History(context.Context, HistoryOptions) (History, error)
 	Stats(context.Context) (UpdateStats, error)
-	Status(context.Context) (UpdateStatus, error)
-	Drop(context.Context) error
+	Status(context.Context) (UpdateState, error)
+	WatchUpdate(context.Context) (<-chan UpdateState, error)
+	Delete(context.Context, DeleteOptions) (int, error)
+	Drop(context.Context) (Snapshot, error)
+	Snapshots(context.Context) ([]Snapshot, error)
+	Restore(context.Context, string) error
+	DeleteSnapshot(context.Context, string) error
+	Schedule(context.Context) (UpdateSchedule, error)
+	SetSchedule(context.Context, ScheduleSettings) (UpdateSchedule, error)
 }
 
 type Searcher interface {
@@ -56,12 +56,30 @@ func main() {
 		os.Exit(1)
 	}
 
+	// закрывается при остановке сервера и завершает SSE-потоки
+	streams := make(chan struct{})
+
 	mux := http.NewServeMux()
 	mux.Handle("GET /api/ping", rest.NewPingHandler(log, map[string]core.Pinger{"words": wordsClient, "update": updateClient, "search": searchClient}))
 	mux.Handle("GET /api/db/stats", rest.NewUpdateStatsHandler(log, updateClient))
 	mux.Handle("GET /api/db/status", rest.NewUpdateStatusHandler(log, updateClient))
+	mux.Handle("GET /api/db/update/events", rest.NewUpdateEventsHandler(log, updateClient, streams))
 	mux.Handle("POST /api/db/update", middleware.Auth(rest.NewUpdateHandler(log, updateClient), aaaClient))
+	mux.Handle("DELETE /api/db/update", middleware.Auth(rest.NewCancelHandler(log, updateClient), aaaClient))
+	mux.Handle("GET /api/db/failures", middleware.Auth(rest.NewFailuresHandler(log, updateClient), aaaClient))
+	mux.Handle("GET /api/db/updates", middleware.Auth(rest.NewHistoryHandler(log, updateClient), aaaClient))
+	mux.Handle("POST /api/db/failures/retry", middleware.Auth(rest.NewRetryFailedHandler(log, updateClient), aaaClient))
+	mux.Handle("POST /api/db/refresh", middleware.Auth(rest.NewRefreshHandler(log, updateClient), aaaClient))
+	mux.Handle("GET /api/db/export", middleware.Auth(rest.NewExportHandler(log, updateClient), aaaClient))
+	mux.Handle("POST /api/db/import", middleware.Auth(rest.NewImportHandler(log, updateClient), aaaClient))
+	mux.Handle("DELETE /api/comics/{id}", middleware.Auth(rest.NewDeleteComicsHandler(log, updateClient), aaaClient))
+	mux.Handle("DELETE /api/comics", middleware.Auth(rest.NewDeleteComicsRangeHandler(log, updateClient), aaaClient))
 	mux.Handle("DELETE /api/db", middleware.Auth(rest.NewDropHandler(log, updateClient), aaaClient))
+	mux.Handle("GET /api/db/snapshots", middleware.Auth(rest.NewSnapshotsHandler(log, updateClient), aaaClient))
+	mux.Handle("POST /api/db/snapshots/{name}/restore", middleware.Auth(rest.NewRestoreHandler(log, updateClient), aaaClient))
+	mux.Handle("DELETE /api/db/snapshots/{name}", middleware.Auth(rest.NewDeleteSnapshotHandler(log, updateClient), aaaClient))
+	mux.Handle("GET /api/db/schedule", middleware.Auth(rest.NewScheduleHandler(log, updateClient), aaaClient))
+	mux.Handle("PUT /api/db/schedule", middleware.Auth(rest.NewSetScheduleHandler(log, updateClient), aaaClient))
 
 	mux.Handle("POST /api/login", rest.NewLoginHandler(log, aaaClient))
 
@@ -73,6 +91,7 @@ func main() {
 		ReadTimeout: cfg.HTTPConfig.Timeout,
 		Handler:     mux,
 	}
+	server.RegisterOnShutdown(func() { close(streams) })
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
@@ -80,7 +99,9 @@ func main() {
 	go func() {
 		<-ctx.Done()
 		log.Debug("shutting down server")
-		if err := server.Shutdown(context.Background()); err != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPConfig.Timeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
 			log.Error("erroneous shutdown", "error", err)
 		}
 	}()
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"yadro.com/course/fakexkcd/server"
+)
+
+func main() {
+	var (
+		address      string
+		fixtures     string
+		missing      string
+		publishEvery time.Duration
+		opts         server.Options
+	)
+	flag.StringVar(&address, "address", ":8080", "listen address")
+	flag.StringVar(&fixtures, "fixtures", "fixtures", "directory with <id>.json comics")
+	flag.IntVar(&opts.Latest, "latest", 0, "latest comics number, 0 - latest fixture")
+	flag.StringVar(&missing, "missing", "404", "comma separated numbers answering 404")
+	flag.DurationVar(&opts.Latency, "latency", 0, "delay before every response")
+	flag.IntVar(&opts.ErrorEvery, "error-every", 0, "start a burst of 503 every N requests, 0 - never")
+	flag.IntVar(&opts.ErrorBurst, "error-burst", 0, "number of 503 responses in a burst")
+	flag.DurationVar(&publishEvery, "publish-every", 0, "publish a new comics every period, 0 - never")
+	flag.Parse()
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	for _, field := range strings.Split(missing, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			log.Error("wrong missing comics number", "value", field)
+			os.Exit(1)
+		}
+		opts.Missing = append(opts.Missing, id)
+	}
+
+	srv, err := server.New(log, os.DirFS(fixtures), opts)
+	if err != nil {
+		log.Error("failed to create fake xkcd", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if publishEvery > 0 {
+		go func() {
+			ticker := time.NewTicker(publishEvery)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					srv.Publish()
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	httpServer := http.Server{Addr: address, Handler: srv}
+	go func() {
+		<-ctx.Done()
+		log.Debug("shutting down server")
+		if err := httpServer.Shutdown(context.Background()); err != nil {
+			log.Error("erroneous shutdown", "error", err)
+		}
+	}()
+
+	log.Info("fake xkcd is running", "address", address, "latest", srv.Latest())
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Error("server closed unexpectedly", "error", err)
+		os.Exit(1)
+	}
+}
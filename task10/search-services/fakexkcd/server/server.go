@@ -0,0 +1,259 @@
+// Package server - поддельный xkcd.com для разработки и тестов без сети.
+// Отдаёт /info.0.json и /{id}/info.0.json из каталога фикстур, а недостающие
+// до Latest номера заполняет сгенерированными комиксами.
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const infoFile = "info.0.json"
+
+type Options struct {
+	// Latest - номер последнего комикса, 0 - последний из фикстур
+	Latest int
+	// Missing - номера, на которые отвечать 404, как настоящий xkcd на 404
+	Missing []int
+	// Latency - задержка перед каждым ответом
+	Latency time.Duration
+	// каждые ErrorEvery запросов ErrorBurst подряд получают 503, 0 - без ошибок
+	ErrorEvery int
+	ErrorBurst int
+}
+
+// Comics - комикс в формате xkcd
+type Comics struct {
+	Num        int    `json:"num"`
+	Img        string `json:"img"`
+	Title      string `json:"title"`
+	SafeTitle  string `json:"safe_title"`
+	Transcript string `json:"transcript"`
+	Alt        string `json:"alt"`
+	Link       string `json:"link"`
+	News       string `json:"news"`
+	Day        string `json:"day"`
+	Month      string `json:"month"`
+	Year       string `json:"year"`
+}
+
+type Server struct {
+	log      *slog.Logger
+	opts     Options
+	fixtures map[int]Comics
+	missing  map[int]struct{}
+	started  time.Time
+
+	mx       sync.Mutex
+	latest   int
+	requests int
+}
+
+// New загружает фикстуры вида <id>.json из корня fixtures; без фикстур
+// все комиксы до Latest генерируются
+func New(log *slog.Logger, fixtures fs.FS, opts Options) (*Server, error) {
+	if opts.Latest < 0 || opts.ErrorEvery < 0 || opts.ErrorBurst < 0 {
+		return nil, fmt.Errorf("wrong options: %+v", opts)
+	}
+
+	s := &Server{
+		log:      log,
+		opts:     opts,
+		fixtures: make(map[int]Comics),
+		missing:  make(map[int]struct{}, len(opts.Missing)),
+		started:  time.Now().UTC().Truncate(time.Second),
+		latest:   opts.Latest,
+	}
+	for _, id := range opts.Missing {
+		s.missing[id] = struct{}{}
+	}
+
+	var files []string
+	if fixtures != nil {
+		var err error
+		if files, err = fs.Glob(fixtures, "*.json"); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range files {
+		id, err := strconv.Atoi(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		data, err := fs.ReadFile(fixtures, name)
+		if err != nil {
+			return nil, fmt.Errorf("read fixture %s: %w", name, err)
+		}
+		var comics Comics
+		if err := json.Unmarshal(data, &comics); err != nil {
+			return nil, fmt.Errorf("decode fixture %s: %w", name, err)
+		}
+		comics.Num = id
+		s.fixtures[id] = comics
+		s.latest = max(s.latest, id)
+	}
+
+	if s.latest == 0 {
+		return nil, fmt.Errorf("no fixtures found and latest is not set")
+	}
+	return s, nil
+}
+
+// Publish выпускает следующий комикс и возвращает его номер
+func (s *Server) Publish() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.latest++
+	s.log.Info("comics published", "id", s.latest)
+	return s.latest
+}
+
+func (s *Server) Latest() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return s.latest
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// клиент update ходит на /{id}//info.0.json, поэтому пустые части пути пропускаем
+	var parts []string
+	for _, part := range strings.Split(r.URL.Path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if len(parts) == 2 && parts[0] == "_fake" && parts[1] == "publish" && r.Method == http.MethodPost {
+		fmt.Fprintln(w, s.Publish())
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.opts.Latency > 0 {
+		select {
+		case <-time.After(s.opts.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if s.failing() {
+		http.Error(w, "simulated failure", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && parts[0] == infoFile:
+		s.serve(w, r, s.Latest())
+	case len(parts) == 2 && parts[1] == infoFile:
+		id, err := strconv.Atoi(parts[0])
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		s.serve(w, r, id)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// failing отсчитывает запросы и решает, попадает ли этот во всплеск 503
+func (s *Server) failing() bool {
+	if s.opts.ErrorEvery == 0 || s.opts.ErrorBurst == 0 {
+		return false
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	// всплеск замыкает каждую серию из ErrorEvery запросов
+	n := s.requests % s.opts.ErrorEvery
+	s.requests++
+	return n >= s.opts.ErrorEvery-s.opts.ErrorBurst
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, id int) {
+	if _, ok := s.missing[id]; ok || id < 1 || id > s.Latest() {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := json.Marshal(s.comics(id))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:8])+`"`)
+	// http.ServeContent сам отвечает 304 на If-None-Match и If-Modified-Since
+	http.ServeContent(w, r, path.Join(strconv.Itoa(id), infoFile), s.started, strings.NewReader(string(body)))
+}
+
+func (s *Server) comics(id int) Comics {
+	if comics, ok := s.fixtures[id]; ok {
+		return comics
+	}
+	return generate(id)
+}
+
+// слова для сгенерированных комиксов подобраны так, чтобы не пересекаться
+// с фразами из интеграционных тестов
+var vocabulary = strings.Fields(`
+	ocean river mountain forest desert island valley canyon glacier volcano
+	teacher pilot farmer sailor painter baker dancer singer writer builder
+	bicycle rocket submarine tractor balloon elevator bridge tunnel castle tower
+	physics chemistry biology geology astronomy statistics grammar history music poetry
+	coffee pizza sandwich cookie pancake noodle cheese pepper tomato carrot
+	keyboard printer router monitor battery antenna cable server laptop camera
+	dragon robot wizard pirate ghost zombie alien knight giant dinosaur
+	winter summer autumn spring morning evening midnight weekend holiday birthday
+	purple orange yellow silver golden crimson violet turquoise scarlet emerald
+	jumping running swimming flying singing cooking reading writing painting dancing
+	gravity magnet laser plasma quantum neutron photon proton galaxy comet
+	puzzle riddle secret mystery treasure journey adventure question answer theory
+	umbrella blanket pillow lantern ladder bucket hammer shovel compass anchor
+	penguin giraffe octopus dolphin squirrel hamster parrot turtle rabbit beaver
+	election committee meeting deadline project budget invoice contract manager intern
+	`)
+
+func generate(id int) Comics {
+	rnd := rand.New(rand.NewPCG(uint64(id), 0x6b63_6478))
+	words := func(n int) string {
+		picked := make([]string, n)
+		for i := range picked {
+			picked[i] = vocabulary[rnd.IntN(len(vocabulary))]
+		}
+		return strings.Join(picked, " ")
+	}
+
+	title := words(2)
+	published := time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, id*2)
+	return Comics{
+		Num:        id,
+		Img:        fmt.Sprintf("https://imgs.xkcd.com/comics/fake_%d.png", id),
+		Title:      title,
+		SafeTitle:  title,
+		Transcript: words(8),
+		Alt:        words(6),
+		Day:        strconv.Itoa(published.Day()),
+		Month:      strconv.Itoa(int(published.Month())),
+		Year:       strconv.Itoa(published.Year()),
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+var fixtures = fstest.MapFS{
+	"2.json":    {Data: []byte(`{"num": 2, "img": "https://imgs.xkcd.com/comics/two.png", "title": "Two"}`)},
+	"README.md": {Data: []byte("not a comics")},
+}
+
+func get(t *testing.T, srv http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) Comics {
+	t.Helper()
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var comics Comics
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&comics))
+	return comics
+}
+
+func TestServer(t *testing.T) {
+	srv, err := New(logger, fixtures, Options{Latest: 5, Missing: []int{4}})
+	require.NoError(t, err)
+
+	require.Equal(t, 5, decode(t, get(t, srv, "/info.0.json", nil)).Num)
+
+	comics := decode(t, get(t, srv, "/2/info.0.json", nil))
+	require.Equal(t, "Two", comics.Title)
+
+	// без фикстуры комикс генерируется, и всегда одинаково
+	generated := decode(t, get(t, srv, "/3//info.0.json", nil))
+	require.Equal(t, 3, generated.Num)
+	require.NotEmpty(t, generated.Title)
+	require.Equal(t, generated, decode(t, get(t, srv, "/3/info.0.json", nil)))
+
+	require.Equal(t, http.StatusNotFound, get(t, srv, "/4/info.0.json", nil).Code)
+	require.Equal(t, http.StatusNotFound, get(t, srv, "/6/info.0.json", nil).Code)
+	require.Equal(t, http.StatusNotFound, get(t, srv, "/abc/info.0.json", nil).Code)
+	require.Equal(t, http.StatusNotFound, get(t, srv, "/", nil).Code)
+}
+
+func TestServer_Publish(t *testing.T) {
+	srv, err := New(logger, fixtures, Options{})
+	require.NoError(t, err)
+	require.Equal(t, 2, srv.Latest())
+
+	require.Equal(t, http.StatusNotFound, get(t, srv, "/3/info.0.json", nil).Code)
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/_fake/publish", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "3\n", w.Body.String())
+
+	require.Equal(t, 3, decode(t, get(t, srv, "/info.0.json", nil)).Num)
+	require.Equal(t, 3, decode(t, get(t, srv, "/3/info.0.json", nil)).Num)
+}
+
+func TestServer_NotModified(t *testing.T) {
+	srv, err := New(logger, fixtures, Options{})
+	require.NoError(t, err)
+
+	w := get(t, srv, "/info.0.json", nil)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	require.NotEmpty(t, w.Header().Get("Last-Modified"))
+
+	require.Equal(t, http.StatusNotModified, get(t, srv, "/info.0.json", http.Header{"If-None-Match": {etag}}).Code)
+
+	// вышел новый комикс - ETag последнего поменялся
+	srv.Publish()
+	require.Equal(t, http.StatusOK, get(t, srv, "/info.0.json", http.Header{"If-None-Match": {etag}}).Code)
+}
+
+func TestServer_ErrorBurst(t *testing.T) {
+	srv, err := New(logger, fixtures, Options{ErrorEvery: 4, ErrorBurst: 2})
+	require.NoError(t, err)
+
+	var codes []int
+	for range 8 {
+		codes = append(codes, get(t, srv, "/2/info.0.json", nil).Code)
+	}
+	require.Equal(t, []int{200, 200, 503, 503, 200, 200, 503, 503}, codes)
+}
+
+func TestServer_Latency(t *testing.T) {
+	srv, err := New(logger, fixtures, Options{Latency: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	start := time.Now()
+	decode(t, get(t, srv, "/2/info.0.json", nil))
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := New(logger, fstest.MapFS{}, Options{})
+	require.Error(t, err)
+
+	_, err = New(logger, fixtures, Options{Latest: -1})
+	require.Error(t, err)
+
+	_, err = New(logger, fstest.MapFS{"1.json": {Data: []byte("{broken")}}, Options{})
+	require.Error(t, err)
+}
+
+func TestFixtures(t *testing.T) {
+	srv, err := New(logger, os.DirFS("../fixtures"), Options{})
+	require.NoError(t, err)
+	require.Equal(t, "Tree", decode(t, get(t, srv, "/835/info.0.json", nil)).Title)
+}
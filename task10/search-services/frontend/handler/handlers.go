@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"html/template"
 	"io"
 	"log/slog"
@@ -187,6 +188,90 @@ func HandlerStats(client *http.Client, api_address string, log *slog.Logger) htt
 	}
 }
 
+// сколько запусков показывать на странице журнала
+const updatesPageSize = 20
+
+func HandlerUpdates(client *http.Client, api_address string, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("token")
+		if err != nil {
+			tmpl, err := template.ParseFiles("templates/auth/unauthorized.html")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			err = tmpl.Execute(w, nil)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+		if err != nil || offset < 0 {
+			offset = 0
+		}
+
+		req, err := http.NewRequest(http.MethodGet,
+			fmt.Sprintf("%s/api/db/updates?limit=%d&offset=%d", api_address, updatesPageSize, offset), nil)
+		if err != nil {
+			http.Error(w, "Ошибка создания запроса", http.StatusInternalServerError)
+			return
+		}
+		req.Header.Set("Authorization", "Token "+cookie.Value)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			log.Error("HandlerUpdates", "error", err)
+			http.Error(w, "Не удалось получить журнал обновлений", http.StatusInternalServerError)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+			tmpl, err := template.ParseFiles("templates/auth/unauthorized.html")
+			if err != nil {
+				http.Error(w, "Не удалось открыть страницу недостаточно прав", http.StatusInternalServerError)
+				return
+			}
+			err = tmpl.Execute(w, nil)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			log.Error("HandlerUpdates", "status", resp.StatusCode)
+			http.Error(w, "Не удалось получить журнал обновлений", http.StatusBadGateway)
+			return
+		}
+
+		var page model.HistoryPage
+		if err := json.NewDecoder(resp.Body).Decode(&page.HistoryResponse); err != nil {
+			log.Error("HandlerUpdates", "error", err)
+			http.Error(w, "Не удалось получить журнал обновлений", http.StatusInternalServerError)
+			return
+		}
+
+		page.HasPrev = offset > 0
+		page.Prev = max(offset-updatesPageSize, 0)
+		page.HasNext = offset+len(page.Runs) < page.Total
+		page.Next = offset + updatesPageSize
+
+		tmpl, err := template.ParseFiles("templates/updates/updates.html")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		err = tmpl.Execute(w, page)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
 func HandlerStatus(client *http.Client, api_address string, log *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var status model.Status
@@ -218,6 +303,54 @@ func HandlerStatus(client *http.Client, api_address string, log *slog.Logger) ht
 	}
 }
 
+func HandlerStatusEvents(client *http.Client, apiAddress string, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Стриминг не поддерживается", http.StatusInternalServerError)
+			return
+		}
+
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, apiAddress+"/api/db/update/events", nil)
+		if err != nil {
+			http.Error(w, "Ошибка создания запроса", http.StatusInternalServerError)
+			return
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			log.Error("HandlerStatusEvents", "error", err)
+			http.Error(w, "Не удалось подписаться на статус", http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			http.Error(w, "Не удалось подписаться на статус", http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		buf := make([]byte, 4096)
+		for {
+			n, err := resp.Body.Read(buf)
+			if n > 0 {
+				if _, err := w.Write(buf[:n]); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
 func HandlerDrop(client *http.Client, apiAddress string, log *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		cookie, err := r.Cookie("token")
@@ -263,17 +396,28 @@ func HandlerDrop(client *http.Client, apiAddress string, log *slog.Logger) http.
 			return
 		}
 
+		if resp.StatusCode == http.StatusConflict {
+			http.Error(w, "Идёт обновление, удалить комиксы нельзя", http.StatusConflict)
+			return
+		}
+
 		if resp.StatusCode != http.StatusOK {
 			http.Error(w, "Ошибка при удалении", http.StatusInternalServerError)
 			return
 		}
 
+		// снимок только для информации, без него страница всё равно показывается
+		var dropped model.DropResponse
+		if err := json.NewDecoder(resp.Body).Decode(&dropped); err != nil {
+			log.Error("HandlerDrop", "error", err)
+		}
+
 		tmpl, err := template.ParseFiles("templates/drop/drop.html")
 		if err != nil {
 			http.Error(w, "Не удалось открыть страницу успеха", http.StatusInternalServerError)
 			return
 		}
-		err = tmpl.Execute(w, nil)
+		err = tmpl.Execute(w, dropped)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
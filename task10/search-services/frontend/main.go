@@ -37,8 +37,12 @@ func main() {
 
 	mux.HandleFunc("GET /stats", handler.HandlerStats(http.DefaultClient, "http://"+cfg.Api_address, log))
 
+	mux.HandleFunc("GET /updates", handler.HandlerUpdates(http.DefaultClient, "http://"+cfg.Api_address, log))
+
 	mux.HandleFunc("GET /status", handler.HandlerStatus(http.DefaultClient, "http://"+cfg.Api_address, log))
 
+	mux.HandleFunc("GET /status/events", handler.HandlerStatusEvents(http.DefaultClient, "http://"+cfg.Api_address, log))
+
 	mux.HandleFunc("GET /drop", handler.HandlerDrop(http.DefaultClient, "http://"+cfg.Api_address, log))
 
 	mux.HandleFunc("GET /update", handler.HandlerUpdate(http.DefaultClient, "http://"+cfg.Api_address, log))
@@ -1,8 +1,13 @@
 package model
 
+import "time"
+
 type Comics struct {
-	ID  int    `json:"id"`
-	URL string `json:"url"`
+	ID     int    `json:"id"`
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Alt    string `json:"alt"`
+	Source string `json:"source"`
 }
 
 type ComicsResponse struct {
@@ -24,8 +29,65 @@ type AuthInfo struct {
 	Password string `json:"password"`
 }
 
+type Progress struct {
+	Total      int        `json:"total"`
+	Fetched    int        `json:"fetched"`
+	Normalized int        `json:"normalized"`
+	Stored     int        `json:"stored"`
+	Failed     int        `json:"failed"`
+	Unchanged  int        `json:"unchanged"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+}
+
 type Status struct {
-	Status string `json:"status"`
+	Status   string     `json:"status"`
+	Progress Progress   `json:"progress"`
+	Replica  string     `json:"replica,omitempty"`
+	LastRun  *UpdateRun `json:"last_run,omitempty"`
+	// Scheduled - планировщик назначил следующий запуск на NextRun
+	Scheduled bool       `json:"scheduled"`
+	NextRun   *time.Time `json:"next_run,omitempty"`
+}
+
+type Snapshot struct {
+	Name      string    `json:"name"`
+	Comics    int       `json:"comics"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DropResponse struct {
+	Snapshot *Snapshot `json:"snapshot"`
+}
+
+type UpdateRun struct {
+	ID         int64     `json:"id"`
+	Mode       string    `json:"mode"`
+	Trigger    string    `json:"trigger"`
+	Status     string    `json:"status"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   string    `json:"duration"`
+	Stored     int       `json:"stored"`
+	Failed     int       `json:"failed"`
+	Skipped    int       `json:"skipped"`
+	Error      string    `json:"error"`
+	Summary    string    `json:"summary"`
+}
+
+type HistoryResponse struct {
+	Runs   []UpdateRun `json:"runs"`
+	Total  int         `json:"total"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+}
+
+// HistoryPage - страница журнала запусков со ссылками на соседние страницы
+type HistoryPage struct {
+	HistoryResponse
+	Prev    int
+	Next    int
+	HasPrev bool
+	HasNext bool
 }
 
 type StatsResponse struct {
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// changesChannel - канал, в который update-сервис шлёт NOTIFY после изменения комиксов
+const changesChannel = "comics_changed"
+
+// Listener держит отдельное соединение под LISTEN: в пуле sqlx
+// подписка потерялась бы вместе с соединением
+type Listener struct {
+	log     *slog.Logger
+	address string
+	retry   time.Duration
+}
+
+func NewListener(log *slog.Logger, address string, retry time.Duration) *Listener {
+	return &Listener{
+		log:     log,
+		address: address,
+		retry:   retry,
+	}
+}
+
+// Listen возвращает причины изменений. Уведомления, пришедшие пока предыдущее
+// не прочитано, схлопываются в одно - индекс всё равно перестраивается целиком.
+func (l *Listener) Listen(ctx context.Context) <-chan string {
+	changes := make(chan string, 1)
+
+	go func() {
+		reconnect := false
+		for {
+			err := l.listen(ctx, changes, reconnect)
+			if ctx.Err() != nil {
+				return
+			}
+			l.log.Error("comics changes subscription failed", "error", err, "retry", l.retry)
+			reconnect = true
+
+			select {
+			case <-time.After(l.retry):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return changes
+}
+
+func (l *Listener) listen(ctx context.Context, changes chan string, reconnect bool) error {
+	conn, err := pgx.Connect(ctx, l.address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+changesChannel); err != nil {
+		return err
+	}
+	l.log.Info("subscribed to comics changes", "channel", changesChannel)
+
+	// пока соединения не было, изменения могли пройти мимо
+	if reconnect {
+		send(changes, "reconnect")
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		send(changes, notification.Payload)
+	}
+}
+
+func send(changes chan string, reason string) {
+	select {
+	case changes <- reason:
+	default:
+	}
+}
@@ -3,6 +3,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
 
@@ -37,10 +38,11 @@ func New(log *slog.Logger, address string) (*DB, error) {
 }
 
 func (db *DB) SearchByWord(ctx context.Context, keyword string) ([]int, error) {
+	// слово может встречаться в нескольких полях комикса
 	query := `
-	SELECT comics_id 
-	FROM comics
-	WHERE $1 = ANY(keywords)
+	SELECT DISTINCT comic_id
+	FROM comic_keywords
+	WHERE keyword = $1
 	`
 
 	var IDs []int
@@ -54,54 +56,82 @@ func (db *DB) SearchByWord(ctx context.Context, keyword string) ([]int, error) {
 	return IDs, err
 }
 
+type metadataRow struct {
+	Title      string       `db:"title"`
+	Alt        string       `db:"alt"`
+	Transcript string       `db:"transcript"`
+	Link       string       `db:"link"`
+	News       string       `db:"news"`
+	Published  sql.NullTime `db:"published"`
+	Source     string       `db:"source"`
+}
+
+func (m metadataRow) comics(id int, url string) core.Comics {
+	return core.Comics{
+		ID:         id,
+		URL:        url,
+		Title:      m.Title,
+		Alt:        m.Alt,
+		Transcript: m.Transcript,
+		Link:       m.Link,
+		News:       m.News,
+		Published:  m.Published.Time,
+		Source:     m.Source,
+	}
+}
+
 type comicRow struct {
 	ID       int              `db:"comics_id"`
 	Keywords pgtype.TextArray `db:"keywords"`
 	URL      string           `db:"img_url"`
+	metadataRow
 }
 
-func (db *DB) FetchComics(ctx context.Context, id int) (core.Comics, []string, error) {
-	query := `
-        SELECT comics_id, img_url, keywords 
-        FROM comics 
-        WHERE comics_id = $1
-    `
-
-	var res comicRow
-
-	err := db.conn.GetContext(ctx, &res, query, id)
-	if err != nil {
-		db.log.Error("Fetch keywords error", "error", err, "id", id)
-		return core.Comics{}, nil, fmt.Errorf("fetch keywords: %w", err)
+// страница выгрузки для индекса: вся таблица в один ответ не нужна
+const walkPageSize = 1000
+
+// Walk передаёт fn все комиксы с ключевыми словами по возрастанию ID
+func (db *DB) Walk(ctx context.Context, fn func(core.Comics, []string) error) error {
+	lastID := 0
+	for {
+		var rows []comicRow
+		err := db.conn.SelectContext(ctx, &rows, `
+		SELECT comics_id, img_url, keywords, title, alt, transcript, link, news, published, source
+		FROM comics
+		WHERE comics_id > $1
+		ORDER BY comics_id
+		LIMIT $2`, lastID, walkPageSize)
+		if err != nil {
+			db.log.Error("failed to fetch comics page", "after", lastID, "error", err)
+			return fmt.Errorf("fetch comics after %d: %w", lastID, err)
+		}
+
+		for _, row := range rows {
+			var keywords []string
+			if err := row.Keywords.AssignTo(&keywords); err != nil {
+				return fmt.Errorf("convert keywords of comics %d: %w", row.ID, err)
+			}
+			if err := fn(row.comics(row.ID, row.URL), keywords); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < walkPageSize {
+			return nil
+		}
+		lastID = rows[len(rows)-1].ID
 	}
-
-	var keywords []string
-
-	if err := res.Keywords.AssignTo(&keywords); err != nil {
-		return core.Comics{}, nil, fmt.Errorf("convert keywords: %w", err)
-	}
-
-	return core.Comics{ID: res.ID, URL: res.URL}, keywords, nil
-}
-
-func (db *DB) GetMaxID(ctx context.Context) (int, error) {
-	query := `
-	SELECT COALESCE(MAX(comics_id), 0)
-	FROM comics
-	`
-	var maxID int
-	err := db.conn.GetContext(ctx, &maxID, query)
-	return maxID, err
 }
 
 type comicsInf struct {
 	ID  int    `db:"comics_id"`
 	URL string `db:"img_url"`
+	metadataRow
 }
 
 func (db *DB) GetComics(ctx context.Context, id int) (core.Comics, error) {
 	query := `
-	SELECT comics_id, img_url
+	SELECT comics_id, img_url, title, alt, transcript, link, news, published, source
 	FROM comics
 	WHERE comics_id = $1
 	`
@@ -110,5 +140,5 @@ func (db *DB) GetComics(ctx context.Context, id int) (core.Comics, error) {
 
 	err := db.conn.GetContext(ctx, &comics, query, id)
 
-	return core.Comics{ID: comics.ID, URL: comics.URL}, err
+	return comics.comics(comics.ID, comics.URL), err
 }
@@ -2,16 +2,19 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"io"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	mock_dbops "yadro.com/course/search/adapters/db/mocks"
+	"yadro.com/course/search/core"
 )
 
 var logger = slog.New(slog.NewTextHandler(io.Discard, nil))
@@ -52,98 +55,88 @@ func TestSearchByWord(t *testing.T) {
 	}
 }
 
-func TestFetchComics_Success(t *testing.T) {
+func TestSearchByWord_ComicKeywords(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockConn := mock_dbops.NewMockDBops(ctrl)
-
-	d := &DB{
-		log:  logger,
-		conn: mockConn,
-	}
-	ctx := context.Background()
-	id := 42
-
-	mockConn.
-		EXPECT().
-		GetContext(ctx, gomock.Any(), gomock.Any(), id).
-		DoAndReturn(func(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-			r, ok := dest.(*comicRow)
-			if !ok {
-				return errors.New("unexpected dest type")
-			}
-			r.ID = id
-			r.URL = "http://xkcd.com/img"
-			// Имитируем установку массива ключевых слов.
-			var arr pgtype.TextArray
-			err := arr.Set([]string{"action", "thriller"})
-			if err != nil {
-				return err
-			}
-			r.Keywords = arr
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), "apple").
+		DoAndReturn(func(_ context.Context, dest any, query string, _ ...any) error {
+			// слово из заголовка и из alt одного комикса даёт один ID
+			assert.Contains(t, query, "SELECT DISTINCT comic_id")
+			assert.Contains(t, query, "FROM comic_keywords")
+			assert.Contains(t, query, "WHERE keyword = $1")
+			*dest.(*[]int) = []int{1, 1000003}
 			return nil
 		})
 
-	comic, keywords, err := d.FetchComics(ctx, id)
-	require.NoError(t, err)
-	require.Equal(t, id, comic.ID)
-	require.Equal(t, "http://xkcd.com/img", comic.URL)
-	require.Equal(t, []string{"action", "thriller"}, keywords)
-}
-
-func TestFetchComics_Error(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mockConn := mock_dbops.NewMockDBops(ctrl)
-	d := DB{
+	db := DB{
 		log:  logger,
-		conn: mockConn,
+		conn: mockDBops,
 	}
-	ctx := context.Background()
-	id := 100
 
-	expectedErr := errors.New("unexpected error")
-	mockConn.
-		EXPECT().
-		GetContext(ctx, gomock.Any(), gomock.Any(), id).
-		Return(expectedErr)
-
-	_, _, err := d.FetchComics(ctx, id)
-	require.ErrorIs(t, err, expectedErr)
+	ids, err := db.SearchByWord(context.Background(), "apple")
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 1000003}, ids)
 }
 
-func TestGetMaxID(t *testing.T) {
+func TestWalk(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockConn := mock_dbops.NewMockDBops(ctrl)
-	d := DB{
+	d := &DB{
 		log:  logger,
 		conn: mockConn,
 	}
 	ctx := context.Background()
 
-	// Ожидаем вызов GetContext, который записывает максимальный ID.
-	mockConn.
-		EXPECT().
-		GetContext(ctx, gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-			ptr, ok := dest.(*int)
-			if !ok {
-				return errors.New("expected *int as dest")
-			}
-			*ptr = 100
-			return nil
-		})
-
-	maxID, err := d.GetMaxID(ctx)
+	var keywords pgtype.TextArray
+	require.NoError(t, keywords.Set([]string{"action", "thriller"}))
+
+	// полная страница - за ней запрашивается следующая после последнего ID,
+	// ID идут с пропусками, как у источников со смещением
+	gomock.InOrder(
+		mockConn.
+			EXPECT().
+			SelectContext(ctx, gomock.Any(), gomock.Any(), 0, walkPageSize).
+			DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+				rows := dest.(*[]comicRow)
+				for i := range walkPageSize {
+					*rows = append(*rows, comicRow{ID: i + 1, Keywords: keywords})
+				}
+				return nil
+			}),
+		mockConn.
+			EXPECT().
+			SelectContext(ctx, gomock.Any(), gomock.Any(), walkPageSize, walkPageSize).
+			DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+				row := comicRow{ID: 1000001, URL: "http://xkcd.com/img", Keywords: keywords}
+				row.Transcript = "Transcript"
+				*dest.(*[]comicRow) = []comicRow{row}
+				return nil
+			}),
+	)
+
+	var last core.Comics
+	var lastKeywords []string
+	walked := 0
+	err := d.Walk(ctx, func(comics core.Comics, keywords []string) error {
+		walked++
+		last, lastKeywords = comics, keywords
+		return nil
+	})
 	require.NoError(t, err)
-	require.Equal(t, 100, maxID)
+	require.Equal(t, walkPageSize+1, walked)
+	require.Equal(t, 1000001, last.ID)
+	require.Equal(t, "http://xkcd.com/img", last.URL)
+	require.Equal(t, "Transcript", last.Transcript)
+	require.True(t, last.Published.IsZero())
+	require.Equal(t, []string{"action", "thriller"}, lastKeywords)
 }
 
-func TestGetMaxID_Error(t *testing.T) {
+func TestWalk_Error(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -152,16 +145,35 @@ func TestGetMaxID_Error(t *testing.T) {
 		log:  logger,
 		conn: mockConn,
 	}
-	ctx := context.Background()
-	expectedErr := errors.New("unexpected error")
 
+	expectedErr := errors.New("unexpected error")
 	mockConn.
 		EXPECT().
-		GetContext(ctx, gomock.Any(), gomock.Any()).
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), 0, walkPageSize).
 		Return(expectedErr)
-
-	_, err := d.GetMaxID(ctx)
+	err := d.Walk(context.Background(), func(core.Comics, []string) error { return nil })
 	require.ErrorIs(t, err, expectedErr)
+
+	// ошибка обработчика прерывает обход
+	mockConn.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), 0, walkPageSize).
+		DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+			var keywords pgtype.TextArray
+			if err := keywords.Set([]string{"word"}); err != nil {
+				return err
+			}
+			*dest.(*[]comicRow) = []comicRow{{ID: 1, Keywords: keywords}, {ID: 2, Keywords: keywords}}
+			return nil
+		})
+	stop := errors.New("stop")
+	calls := 0
+	err = d.Walk(context.Background(), func(core.Comics, []string) error {
+		calls++
+		return stop
+	})
+	require.ErrorIs(t, err, stop)
+	require.Equal(t, 1, calls)
 }
 
 func TestGetComics(t *testing.T) {
@@ -186,6 +198,10 @@ func TestGetComics(t *testing.T) {
 			}
 			r.ID = id
 			r.URL = "http://example.com/comic7.jpg"
+			r.Title = "Title"
+			r.Alt = "Alt"
+			r.Published = sql.NullTime{Time: time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true}
+			r.Source = "xkcd"
 			return nil
 		})
 
@@ -193,6 +209,10 @@ func TestGetComics(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, id, comic.ID)
 	require.Equal(t, "http://example.com/comic7.jpg", comic.URL)
+	require.Equal(t, "Title", comic.Title)
+	require.Equal(t, "Alt", comic.Alt)
+	require.Equal(t, time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC), comic.Published)
+	require.Equal(t, "xkcd", comic.Source)
 }
 
 func TestGetComics_Error(t *testing.T) {
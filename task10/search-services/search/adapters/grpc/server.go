@@ -5,6 +5,7 @@ import (
 	"context"
 
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	searchpb "yadro.com/course/proto/search"
 	"yadro.com/course/search/core"
 )
@@ -31,7 +32,7 @@ func (s *Server) DbSearch(ctx context.Context, in *searchpb.SearchRequest) (*sea
 	comicsResponse := make([]*searchpb.Comics, 0, len(comics))
 
 	for _, x := range comics {
-		comicsResponse = append(comicsResponse, &searchpb.Comics{Id: int64(x.ID), Url: x.URL})
+		comicsResponse = append(comicsResponse, comicsReply(x))
 	}
 
 	return &searchpb.SearchReply{Comics: comicsResponse}, nil
@@ -46,8 +47,25 @@ func (s *Server) IndexSearch(ctx context.Context, in *searchpb.SearchRequest) (*
 	comicsResponse := make([]*searchpb.Comics, 0, len(comics))
 
 	for _, x := range comics {
-		comicsResponse = append(comicsResponse, &searchpb.Comics{Id: int64(x.ID), Url: x.URL})
+		comicsResponse = append(comicsResponse, comicsReply(x))
 	}
 
 	return &searchpb.SearchReply{Comics: comicsResponse}, nil
 }
+
+func comicsReply(comics core.Comics) *searchpb.Comics {
+	reply := &searchpb.Comics{
+		Id:         int64(comics.ID),
+		Url:        comics.URL,
+		Title:      comics.Title,
+		Alt:        comics.Alt,
+		Transcript: comics.Transcript,
+		Link:       comics.Link,
+		News:       comics.News,
+		Source:     comics.Source,
+	}
+	if !comics.Published.IsZero() {
+		reply.Published = timestamppb.New(comics.Published)
+	}
+	return reply
+}
@@ -3,6 +3,7 @@ package grpc
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -58,6 +59,43 @@ func TestDbSearch(t *testing.T) {
 	require.Equal(t, expected, reply)
 }
 
+func TestDbSearch_Metadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	published := time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockSearcher := mock_grpc.NewMockSearcher(ctrl)
+	mockSearcher.EXPECT().
+		DbSearch(gomock.Any(), 1, "barrel").
+		Return([]core.Comics{{
+			ID:         1,
+			URL:        "http://example.com/1",
+			Title:      "Barrel - Part 1",
+			Alt:        "Don't we all.",
+			Transcript: "[[A boy sits in a barrel]]",
+			Link:       "http://example.com",
+			News:       "news",
+			Published:  published,
+			Source:     "xkcd",
+		}}, nil)
+
+	srv := NewServer(mockSearcher)
+
+	reply, err := srv.DbSearch(context.Background(), &searchpb.SearchRequest{Limit: 1, Phrase: "barrel"})
+	require.NoError(t, err)
+	require.Len(t, reply.Comics, 1)
+
+	comics := reply.Comics[0]
+	require.Equal(t, "Barrel - Part 1", comics.Title)
+	require.Equal(t, "Don't we all.", comics.Alt)
+	require.Equal(t, "[[A boy sits in a barrel]]", comics.Transcript)
+	require.Equal(t, "http://example.com", comics.Link)
+	require.Equal(t, "news", comics.News)
+	require.Equal(t, published, comics.Published.AsTime())
+	require.Equal(t, "xkcd", comics.Source)
+}
+
 func TestIndexSearch(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
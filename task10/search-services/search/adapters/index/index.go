@@ -1,26 +1,32 @@
-//go:generate mockgen -package mock_index -destination ./mocks/index.go yadro.com/course/search/core Builder
+//go:generate mockgen -package mock_index -destination ./mocks/index.go yadro.com/course/search/core Builder,Changes
 package index
 
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 
 	"yadro.com/course/search/core"
 )
 
 type Index struct {
-	log        *slog.Logger
-	builder    core.Builder
-	ttl        time.Duration
+	log     *slog.Logger
+	builder core.Builder
+	changes core.Changes
+	ttl     time.Duration
+	// перестройка подменяет карты, пока их читают обработчики запросов
+	mx         sync.RWMutex
 	wordToID   map[string][]int
 	idToComics map[int]core.Comics
 }
 
-func NewIndex(log *slog.Logger, builder core.Builder, ttl time.Duration) (*Index, error) {
+// changes может быть nil, тогда индекс перестраивается только по ttl
+func NewIndex(log *slog.Logger, builder core.Builder, ttl time.Duration, changes core.Changes) (*Index, error) {
 	return &Index{
 		log:        log,
 		builder:    builder,
+		changes:    changes,
 		ttl:        ttl,
 		wordToID:   make(map[string][]int),
 		idToComics: make(map[int]core.Comics),
@@ -30,13 +36,17 @@ func NewIndex(log *slog.Logger, builder core.Builder, ttl time.Duration) (*Index
 func (i *Index) SearchByWord(_ context.Context, word string) ([]int, error) {
 	IDs := make([]int, 0)
 
+	i.mx.RLock()
 	IDs = append(IDs, i.wordToID[word]...)
+	i.mx.RUnlock()
 
 	return IDs, nil
 }
 
 func (i *Index) GetComics(_ context.Context, id int) (core.Comics, error) {
+	i.mx.RLock()
 	comics, ok := i.idToComics[id]
+	i.mx.RUnlock()
 	if ok {
 		return comics, nil
 	}
@@ -48,24 +58,27 @@ func (i *Index) Start(ctx context.Context) {
 	if err != nil {
 		i.log.Error("First builder index initiator failed", "error", err)
 	}
-
-	i.wordToID = wordToID
-	i.idToComics = idToComics
+	i.swap(wordToID, idToComics)
 
 	i.log.Info("Start index initiator")
 
+	// без подписки канал nil и в select никогда не срабатывает
+	var changes <-chan string
+	if i.changes != nil {
+		changes = i.changes.Listen(ctx)
+	}
+
 	ticker := time.NewTicker(i.ttl)
 	go func() {
 		for {
 			select {
 			case <-ticker.C:
-				if wordToID, idToComics, err = i.builder.BuildIndex(ctx); err != nil {
-					i.log.Error("Index build failed", "error", err)
-				} else {
-					i.wordToID = wordToID
-					i.idToComics = idToComics
-					i.log.Info("Index build complete")
-				}
+				i.rebuild(ctx)
+			case reason := <-changes:
+				i.log.Info("Comics changed, rebuilding index", "reason", reason)
+				i.rebuild(ctx)
+				// ttl остаётся страховкой на случай потерянного уведомления
+				ticker.Reset(i.ttl)
 			case <-ctx.Done():
 				i.log.Info("Index initiator stopped")
 				return
@@ -73,3 +86,23 @@ func (i *Index) Start(ctx context.Context) {
 		}
 	}()
 }
+
+func (i *Index) rebuild(ctx context.Context) {
+	wordToID, idToComics, err := i.builder.BuildIndex(ctx)
+	if err != nil {
+		i.log.Error("Index build failed", "error", err)
+		return
+	}
+	i.swap(wordToID, idToComics)
+	i.log.Info("Index build complete")
+}
+
+// swap подменяет обе карты сразу: запрос не увидит слова из нового индекса
+// вместе с комиксами из старого
+func (i *Index) swap(wordToID map[string][]int, idToComics map[int]core.Comics) {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	i.wordToID = wordToID
+	i.idToComics = idToComics
+}
@@ -24,7 +24,7 @@ func TestNewIndex(t *testing.T) {
 
 	mockBuilder := mock_index.NewMockBuilder(ctrl)
 
-	idx, err := NewIndex(logger, mockBuilder, time.Second)
+	idx, err := NewIndex(logger, mockBuilder, time.Second, nil)
 	require.NoError(t, err)
 	require.NotNil(t, idx)
 	require.Empty(t, idx.wordToID)
@@ -36,7 +36,7 @@ func TestSearchByWord(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockBuilder := mock_index.NewMockBuilder(ctrl)
-	idx, _ := NewIndex(logger, mockBuilder, time.Second)
+	idx, _ := NewIndex(logger, mockBuilder, time.Second, nil)
 
 	idx.wordToID = map[string][]int{
 		"hello": {1, 2, 3},
@@ -56,7 +56,7 @@ func TestGetComics(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockBuilder := mock_index.NewMockBuilder(ctrl)
-	idx, _ := NewIndex(logger, mockBuilder, time.Second)
+	idx, _ := NewIndex(logger, mockBuilder, time.Second, nil)
 
 	comic := core.Comics{
 		ID:  10,
@@ -74,3 +74,81 @@ func TestGetComics(t *testing.T) {
 	require.Error(t, err)
 	require.Equal(t, core.ErrNotFound, err)
 }
+
+func TestStart_RebuildsOnChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockBuilder := mock_index.NewMockBuilder(ctrl)
+	mockChanges := mock_index.NewMockChanges(ctrl)
+
+	changes := make(chan string, 1)
+	mockChanges.EXPECT().Listen(gomock.Any()).Return((<-chan string)(changes))
+
+	// ttl заведомо больше теста - перестройка может прийти только по уведомлению
+	idx, err := NewIndex(logger, mockBuilder, time.Hour, mockChanges)
+	require.NoError(t, err)
+
+	mockBuilder.EXPECT().BuildIndex(gomock.Any()).Return(map[string][]int{}, map[int]core.Comics{}, nil)
+	idx.Start(ctx)
+
+	rebuilt := make(chan struct{})
+	mockBuilder.EXPECT().BuildIndex(gomock.Any()).DoAndReturn(
+		func(context.Context) (map[string][]int, map[int]core.Comics, error) {
+			close(rebuilt)
+			return map[string][]int{"barrel": {1}}, map[int]core.Comics{1: {ID: 1}}, nil
+		})
+	changes <- "update"
+
+	select {
+	case <-rebuilt:
+	case <-time.After(time.Second):
+		t.Fatal("index is not rebuilt after change notification")
+	}
+}
+
+func TestRebuild_ConcurrentReads(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockBuilder := mock_index.NewMockBuilder(ctrl)
+	mockChanges := mock_index.NewMockChanges(ctrl)
+	changes := make(chan string)
+	mockChanges.EXPECT().Listen(gomock.Any()).Return((<-chan string)(changes))
+
+	idx, err := NewIndex(logger, mockBuilder, time.Hour, mockChanges)
+	require.NoError(t, err)
+
+	mockBuilder.EXPECT().BuildIndex(gomock.Any()).
+		Return(map[string][]int{"barrel": {1}}, map[int]core.Comics{1: {ID: 1}}, nil).
+		AnyTimes()
+	idx.Start(ctx)
+
+	// с -race ловит гонку между перестройкой и чтением индекса
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 20 {
+			changes <- "update"
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			ids, err := idx.SearchByWord(ctx, "barrel")
+			require.NoError(t, err)
+			require.Equal(t, []int{1}, ids)
+			return
+		default:
+			_, _ = idx.SearchByWord(ctx, "barrel")
+			_, _ = idx.GetComics(ctx, 1)
+		}
+	}
+}
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: yadro.com/course/search/core (interfaces: Builder)
+// Source: yadro.com/course/search/core (interfaces: Builder,Changes)
 //
 // Generated by this command:
 //
-//	mockgen -package mock_index -destination ./mocks/index.go yadro.com/course/search/core Builder
+//	mockgen -package mock_index -destination ./mocks/index.go yadro.com/course/search/core Builder,Changes
 //
 
 // Package mock_index is a generated GoMock package.
@@ -56,3 +56,41 @@ func (mr *MockBuilderMockRecorder) BuildIndex(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildIndex", reflect.TypeOf((*MockBuilder)(nil).BuildIndex), arg0)
 }
+
+// MockChanges is a mock of Changes interface.
+type MockChanges struct {
+	ctrl     *gomock.Controller
+	recorder *MockChangesMockRecorder
+	isgomock struct{}
+}
+
+// MockChangesMockRecorder is the mock recorder for MockChanges.
+type MockChangesMockRecorder struct {
+	mock *MockChanges
+}
+
+// NewMockChanges creates a new mock instance.
+func NewMockChanges(ctrl *gomock.Controller) *MockChanges {
+	mock := &MockChanges{ctrl: ctrl}
+	mock.recorder = &MockChangesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChanges) EXPECT() *MockChangesMockRecorder {
+	return m.recorder
+}
+
+// Listen mocks base method.
+func (m *MockChanges) Listen(arg0 context.Context) <-chan string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listen", arg0)
+	ret0, _ := ret[0].(<-chan string)
+	return ret0
+}
+
+// Listen indicates an expected call of Listen.
+func (mr *MockChangesMockRecorder) Listen(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockChanges)(nil).Listen), arg0)
+}
@@ -11,6 +11,7 @@ type Config struct {
 	Address      string        `yaml:"search_address" env:"SEARCH_ADDRESS" env-default:"localhost:8080"`
 	WordsAddress string        `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:8081"`
 	IndexTTL     time.Duration `yaml:"index_ttl" env:"INDEX_TTL" env-default:"20s"`
+	ListenRetry  time.Duration `yaml:"listen_retry" env:"LISTEN_RETRY" env-default:"5s"`
 	DBAddress    string        `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:5431"`
 }
 
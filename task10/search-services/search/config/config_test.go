@@ -23,4 +23,5 @@ func TestMustLoad(t *testing.T) {
 	require.NotEmpty(t, cfg.DBAddress)
 
 	require.Greater(t, cfg.IndexTTL, int64(0))
+	require.Greater(t, cfg.ListenRetry, int64(0))
 }
@@ -19,22 +19,15 @@ func (i *IndexBuilder) BuildIndex(ctx context.Context) (map[string][]int, map[in
 	wordToID := make(map[string][]int)
 	idToComics := make(map[int]Comics)
 
-	maxID, err := i.fetcher.GetMaxID(ctx)
-	if err != nil {
-		return wordToID, idToComics, fmt.Errorf("couldn't get MaxID, %w", err)
-	}
-
-	for id := 1; id <= maxID; id++ {
-		comics, keywords, err := i.fetcher.FetchComics(ctx, id)
-		if err != nil {
-			i.log.Error("Couldn't fetch comics from DB", "error", err, "id", id)
-			continue
-		}
-
-		idToComics[id] = comics
+	err := i.fetcher.Walk(ctx, func(comics Comics, keywords []string) error {
+		idToComics[comics.ID] = comics
 		for _, word := range keywords {
-			wordToID[word] = append(wordToID[word], id)
+			wordToID[word] = append(wordToID[word], comics.ID)
 		}
+		return nil
+	})
+	if err != nil {
+		return make(map[string][]int), make(map[int]Comics), fmt.Errorf("couldn't fetch comics, %w", err)
 	}
 
 	return wordToID, idToComics, nil
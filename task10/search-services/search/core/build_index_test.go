@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log/slog"
 	"testing"
@@ -12,17 +13,24 @@ import (
 
 var logger = slog.New(slog.NewTextHandler(io.Discard, nil))
 
+// walk подменяет Fetcher.Walk: отдаёт комиксы с ключевыми словами по порядку
+func walk(comics []Comics, keywords [][]string) func(context.Context, func(Comics, []string) error) error {
+	return func(_ context.Context, fn func(Comics, []string) error) error {
+		for i := range comics {
+			if err := fn(comics[i], keywords[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 func TestBuildIndex(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockFetcher := NewMockFetcher(ctrl)
 
-	mockFetcher.
-		EXPECT().
-		GetMaxID(gomock.Any()).
-		Return(3, nil)
-
 	expectedWordToID := map[string][]int{
 		"hell": {1, 3},
 		"word": {1, 2},
@@ -45,29 +53,13 @@ func TestBuildIndex(t *testing.T) {
 		},
 	}
 
-	gomock.InOrder(
-		mockFetcher.
-			EXPECT().
-			FetchComics(gomock.Any(), 1).
-			Return(Comics{
-				ID:  1,
-				URL: "http:xkcd.com/1.img",
-			}, []string{"hell", "word"}, nil),
-		mockFetcher.
-			EXPECT().
-			FetchComics(gomock.Any(), 2).
-			Return(Comics{
-				ID:  2,
-				URL: "http:xkcd.com/2.img",
-			}, []string{"word", "run", "job"}, nil),
-		mockFetcher.
-			EXPECT().
-			FetchComics(gomock.Any(), 3).
-			Return(Comics{
-				ID:  3,
-				URL: "http:xkcd.com/3.img",
-			}, []string{"hell", "job"}, nil),
-	)
+	mockFetcher.
+		EXPECT().
+		Walk(gomock.Any(), gomock.Any()).
+		DoAndReturn(walk(
+			[]Comics{expectedIdToComics[1], expectedIdToComics[2], expectedIdToComics[3]},
+			[][]string{{"hell", "word"}, {"word", "run", "job"}, {"hell", "job"}},
+		))
 
 	builder, err := NewIndexBuilder(logger, mockFetcher)
 	require.NoError(t, err)
@@ -78,3 +70,42 @@ func TestBuildIndex(t *testing.T) {
 	require.Equal(t, expectedWordToID, wordToID)
 	require.Equal(t, expectedIdToComics, idToComic)
 }
+
+func TestBuildIndex_SparseIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// удалённые комиксы и пропуски между источниками просто не приходят
+	mockFetcher := NewMockFetcher(ctrl)
+	mockFetcher.
+		EXPECT().
+		Walk(gomock.Any(), gomock.Any()).
+		DoAndReturn(walk(
+			[]Comics{{ID: 1}, {ID: 1000003}},
+			[][]string{{"word"}, {"word"}},
+		))
+
+	builder, err := NewIndexBuilder(logger, mockFetcher)
+	require.NoError(t, err)
+
+	wordToID, idToComics, err := builder.BuildIndex(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string][]int{"word": {1, 1000003}}, wordToID)
+	require.Equal(t, map[int]Comics{1: {ID: 1}, 1000003: {ID: 1000003}}, idToComics)
+}
+
+func TestBuildIndex_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFetcher := NewMockFetcher(ctrl)
+	mockFetcher.EXPECT().Walk(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+	builder, err := NewIndexBuilder(logger, mockFetcher)
+	require.NoError(t, err)
+
+	wordToID, idToComics, err := builder.BuildIndex(context.Background())
+	require.Error(t, err)
+	require.Empty(t, wordToID)
+	require.Empty(t, idToComics)
+}
@@ -268,35 +268,18 @@ func (m *MockFetcher) EXPECT() *MockFetcherMockRecorder {
 	return m.recorder
 }
 
-// FetchComics mocks base method.
-func (m *MockFetcher) FetchComics(arg0 context.Context, arg1 int) (Comics, []string, error) {
+// Walk mocks base method.
+func (m *MockFetcher) Walk(ctx context.Context, fn func(Comics, []string) error) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchComics", arg0, arg1)
-	ret0, _ := ret[0].(Comics)
-	ret1, _ := ret[1].([]string)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret := m.ctrl.Call(m, "Walk", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// FetchComics indicates an expected call of FetchComics.
-func (mr *MockFetcherMockRecorder) FetchComics(arg0, arg1 any) *gomock.Call {
+// Walk indicates an expected call of Walk.
+func (mr *MockFetcherMockRecorder) Walk(ctx, fn any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchComics", reflect.TypeOf((*MockFetcher)(nil).FetchComics), arg0, arg1)
-}
-
-// GetMaxID mocks base method.
-func (m *MockFetcher) GetMaxID(arg0 context.Context) (int, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMaxID", arg0)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetMaxID indicates an expected call of GetMaxID.
-func (mr *MockFetcherMockRecorder) GetMaxID(arg0 any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxID", reflect.TypeOf((*MockFetcher)(nil).GetMaxID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Walk", reflect.TypeOf((*MockFetcher)(nil).Walk), ctx, fn)
 }
 
 // MockBuilder is a mock of Builder interface.
@@ -339,6 +322,44 @@ func (mr *MockBuilderMockRecorder) BuildIndex(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildIndex", reflect.TypeOf((*MockBuilder)(nil).BuildIndex), arg0)
 }
 
+// MockChanges is a mock of Changes interface.
+type MockChanges struct {
+	ctrl     *gomock.Controller
+	recorder *MockChangesMockRecorder
+	isgomock struct{}
+}
+
+// MockChangesMockRecorder is the mock recorder for MockChanges.
+type MockChangesMockRecorder struct {
+	mock *MockChanges
+}
+
+// NewMockChanges creates a new mock instance.
+func NewMockChanges(ctrl *gomock.Controller) *MockChanges {
+	mock := &MockChanges{ctrl: ctrl}
+	mock.recorder = &MockChangesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChanges) EXPECT() *MockChangesMockRecorder {
+	return m.recorder
+}
+
+// Listen mocks base method.
+func (m *MockChanges) Listen(arg0 context.Context) <-chan string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listen", arg0)
+	ret0, _ := ret[0].(<-chan string)
+	return ret0
+}
+
+// Listen indicates an expected call of Listen.
+func (mr *MockChangesMockRecorder) Listen(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockChanges)(nil).Listen), arg0)
+}
+
 // MockWords is a mock of Words interface.
 type MockWords struct {
 	ctrl     *gomock.Controller
@@ -1,6 +1,15 @@
 package core
 
+import "time"
+
 type Comics struct {
-	ID  int
-	URL string
+	ID         int
+	URL        string
+	Title      string
+	Alt        string
+	Transcript string
+	Link       string
+	News       string
+	Published  time.Time
+	Source     string
 }
@@ -23,15 +23,22 @@ type DB interface {
 	wordSearcher
 }
 
+// Fetcher обходит только существующие комиксы: с ID источников через смещения
+// перебор подряд от 1 до максимального ID почти целиком состоит из пропусков
 type Fetcher interface {
-	GetMaxID(context.Context) (int, error)
-	FetchComics(context.Context, int) (Comics, []string, error)
+	Walk(ctx context.Context, fn func(Comics, []string) error) error
 }
 
 type Builder interface {
 	BuildIndex(context.Context) (map[string][]int, map[int]Comics, error)
 }
 
+// Changes - уведомления update-сервиса об изменении комиксов,
+// в канал приходит причина изменения
+type Changes interface {
+	Listen(context.Context) <-chan string
+}
+
 type Words interface {
 	Norm(context.Context, string) ([]string, error)
 }
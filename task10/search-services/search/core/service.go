@@ -1,4 +1,4 @@
-//go:generate mockgen -source ./ports.go -destination=mocks.go -package=corerequire.Equal(t, secondWordToID, idx.wordToID)
+//go:generate mockgen -source ./ports.go -destination=mocks.go -package=core
 package core
 
 import (
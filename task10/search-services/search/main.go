@@ -60,7 +60,10 @@ func run(log *slog.Logger, cfg config.Config) error {
 	}
 
 	// index adapter
-	index, err := index.NewIndex(log, indexBuilder, cfg.IndexTTL)
+	// уведомления update-сервиса об изменении комиксов
+	changes := db.NewListener(log, cfg.DBAddress, cfg.ListenRetry)
+
+	index, err := index.NewIndex(log, indexBuilder, cfg.IndexTTL, changes)
 	if err != nil {
 		return fmt.Errorf("failed create index: %v", err)
 	}
@@ -0,0 +1,152 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"yadro.com/course/update/core"
+)
+
+// самая длинная строка архива: транскрипты у xkcd бывают большими
+const maxLine = 4 << 20
+
+// Record - строка архива: один комикс в JSON
+type Record struct {
+	ID         int      `json:"id"`
+	URL        string   `json:"url"`
+	Keywords   []string `json:"keywords"`
+	Title      string   `json:"title,omitempty"`
+	Alt        string   `json:"alt,omitempty"`
+	Transcript string   `json:"transcript,omitempty"`
+	Link       string   `json:"link,omitempty"`
+	News       string   `json:"news,omitempty"`
+	Published  string   `json:"published,omitempty"`
+	Hash       string   `json:"hash,omitempty"`
+	Source     string   `json:"source,omitempty"`
+}
+
+func record(comics core.Comics) Record {
+	rec := Record{
+		ID:         comics.ID,
+		URL:        comics.URL,
+		Keywords:   comics.Words,
+		Title:      comics.Title,
+		Alt:        comics.Alt,
+		Transcript: comics.Transcript,
+		Link:       comics.Link,
+		News:       comics.News,
+		Hash:       comics.Hash,
+		Source:     comics.Source,
+	}
+	if !comics.Published.IsZero() {
+		rec.Published = comics.Published.Format(time.DateOnly)
+	}
+	return rec
+}
+
+func (rec Record) comics() (core.Comics, error) {
+	comics := core.Comics{
+		ID:     rec.ID,
+		URL:    rec.URL,
+		Words:  rec.Keywords,
+		Hash:   rec.Hash,
+		Source: rec.Source,
+		Metadata: core.Metadata{
+			Title:      rec.Title,
+			Alt:        rec.Alt,
+			Transcript: rec.Transcript,
+			Link:       rec.Link,
+			News:       rec.News,
+		},
+	}
+	if rec.Published != "" {
+		published, err := time.Parse(time.DateOnly, rec.Published)
+		if err != nil {
+			return core.Comics{}, fmt.Errorf("wrong published date %q", rec.Published)
+		}
+		comics.Published = published
+	}
+	return comics, nil
+}
+
+// Writer пишет комиксы в gzip-сжатый NDJSON
+type Writer struct {
+	gz  *gzip.Writer
+	enc *json.Encoder
+}
+
+func NewWriter(w io.Writer) *Writer {
+	gz := gzip.NewWriter(w)
+	return &Writer{gz: gz, enc: json.NewEncoder(gz)}
+}
+
+func (w *Writer) Write(comics core.Comics) error {
+	if err := w.enc.Encode(record(comics)); err != nil {
+		return fmt.Errorf("write comics %d: %w", comics.ID, err)
+	}
+	return nil
+}
+
+// Close дописывает конец gzip-потока, сам w не закрывается
+func (w *Writer) Close() error {
+	return w.gz.Close()
+}
+
+// Reader читает комиксы из gzip-сжатого NDJSON.
+// Битая строка возвращается как core.ErrBadArguments с её номером,
+// чтение можно продолжить со следующей.
+type Reader struct {
+	gz      *gzip.Reader
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a gzip archive: %v", core.ErrBadArguments, err)
+	}
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64<<10), maxLine)
+
+	return &Reader{gz: gz, scanner: scanner}, nil
+}
+
+// Next возвращает следующий комикс или io.EOF в конце архива
+func (r *Reader) Next() (core.Comics, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := r.scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return core.Comics{}, fmt.Errorf("%w: line %d: %v", core.ErrBadArguments, r.line, err)
+		}
+		comics, err := rec.comics()
+		if err != nil {
+			return core.Comics{}, fmt.Errorf("%w: line %d: %v", core.ErrBadArguments, r.line, err)
+		}
+		return comics, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return core.Comics{}, fmt.Errorf("line %d is longer than %d bytes", r.line+1, maxLine)
+		}
+		return core.Comics{}, fmt.Errorf("read archive: %w", err)
+	}
+	return core.Comics{}, io.EOF
+}
+
+func (r *Reader) Close() error {
+	return r.gz.Close()
+}
@@ -0,0 +1,111 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"yadro.com/course/update/core"
+)
+
+func gzipped(t *testing.T, content string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return &buf
+}
+
+func TestRoundTrip(t *testing.T) {
+	comics := []core.Comics{
+		{
+			ID:     1,
+			URL:    "https://imgs.xkcd.com/comics/barrel_cropped_(1).jpg",
+			Words:  []string{"barrel", "boy"},
+			Hash:   "hash",
+			Source: "xkcd",
+			Metadata: core.Metadata{
+				Title:      "Barrel - Part 1",
+				Alt:        "Don't we all.",
+				Transcript: "[[A boy sits in a barrel]]",
+				Published:  time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{ID: 2, URL: "url", Words: []string{"petit", "trees"}},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, c := range comics {
+		require.NoError(t, w.Write(c))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+	defer r.Close()
+
+	var read []core.Comics
+	for {
+		c, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		read = append(read, c)
+	}
+	require.Equal(t, comics, read)
+}
+
+func TestReader_BadLines(t *testing.T) {
+	r, err := NewReader(gzipped(t, `{"id":1,"url":"a"}`+"\n\n"+
+		`not json`+"\n"+
+		`{"id":3,"url":"c","published":"yesterday"}`+"\n"+
+		`{"id":4,"url":"d"}`))
+	require.NoError(t, err)
+
+	c, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, 1, c.ID)
+
+	// после битой строки чтение продолжается, номер строки учитывает пустые
+	_, err = r.Next()
+	require.ErrorIs(t, err, core.ErrBadArguments)
+	require.ErrorContains(t, err, "line 3")
+
+	_, err = r.Next()
+	require.ErrorIs(t, err, core.ErrBadArguments)
+	require.ErrorContains(t, err, "line 4")
+
+	c, err = r.Next()
+	require.NoError(t, err)
+	require.Equal(t, 4, c.ID)
+
+	_, err = r.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestNewReader_NotGzip(t *testing.T) {
+	_, err := NewReader(bytes.NewBufferString(`{"id":1}`))
+	require.ErrorIs(t, err, core.ErrBadArguments)
+}
+
+func TestReader_Truncated(t *testing.T) {
+	buf := gzipped(t, `{"id":1,"url":"a"}`+"\n"+`{"id":2,"url":"b"}`+"\n")
+	r, err := NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-10]))
+	require.NoError(t, err)
+
+	var last error
+	for last == nil {
+		_, last = r.Next()
+	}
+	require.NotErrorIs(t, last, io.EOF)
+	require.NotErrorIs(t, last, core.ErrBadArguments)
+}
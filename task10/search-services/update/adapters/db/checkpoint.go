@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"yadro.com/course/update/core"
+)
+
+type rangeJSON struct {
+	Source string `json:"source"`
+	From   int    `json:"from"`
+	To     int    `json:"to"`
+}
+
+type checkpointRow struct {
+	ID          int64     `db:"id"`
+	Mode        string    `db:"mode"`
+	Ranges      []byte    `db:"ranges"`
+	NewestFirst bool      `db:"newest_first"`
+	Committed   int       `db:"committed"`
+	StartedAt   time.Time `db:"started_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+// StartCheckpoint сохраняет отметку нового обхода. Прежние незакрытые отметки
+// закрываются: новый обход и так пройдёт всё, что они не успели.
+func (db *DB) StartCheckpoint(ctx context.Context, cp core.Checkpoint) (int64, error) {
+	ranges := make([]rangeJSON, 0, len(cp.Ranges))
+	for _, r := range cp.Ranges {
+		ranges = append(ranges, rangeJSON{Source: r.Source, From: r.From, To: r.To})
+	}
+	data, err := json.Marshal(ranges)
+	if err != nil {
+		return 0, fmt.Errorf("encode checkpoint ranges: %w", err)
+	}
+
+	var id int64
+	err = db.conn.GetContext(ctx, &id, `
+		WITH closed AS (
+			UPDATE update_checkpoints SET finished_at = now() WHERE finished_at IS NULL
+		)
+		INSERT INTO update_checkpoints (mode, ranges, newest_first, committed, started_at, updated_at)
+		VALUES ($1, $2, $3, $4, now(), now())
+		RETURNING id`,
+		cp.Mode, string(data), cp.NewestFirst, cp.Committed)
+	if err != nil {
+		db.log.Error("failed to start update checkpoint", "error", err)
+		return 0, fmt.Errorf("start update checkpoint: %w", err)
+	}
+	return id, nil
+}
+
+func (db *DB) SaveCheckpoint(ctx context.Context, id int64, committed int) error {
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE update_checkpoints SET committed = $2, updated_at = now() WHERE id = $1`, id, committed)
+	if err != nil {
+		db.log.Error("failed to save update checkpoint", "checkpoint", id, "error", err)
+		return fmt.Errorf("save update checkpoint %d: %w", id, err)
+	}
+	return nil
+}
+
+func (db *DB) FinishCheckpoint(ctx context.Context, id int64) error {
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE update_checkpoints SET finished_at = now() WHERE id = $1`, id)
+	if err != nil {
+		db.log.Error("failed to finish update checkpoint", "checkpoint", id, "error", err)
+		return fmt.Errorf("finish update checkpoint %d: %w", id, err)
+	}
+	return nil
+}
+
+// Checkpoint возвращает последнюю незакрытую отметку
+func (db *DB) Checkpoint(ctx context.Context) (core.Checkpoint, error) {
+	var row checkpointRow
+	err := db.conn.GetContext(ctx, &row, `
+		SELECT id, mode, ranges, newest_first, committed, started_at, updated_at
+		FROM update_checkpoints
+		WHERE finished_at IS NULL
+		ORDER BY started_at DESC, id DESC
+		LIMIT 1`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Checkpoint{}, fmt.Errorf("%w: no interrupted update to resume", core.ErrNotFound)
+	}
+	if err != nil {
+		db.log.Error("failed to fetch update checkpoint", "error", err)
+		return core.Checkpoint{}, fmt.Errorf("fetch update checkpoint: %w", err)
+	}
+
+	var ranges []rangeJSON
+	if err := json.Unmarshal(row.Ranges, &ranges); err != nil {
+		return core.Checkpoint{}, fmt.Errorf("decode checkpoint %d ranges: %w", row.ID, err)
+	}
+
+	cp := core.Checkpoint{
+		ID:          row.ID,
+		Mode:        core.RunMode(row.Mode),
+		Ranges:      make([]core.SourceRange, 0, len(ranges)),
+		NewestFirst: row.NewestFirst,
+		Committed:   row.Committed,
+		StartedAt:   row.StartedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	for _, r := range ranges {
+		cp.Ranges = append(cp.Ranges, core.SourceRange{Source: r.Source, From: r.From, To: r.To})
+	}
+	return cp, nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	mock_dbops "yadro.com/course/update/adapters/db/mocks"
+	"yadro.com/course/update/core"
+)
+
+func TestCheckpointStartSaveFinish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mock_dbops.NewMockDBops(ctrl)
+	db := &DB{log: logger, conn: conn}
+
+	conn.EXPECT().GetContext(gomock.Any(), gomock.Any(), gomock.Any(),
+		core.ModeUpdate, `[{"source":"xkcd","from":1,"to":3000}]`, true, 0).
+		DoAndReturn(func(_ context.Context, dest any, _ string, _ ...any) error {
+			*dest.(*int64) = 5
+			return nil
+		})
+	id, err := db.StartCheckpoint(context.Background(), core.Checkpoint{
+		Mode:        core.ModeUpdate,
+		Ranges:      []core.SourceRange{{Source: "xkcd", From: 1, To: 3000}},
+		NewestFirst: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), id)
+
+	conn.EXPECT().ExecContext(gomock.Any(), gomock.Any(), int64(5), 2500).Return(driver.RowsAffected(1), nil)
+	require.NoError(t, db.SaveCheckpoint(context.Background(), 5, 2500))
+
+	conn.EXPECT().ExecContext(gomock.Any(), gomock.Any(), int64(5)).Return(nil, errors.New("db error"))
+	require.EqualError(t, db.FinishCheckpoint(context.Background(), 5), "finish update checkpoint 5: db error")
+}
+
+func TestCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mock_dbops.NewMockDBops(ctrl)
+	db := &DB{log: logger, conn: conn}
+
+	started := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	conn.EXPECT().GetContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, dest any, _ string, _ ...any) error {
+			*dest.(*checkpointRow) = checkpointRow{
+				ID:        5,
+				Mode:      "reconcile",
+				Ranges:    []byte(`[{"source":"xkcd","from":1,"to":3000}]`),
+				Committed: 120,
+				StartedAt: started,
+				UpdatedAt: started.Add(time.Minute),
+			}
+			return nil
+		})
+	cp, err := db.Checkpoint(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, core.Checkpoint{
+		ID:        5,
+		Mode:      core.ModeReconcile,
+		Ranges:    []core.SourceRange{{Source: "xkcd", From: 1, To: 3000}},
+		Committed: 120,
+		StartedAt: started,
+		UpdatedAt: started.Add(time.Minute),
+	}, cp)
+
+	// незакрытых отметок нет
+	conn.EXPECT().GetContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(sql.ErrNoRows)
+	_, err = db.Checkpoint(context.Background())
+	require.ErrorIs(t, err, core.ErrNotFound)
+}
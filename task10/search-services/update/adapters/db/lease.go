@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"yadro.com/course/update/core"
+)
+
+// строка аренды одна на весь кластер
+const leaseName = "update"
+
+// Lease - аренда запуска обновления в таблице update_lease.
+// В отличие от advisory lock не привязана к соединению пула,
+// а держатель и срок видны всем репликам.
+type Lease struct {
+	log     *slog.Logger
+	conn    DBops
+	replica string
+}
+
+func NewLease(db *DB, replica string) *Lease {
+	return &Lease{
+		log:     db.log,
+		conn:    db.conn,
+		replica: replica,
+	}
+}
+
+type leaseRow struct {
+	Replica    string    `db:"replica"`
+	AcquiredAt time.Time `db:"acquired_at"`
+	ExpiresAt  time.Time `db:"expires_at"`
+}
+
+// Acquire забирает аренду, если она свободна, истекла или осталась от этой же
+// реплики: после падения и быстрого перезапуска реплика не ждёт TTL своей
+// же аренды и может продолжить прерванное обновление. Внутри процесса второй
+// захват не даёт мьютекс сервиса, поэтому имена живых реплик должны различаться.
+func (l *Lease) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	res, err := l.conn.ExecContext(ctx, `
+		INSERT INTO update_lease (name, replica, acquired_at, expires_at)
+		VALUES ($1, $2, now(), now() + $3 * interval '1 millisecond')
+		ON CONFLICT (name) DO UPDATE
+		SET replica = EXCLUDED.replica, acquired_at = EXCLUDED.acquired_at, expires_at = EXCLUDED.expires_at
+		WHERE update_lease.expires_at < now() OR update_lease.replica = EXCLUDED.replica`,
+		leaseName, l.replica, ttl.Milliseconds())
+	if err != nil {
+		l.log.Error("failed to acquire update lease", "replica", l.replica, "error", err)
+		return false, fmt.Errorf("acquire update lease: %w", err)
+	}
+	return leased(res)
+}
+
+// Renew продлевает аренду, только пока она принадлежит этой реплике
+func (l *Lease) Renew(ctx context.Context, ttl time.Duration) (bool, error) {
+	res, err := l.conn.ExecContext(ctx, `
+		UPDATE update_lease SET expires_at = now() + $3 * interval '1 millisecond'
+		WHERE name = $1 AND replica = $2`,
+		leaseName, l.replica, ttl.Milliseconds())
+	if err != nil {
+		l.log.Error("failed to renew update lease", "replica", l.replica, "error", err)
+		return false, fmt.Errorf("renew update lease: %w", err)
+	}
+	return leased(res)
+}
+
+func (l *Lease) Release(ctx context.Context) error {
+	_, err := l.conn.ExecContext(ctx, `DELETE FROM update_lease WHERE name = $1 AND replica = $2`,
+		leaseName, l.replica)
+	if err != nil {
+		l.log.Error("failed to release update lease", "replica", l.replica, "error", err)
+		return fmt.Errorf("release update lease: %w", err)
+	}
+	return nil
+}
+
+// Holder возвращает держателя действующей аренды, истёкшая считается свободной
+func (l *Lease) Holder(ctx context.Context) (core.LeaseHolder, error) {
+	var row leaseRow
+	err := l.conn.GetContext(ctx, &row, `
+		SELECT replica, acquired_at, expires_at FROM update_lease
+		WHERE name = $1 AND expires_at > now()`, leaseName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.LeaseHolder{}, nil
+	}
+	if err != nil {
+		l.log.Error("failed to fetch update lease", "error", err)
+		return core.LeaseHolder{}, fmt.Errorf("fetch update lease: %w", err)
+	}
+	return core.LeaseHolder{
+		Replica:    row.Replica,
+		AcquiredAt: row.AcquiredAt,
+		ExpiresAt:  row.ExpiresAt,
+	}, nil
+}
+
+func leased(res sql.Result) (bool, error) {
+	n, err := rowsAffected(res)
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	mock_dbops "yadro.com/course/update/adapters/db/mocks"
+	"yadro.com/course/update/core"
+)
+
+func newLease(t *testing.T) (*Lease, *mock_dbops.MockDBops) {
+	ctrl := gomock.NewController(t)
+	conn := mock_dbops.NewMockDBops(ctrl)
+	return NewLease(&DB{log: logger, conn: conn}, "update-1"), conn
+}
+
+func TestLeaseAcquire(t *testing.T) {
+	lease, conn := newLease(t)
+
+	conn.EXPECT().ExecContext(gomock.Any(), gomock.Any(), leaseName, "update-1", int64(30000)).
+		DoAndReturn(func(_ context.Context, query string, _ ...any) (sql.Result, error) {
+			// свою неистёкшую аренду перезапущенная реплика забирает сразу
+			require.Contains(t, query, "WHERE update_lease.expires_at < now() OR update_lease.replica = EXCLUDED.replica")
+			return driver.RowsAffected(1), nil
+		})
+	ok, err := lease.Acquire(context.Background(), 30*time.Second)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// аренда у другой реплики и ещё не истекла
+	conn.EXPECT().ExecContext(gomock.Any(), gomock.Any(), leaseName, "update-1", int64(30000)).
+		Return(driver.RowsAffected(0), nil)
+	ok, err = lease.Acquire(context.Background(), 30*time.Second)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	conn.EXPECT().ExecContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("db error"))
+	_, err = lease.Acquire(context.Background(), 30*time.Second)
+	require.EqualError(t, err, "acquire update lease: db error")
+}
+
+func TestLeaseRenew(t *testing.T) {
+	lease, conn := newLease(t)
+
+	conn.EXPECT().ExecContext(gomock.Any(), gomock.Any(), leaseName, "update-1", int64(10000)).
+		Return(driver.RowsAffected(1), nil)
+	ok, err := lease.Renew(context.Background(), 10*time.Second)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// аренду перехватили
+	conn.EXPECT().ExecContext(gomock.Any(), gomock.Any(), leaseName, "update-1", int64(10000)).
+		Return(driver.RowsAffected(0), nil)
+	ok, err = lease.Renew(context.Background(), 10*time.Second)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestLeaseRelease(t *testing.T) {
+	lease, conn := newLease(t)
+
+	conn.EXPECT().ExecContext(gomock.Any(), gomock.Any(), leaseName, "update-1").
+		Return(driver.RowsAffected(1), nil)
+	require.NoError(t, lease.Release(context.Background()))
+}
+
+func TestLeaseHolder(t *testing.T) {
+	lease, conn := newLease(t)
+
+	acquired := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	conn.EXPECT().GetContext(gomock.Any(), gomock.Any(), gomock.Any(), leaseName).
+		DoAndReturn(func(_ context.Context, dest any, _ string, _ ...any) error {
+			*dest.(*leaseRow) = leaseRow{Replica: "update-2", AcquiredAt: acquired, ExpiresAt: acquired.Add(time.Minute)}
+			return nil
+		})
+	holder, err := lease.Holder(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, core.LeaseHolder{
+		Replica:    "update-2",
+		AcquiredAt: acquired,
+		ExpiresAt:  acquired.Add(time.Minute),
+	}, holder)
+
+	// аренды нет или она истекла
+	conn.EXPECT().GetContext(gomock.Any(), gomock.Any(), gomock.Any(), leaseName).Return(sql.ErrNoRows)
+	holder, err = lease.Holder(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, holder.Replica)
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS comics_failures;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE comics_failures (
+    comics_id INTEGER PRIMARY KEY,
+    stage TEXT NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    failed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS comics_tombstones;
\ No newline at end of file
@@ -0,0 +1,4 @@
+CREATE TABLE comics_tombstones (
+    comics_id INTEGER PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
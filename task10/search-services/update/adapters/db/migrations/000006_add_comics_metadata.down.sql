@@ -0,0 +1,7 @@
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS alt,
+    DROP COLUMN IF EXISTS transcript,
+    DROP COLUMN IF EXISTS link,
+    DROP COLUMN IF EXISTS news,
+    DROP COLUMN IF EXISTS published;
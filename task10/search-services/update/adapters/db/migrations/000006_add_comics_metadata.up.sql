@@ -0,0 +1,7 @@
+ALTER TABLE comics
+    ADD COLUMN title TEXT NOT NULL DEFAULT '',
+    ADD COLUMN alt TEXT NOT NULL DEFAULT '',
+    ADD COLUMN transcript TEXT NOT NULL DEFAULT '',
+    ADD COLUMN link TEXT NOT NULL DEFAULT '',
+    ADD COLUMN news TEXT NOT NULL DEFAULT '',
+    ADD COLUMN published DATE;
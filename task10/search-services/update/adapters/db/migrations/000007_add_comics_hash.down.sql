@@ -0,0 +1,2 @@
+ALTER TABLE comics
+    DROP COLUMN IF EXISTS hash;
@@ -0,0 +1,2 @@
+ALTER TABLE comics
+    ADD COLUMN hash TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE comics
+    ADD COLUMN source TEXT NOT NULL DEFAULT 'xkcd';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS snapshot_comics;
+DROP TABLE IF EXISTS snapshots;
@@ -0,0 +1,21 @@
+CREATE TABLE snapshots (
+    name TEXT PRIMARY KEY,
+    comics INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE snapshot_comics (
+    snapshot TEXT NOT NULL REFERENCES snapshots (name) ON DELETE CASCADE,
+    comics_id INTEGER NOT NULL,
+    img_url TEXT NOT NULL,
+    keywords TEXT[],
+    title TEXT NOT NULL DEFAULT '',
+    alt TEXT NOT NULL DEFAULT '',
+    transcript TEXT NOT NULL DEFAULT '',
+    link TEXT NOT NULL DEFAULT '',
+    news TEXT NOT NULL DEFAULT '',
+    published DATE,
+    hash TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT 'xkcd',
+    PRIMARY KEY (snapshot, comics_id)
+);
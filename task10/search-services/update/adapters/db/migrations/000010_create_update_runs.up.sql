@@ -0,0 +1,14 @@
+CREATE TABLE update_runs (
+    id BIGSERIAL PRIMARY KEY,
+    mode TEXT NOT NULL,
+    triggered_by TEXT NOT NULL,
+    status TEXT NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    stored INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX update_runs_started_at_idx ON update_runs (started_at DESC);
@@ -0,0 +1,6 @@
+CREATE TABLE update_lease (
+    name TEXT PRIMARY KEY,
+    replica TEXT NOT NULL,
+    acquired_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,13 @@
+CREATE TABLE update_checkpoints (
+    id BIGSERIAL PRIMARY KEY,
+    mode TEXT NOT NULL,
+    ranges JSONB NOT NULL,
+    newest_first BOOLEAN NOT NULL DEFAULT FALSE,
+    committed INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ
+);
+
+-- незакрытая отметка - прерванный обход, его продолжает Resume
+CREATE INDEX update_checkpoints_open_idx ON update_checkpoints (started_at DESC) WHERE finished_at IS NULL;
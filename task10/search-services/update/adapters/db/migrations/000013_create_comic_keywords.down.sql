@@ -0,0 +1,6 @@
+CREATE INDEX IF NOT EXISTS idx_comics_keywords ON comics USING GIN(keywords);
+
+DROP TRIGGER IF EXISTS comics_sync_keywords ON comics;
+DROP FUNCTION IF EXISTS sync_comic_keywords();
+DROP FUNCTION IF EXISTS comic_keywords_rows(INTEGER, TEXT[], TEXT, TEXT, TEXT);
+DROP TABLE IF EXISTS comic_keywords;
@@ -0,0 +1,55 @@
+-- ключевые слова комикса построчно: поиск по слову и статистика идут по
+-- индексу, а не разворачивают массивы всей таблицы
+CREATE TABLE comic_keywords (
+    comic_id INTEGER NOT NULL REFERENCES comics (comics_id) ON DELETE CASCADE,
+    keyword TEXT NOT NULL,
+    -- из какого текста комикса слово: title, alt, transcript; other - слово
+    -- есть в comics.keywords, но ни в одном из полей не нашлось (safe_title)
+    field TEXT NOT NULL,
+    -- сколько раз слово встречается в поле
+    frequency INTEGER NOT NULL DEFAULT 1,
+    PRIMARY KEY (comic_id, keyword, field)
+);
+
+CREATE INDEX idx_comic_keywords_keyword ON comic_keywords (keyword, comic_id);
+
+-- comics.keywords остаётся источником правды: в таблицу попадают только его
+-- слова. Поля разбираются english-стеммером Postgres - тот же Porter2, что и
+-- в сервисе words, поэтому основы совпадают
+CREATE FUNCTION comic_keywords_rows(id INTEGER, keywords TEXT[], title TEXT, alt TEXT, transcript TEXT)
+RETURNS TABLE (comic_id INTEGER, keyword TEXT, field TEXT, frequency INTEGER) AS $$
+    WITH found AS (
+        SELECT f.field, t.lexeme AS keyword, coalesce(array_length(t.positions, 1), 1) AS frequency
+        FROM (VALUES ('title', title), ('alt', alt), ('transcript', transcript)) AS f (field, body),
+            unnest(to_tsvector('english', coalesce(f.body, ''))) AS t
+        WHERE t.lexeme = ANY (keywords)
+    )
+    SELECT id, keyword, field, frequency FROM found
+    UNION ALL
+    SELECT DISTINCT id, k, 'other', 1
+    FROM unnest(keywords) AS k
+    WHERE k <> '' AND k NOT IN (SELECT keyword FROM found);
+$$ LANGUAGE sql STABLE;
+
+-- любая запись в comics (обновление, импорт, восстановление снимка)
+-- пересобирает слова комикса, удаление - каскадом
+CREATE FUNCTION sync_comic_keywords() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM comic_keywords WHERE comic_id = NEW.comics_id;
+    INSERT INTO comic_keywords (comic_id, keyword, field, frequency)
+    SELECT * FROM comic_keywords_rows(NEW.comics_id, NEW.keywords, NEW.title, NEW.alt, NEW.transcript);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER comics_sync_keywords
+    AFTER INSERT OR UPDATE OF keywords, title, alt, transcript ON comics
+    FOR EACH ROW EXECUTE FUNCTION sync_comic_keywords();
+
+INSERT INTO comic_keywords (comic_id, keyword, field, frequency)
+SELECT r.*
+FROM comics AS c,
+    comic_keywords_rows(c.comics_id, c.keywords, c.title, c.alt, c.transcript) AS r;
+
+-- поиск по массиву больше не идёт
+DROP INDEX IF EXISTS idx_comics_keywords;
@@ -0,0 +1,20 @@
+package db
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations_Paired(t *testing.T) {
+	ups, err := fs.Glob(migrationFiles, "migrations/*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, ups)
+
+	for _, up := range ups {
+		_, err := fs.Stat(migrationFiles, strings.TrimSuffix(up, ".up.sql")+".down.sql")
+		require.NoError(t, err, "no down migration for %s", up)
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgtype"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
 	"yadro.com/course/update/core"
@@ -30,6 +33,14 @@ type Stats struct {
 	ComicsFetched int `db:"comics_fetched"`
 }
 
+type Failure struct {
+	ID       int       `db:"comics_id"`
+	Stage    string    `db:"stage"`
+	Error    string    `db:"error"`
+	Attempts int       `db:"attempts"`
+	FailedAt time.Time `db:"failed_at"`
+}
+
 func New(log *slog.Logger, address string) (*DB, error) {
 
 	db, err := sqlx.Connect("pgx", address)
@@ -46,24 +57,237 @@ func New(log *slog.Logger, address string) (*DB, error) {
 	}, nil
 }
 
-func (db *DB) Add(ctx context.Context, comics core.Comics) error {
-	_, err := db.conn.ExecContext(ctx,
-		`INSERT INTO comics (comics_id, img_url, keywords) VALUES($1, $2, $3)`,
-		comics.ID, comics.URL, comics.Words)
+// ограничение на число строк в одном INSERT: у postgres не больше 65535 параметров на запрос
+const maxBatchRows = 1000
+
+// AddBatch сохраняет комиксы многострочным INSERT.
+// Уже записанный комикс перезаписывается, только если изменился его хэш.
+// comic_keywords пересобирает триггер на comics.
+func (db *DB) AddBatch(ctx context.Context, batch []core.Comics) error {
+	return db.storeBatch(ctx, batch, false)
+}
+
+// ImportBatch перезаписывает комиксы безусловно: запись архива заменяет
+// сохранённую, даже если хэш совпадает или пуст
+func (db *DB) ImportBatch(ctx context.Context, batch []core.Comics) error {
+	return db.storeBatch(ctx, batch, true)
+}
+
+func (db *DB) storeBatch(ctx context.Context, batch []core.Comics, overwrite bool) error {
+	for len(batch) > 0 {
+		n := min(len(batch), maxBatchRows)
+		if err := db.addBatch(ctx, batch[:n], overwrite); err != nil {
+			return err
+		}
+		batch = batch[n:]
+	}
 
-	return err
+	return nil
+}
+
+func (db *DB) addBatch(ctx context.Context, batch []core.Comics, overwrite bool) error {
+	const columns = 11
+
+	var values strings.Builder
+	args := make([]any, 0, len(batch)*columns+1)
+	ids := make([]int, 0, len(batch))
+	for i, comics := range batch {
+		if i > 0 {
+			values.WriteString(", ")
+		}
+		n := i * columns
+		fmt.Fprintf(&values, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11)
+		args = append(args, comics.ID, comics.URL, comics.Words,
+			comics.Title, comics.Alt, comics.Transcript, comics.Link, comics.News, published(comics.Published),
+			comics.Hash, comics.Source)
+		ids = append(ids, comics.ID)
+	}
+	args = append(args, ids)
+
+	changed := "WHERE comics.hash <> EXCLUDED.hash"
+	if overwrite {
+		changed = ""
+	}
+
+	// сохранённые комиксы больше не числятся в журнале ошибок
+	query := fmt.Sprintf(`
+	WITH inserted AS (
+		INSERT INTO comics (comics_id, img_url, keywords, title, alt, transcript, link, news, published, hash, source)
+		VALUES %s
+		ON CONFLICT (comics_id) DO UPDATE SET
+			img_url = EXCLUDED.img_url,
+			keywords = EXCLUDED.keywords,
+			title = EXCLUDED.title,
+			alt = EXCLUDED.alt,
+			transcript = EXCLUDED.transcript,
+			link = EXCLUDED.link,
+			news = EXCLUDED.news,
+			published = EXCLUDED.published,
+			hash = EXCLUDED.hash,
+			source = EXCLUDED.source
+		%s
+	)
+	DELETE FROM comics_failures WHERE comics_id = ANY($%d)`, values.String(), changed, len(args))
+
+	if _, err := db.conn.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("store batch of %d comics: %w", len(batch), err)
+	}
+
+	return nil
+}
+
+// выгрузка идёт страницами по ID, чтобы не держать всю таблицу в памяти
+const walkPageSize = 1000
+
+type comicsRow struct {
+	ID         int              `db:"comics_id"`
+	URL        string           `db:"img_url"`
+	Keywords   pgtype.TextArray `db:"keywords"`
+	Title      string           `db:"title"`
+	Alt        string           `db:"alt"`
+	Transcript string           `db:"transcript"`
+	Link       string           `db:"link"`
+	News       string           `db:"news"`
+	Published  sql.NullTime     `db:"published"`
+	Hash       string           `db:"hash"`
+	Source     string           `db:"source"`
+}
+
+func (row comicsRow) comics() (core.Comics, error) {
+	var keywords []string
+	if err := row.Keywords.AssignTo(&keywords); err != nil {
+		return core.Comics{}, fmt.Errorf("convert keywords of comics %d: %w", row.ID, err)
+	}
+
+	return core.Comics{
+		ID:     row.ID,
+		URL:    row.URL,
+		Words:  keywords,
+		Hash:   row.Hash,
+		Source: row.Source,
+		Metadata: core.Metadata{
+			Title:      row.Title,
+			Alt:        row.Alt,
+			Transcript: row.Transcript,
+			Link:       row.Link,
+			News:       row.News,
+			Published:  row.Published.Time,
+		},
+	}, nil
+}
+
+// Walk передаёт fn все комиксы по возрастанию ID
+func (db *DB) Walk(ctx context.Context, fn func(core.Comics) error) error {
+	lastID := 0
+	for {
+		var rows []comicsRow
+		err := db.conn.SelectContext(ctx, &rows, `
+		SELECT comics_id, img_url, keywords, title, alt, transcript, link, news, published, hash, source
+		FROM comics
+		WHERE comics_id > $1
+		ORDER BY comics_id
+		LIMIT $2`, lastID, walkPageSize)
+		if err != nil {
+			db.log.Error("failed to fetch comics page", "after", lastID, "error", err)
+			return fmt.Errorf("fetch comics after %d: %w", lastID, err)
+		}
+
+		for _, row := range rows {
+			comics, err := row.comics()
+			if err != nil {
+				return err
+			}
+			if err := fn(comics); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < walkPageSize {
+			return nil
+		}
+		lastID = rows[len(rows)-1].ID
+	}
+}
+
+func published(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func (db *DB) AddFailure(ctx context.Context, failure core.Failure) error {
+	_, err := db.conn.ExecContext(ctx, `
+	INSERT INTO comics_failures (comics_id, stage, error) VALUES($1, $2, $3)
+	ON CONFLICT (comics_id) DO UPDATE SET
+		stage = EXCLUDED.stage,
+		error = EXCLUDED.error,
+		attempts = comics_failures.attempts + 1,
+		failed_at = now()`,
+		failure.ID, string(failure.Stage), failure.Error)
+	if err != nil {
+		return fmt.Errorf("record failure of comics %d: %w", failure.ID, err)
+	}
+
+	return nil
+}
+
+func (db *DB) AddTombstone(ctx context.Context, id int) error {
+	// у несуществующего комикса не может быть открытых ошибок
+	_, err := db.conn.ExecContext(ctx, `
+	WITH inserted AS (
+		INSERT INTO comics_tombstones (comics_id) VALUES($1) ON CONFLICT DO NOTHING
+	)
+	DELETE FROM comics_failures WHERE comics_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("record tombstone of comics %d: %w", id, err)
+	}
+
+	return nil
+}
+
+func (db *DB) Tombstones(ctx context.Context) ([]int, error) {
+	var ids []int
+
+	err := db.conn.SelectContext(ctx, &ids, `SELECT comics_id FROM comics_tombstones`)
+	if err != nil {
+		db.log.Error("failed to fetch tombstones", "error", err)
+		return nil, fmt.Errorf("fetch tombstones: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (db *DB) Failures(ctx context.Context) ([]core.Failure, error) {
+	var rows []Failure
+
+	err := db.conn.SelectContext(ctx, &rows,
+		`SELECT comics_id, stage, error, attempts, failed_at FROM comics_failures ORDER BY comics_id`)
+	if err != nil {
+		db.log.Error("failed to fetch comics failures", "error", err)
+		return nil, fmt.Errorf("fetch comics failures: %w", err)
+	}
+
+	failures := make([]core.Failure, 0, len(rows))
+	for _, row := range rows {
+		failures = append(failures, core.Failure{
+			ID:       row.ID,
+			Stage:    core.FailureStage(row.Stage),
+			Error:    row.Error,
+			Attempts: row.Attempts,
+			FailedAt: row.FailedAt,
+		})
+	}
+
+	return failures, nil
 }
 
 func (db *DB) Stats(ctx context.Context) (core.DBStats, error) {
+	// слово считается один раз на комикс, в скольких бы полях оно ни встретилось
 	query := `
-	SELECT 
-		COUNT(*) AS words_total,
+	SELECT
+		COUNT(DISTINCT (comic_id, keyword)) AS words_total,
 		COUNT(DISTINCT keyword) AS words_unique,
 		(SELECT COUNT(*) FROM comics) AS comics_fetched
-	FROM (
-		SELECT unnest(keywords) AS keyword
-		FROM comics
-	) AS subquery;
+	FROM comic_keywords
 	`
 	var stats Stats
 	err := db.conn.GetContext(ctx, &stats, query)
@@ -87,7 +311,292 @@ func (db *DB) IDs(ctx context.Context) ([]int, error) {
 	return ids, nil
 }
 
-func (db *DB) Drop(ctx context.Context) error {
-	_, err := db.conn.ExecContext(ctx, `DELETE FROM comics`)
-	return err
+func (db *DB) MissingIDs(ctx context.Context, from, to int) ([]int, error) {
+	// from - 1 - опорная точка, чтобы найти пропуск в начале диапазона;
+	// у последнего известного ID "следующим" считается to + 1
+	query := `
+	WITH known AS (
+		SELECT $1::int - 1 AS comics_id
+		UNION
+		SELECT comics_id FROM comics WHERE comics_id BETWEEN $1 AND $2
+		UNION
+		SELECT comics_id FROM comics_tombstones WHERE comics_id BETWEEN $1 AND $2
+	), gaps AS (
+		SELECT
+			comics_id + 1 AS gap_start,
+			LEAD(comics_id, 1, $2::int + 1) OVER (ORDER BY comics_id) - 1 AS gap_end
+		FROM known
+	)
+	SELECT generate_series(gap_start, gap_end) AS comics_id
+	FROM gaps
+	WHERE gap_start <= gap_end
+	ORDER BY comics_id
+	`
+
+	var ids []int
+	if err := db.conn.SelectContext(ctx, &ids, query, from, to); err != nil {
+		db.log.Error("failed to fetch missing comics IDs", "error", err)
+		return nil, fmt.Errorf("fetch missing comics IDs: %w", err)
+	}
+
+	return ids, nil
+}
+
+type Hash struct {
+	ID   int    `db:"comics_id"`
+	Hash string `db:"hash"`
+}
+
+// Hashes возвращает хэши сохранённых комиксов в диапазоне from..to, 0 - без границы.
+// У комиксов, сохранённых до появления хэшей, он пустой и считается изменившимся.
+func (db *DB) Hashes(ctx context.Context, from, to int) (map[int]string, error) {
+	var rows []Hash
+
+	err := db.conn.SelectContext(ctx, &rows, `
+	SELECT comics_id, hash FROM comics
+	WHERE comics_id >= $1 AND ($2 = 0 OR comics_id <= $2)`, from, to)
+	if err != nil {
+		db.log.Error("failed to fetch comics hashes", "error", err)
+		return nil, fmt.Errorf("fetch comics hashes: %w", err)
+	}
+
+	hashes := make(map[int]string, len(rows))
+	for _, row := range rows {
+		hashes[row.ID] = row.Hash
+	}
+
+	return hashes, nil
+}
+
+type runRow struct {
+	ID         int64     `db:"id"`
+	Mode       string    `db:"mode"`
+	Trigger    string    `db:"triggered_by"`
+	Status     string    `db:"status"`
+	StartedAt  time.Time `db:"started_at"`
+	FinishedAt time.Time `db:"finished_at"`
+	Stored     int       `db:"stored"`
+	Failed     int       `db:"failed"`
+	Skipped    int       `db:"skipped"`
+	Error      string    `db:"error"`
+}
+
+func (db *DB) AddRun(ctx context.Context, run core.UpdateRun) error {
+	_, err := db.conn.ExecContext(ctx, `
+		INSERT INTO update_runs (mode, triggered_by, status, started_at, finished_at, stored, failed, skipped, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		run.Mode, run.Trigger, run.Status, run.StartedAt, run.FinishedAt,
+		run.Stored, run.Failed, run.Skipped, run.Error)
+	if err != nil {
+		return fmt.Errorf("add update run: %w", err)
+	}
+	return nil
+}
+
+// Runs возвращает страницу журнала запусков от новых к старым и общее число записей
+func (db *DB) Runs(ctx context.Context, limit, offset int) ([]core.UpdateRun, int, error) {
+	var total int
+	if err := db.conn.GetContext(ctx, &total, `SELECT count(*) FROM update_runs`); err != nil {
+		db.log.Error("failed to count update runs", "error", err)
+		return nil, 0, fmt.Errorf("count update runs: %w", err)
+	}
+
+	var rows []runRow
+	err := db.conn.SelectContext(ctx, &rows, `
+		SELECT id, mode, triggered_by, status, started_at, finished_at, stored, failed, skipped, error
+		FROM update_runs
+		ORDER BY started_at DESC, id DESC
+		LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		db.log.Error("failed to fetch update runs", "error", err)
+		return nil, 0, fmt.Errorf("fetch update runs: %w", err)
+	}
+
+	runs := make([]core.UpdateRun, 0, len(rows))
+	for _, row := range rows {
+		runs = append(runs, core.UpdateRun{
+			ID:         row.ID,
+			Mode:       core.RunMode(row.Mode),
+			Trigger:    core.RunTrigger(row.Trigger),
+			Status:     core.RunStatus(row.Status),
+			StartedAt:  row.StartedAt,
+			FinishedAt: row.FinishedAt,
+			Stored:     row.Stored,
+			Failed:     row.Failed,
+			Skipped:    row.Skipped,
+			Error:      row.Error,
+		})
+	}
+	return runs, total, nil
+}
+
+// DeleteIDs удаляет комиксы по списку ID и возвращает число удалённых
+func (db *DB) DeleteIDs(ctx context.Context, ids []int) (int, error) {
+	res, err := db.conn.ExecContext(ctx, `DELETE FROM comics WHERE comics_id = ANY($1)`, ids)
+	if err != nil {
+		db.log.Error("failed to delete comics", "ids", ids, "error", err)
+		return 0, fmt.Errorf("delete comics: %w", err)
+	}
+	return rowsAffected(res)
+}
+
+// DeleteRange удаляет комиксы с ID от from до to включительно
+func (db *DB) DeleteRange(ctx context.Context, from, to int) (int, error) {
+	res, err := db.conn.ExecContext(ctx, `DELETE FROM comics WHERE comics_id BETWEEN $1 AND $2`, from, to)
+	if err != nil {
+		db.log.Error("failed to delete comics", "from", from, "to", to, "error", err)
+		return 0, fmt.Errorf("delete comics: %w", err)
+	}
+	return rowsAffected(res)
+}
+
+func rowsAffected(res sql.Result) (int, error) {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+	return int(n), nil
+}
+
+// comicsColumns - столбцы комикса, общие для comics и snapshot_comics
+const comicsColumns = `comics_id, img_url, keywords, title, alt, transcript, link, news, published, hash, source`
+
+type snapshotRow struct {
+	Name      string    `db:"name"`
+	Comics    int       `db:"comics"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (row snapshotRow) snapshot() core.Snapshot {
+	return core.Snapshot{Name: row.Name, Comics: row.Comics, CreatedAt: row.CreatedAt}
+}
+
+// Drop удаляет все комиксы. С непустым snapshot они предварительно копируются
+// в снимок с этим именем; пустую таблицу не сохраняем, чтобы не вытеснить
+// полезные снимки. Всё делается одним запросом, поэтому снимок и удаление атомарны.
+func (db *DB) Drop(ctx context.Context, snapshot string) (core.Snapshot, error) {
+	if snapshot == "" {
+		_, err := db.conn.ExecContext(ctx, `DELETE FROM comics`)
+		return core.Snapshot{}, err
+	}
+
+	var rows []snapshotRow
+	err := db.conn.SelectContext(ctx, &rows, `
+		WITH snapshot AS (
+			INSERT INTO snapshots (name, comics)
+			SELECT $1, count(*) FROM comics HAVING count(*) > 0
+			RETURNING name, comics, created_at
+		), copied AS (
+			INSERT INTO snapshot_comics (snapshot, `+comicsColumns+`)
+			SELECT snapshot.name, `+comicsColumns+` FROM comics CROSS JOIN snapshot
+		), deleted AS (
+			DELETE FROM comics
+		)
+		SELECT name, comics, created_at FROM snapshot`, snapshot)
+	if err != nil {
+		db.log.Error("failed to drop comics", "snapshot", snapshot, "error", err)
+		return core.Snapshot{}, fmt.Errorf("drop comics: %w", err)
+	}
+	if len(rows) == 0 {
+		return core.Snapshot{}, nil
+	}
+	return rows[0].snapshot(), nil
+}
+
+// Snapshots возвращает снимки, начиная с самого нового
+func (db *DB) Snapshots(ctx context.Context) ([]core.Snapshot, error) {
+	var rows []snapshotRow
+	err := db.conn.SelectContext(ctx, &rows,
+		`SELECT name, comics, created_at FROM snapshots ORDER BY created_at DESC, name DESC`)
+	if err != nil {
+		db.log.Error("failed to fetch snapshots", "error", err)
+		return nil, fmt.Errorf("fetch snapshots: %w", err)
+	}
+
+	snapshots := make([]core.Snapshot, 0, len(rows))
+	for _, row := range rows {
+		snapshots = append(snapshots, row.snapshot())
+	}
+	return snapshots, nil
+}
+
+// Restore заменяет содержимое comics снимком. Удаляются только комиксы,
+// которых нет в снимке, остальные перезаписываются, так что DELETE и INSERT
+// одного запроса не затрагивают одни и те же строки.
+func (db *DB) Restore(ctx context.Context, name string) error {
+	res, err := db.conn.ExecContext(ctx, `
+		WITH deleted AS (
+			DELETE FROM comics
+			WHERE EXISTS (SELECT 1 FROM snapshots WHERE name = $1)
+				AND comics_id NOT IN (SELECT comics_id FROM snapshot_comics WHERE snapshot = $1)
+		)
+		INSERT INTO comics (`+comicsColumns+`)
+		SELECT `+comicsColumns+` FROM snapshot_comics WHERE snapshot = $1
+		ON CONFLICT (comics_id) DO UPDATE SET
+			img_url = EXCLUDED.img_url,
+			keywords = EXCLUDED.keywords,
+			title = EXCLUDED.title,
+			alt = EXCLUDED.alt,
+			transcript = EXCLUDED.transcript,
+			link = EXCLUDED.link,
+			news = EXCLUDED.news,
+			published = EXCLUDED.published,
+			hash = EXCLUDED.hash,
+			source = EXCLUDED.source`, name)
+	if err != nil {
+		db.log.Error("failed to restore snapshot", "snapshot", name, "error", err)
+		return fmt.Errorf("restore snapshot %s: %w", name, err)
+	}
+
+	// пустых снимков не бывает, значит ни одной строки - нет снимка
+	restored, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("restore snapshot %s: %w", name, err)
+	}
+	if restored == 0 {
+		return fmt.Errorf("%w: snapshot %s", core.ErrNotFound, name)
+	}
+	return nil
+}
+
+func (db *DB) DeleteSnapshot(ctx context.Context, name string) error {
+	res, err := db.conn.ExecContext(ctx, `DELETE FROM snapshots WHERE name = $1`, name)
+	if err != nil {
+		db.log.Error("failed to delete snapshot", "snapshot", name, "error", err)
+		return fmt.Errorf("delete snapshot %s: %w", name, err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete snapshot %s: %w", name, err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%w: snapshot %s", core.ErrNotFound, name)
+	}
+	return nil
+}
+
+// PruneSnapshots оставляет keep самых новых снимков
+func (db *DB) PruneSnapshots(ctx context.Context, keep int) error {
+	_, err := db.conn.ExecContext(ctx, `
+		DELETE FROM snapshots WHERE name NOT IN (
+			SELECT name FROM snapshots ORDER BY created_at DESC, name DESC LIMIT $1
+		)`, keep)
+	if err != nil {
+		db.log.Error("failed to prune snapshots", "keep", keep, "error", err)
+		return fmt.Errorf("prune snapshots: %w", err)
+	}
+	return nil
+}
+
+// ChangesChannel - канал LISTEN/NOTIFY, который слушает search-сервис
+const ChangesChannel = "comics_changed"
+
+// NotifyChanged будит подписчиков канала ChangesChannel, reason уходит в payload
+func (db *DB) NotifyChanged(ctx context.Context, reason string) error {
+	if _, err := db.conn.ExecContext(ctx, `SELECT pg_notify($1, $2)`, ChangesChannel, reason); err != nil {
+		db.log.Error("failed to notify about changed comics", "reason", reason, "error", err)
+		return fmt.Errorf("notify %s: %w", ChangesChannel, err)
+	}
+	return nil
 }
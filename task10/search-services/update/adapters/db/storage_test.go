@@ -2,12 +2,18 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgtype"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	mock_dbops "yadro.com/course/update/adapters/db/mocks"
 	"yadro.com/course/update/core"
@@ -15,18 +21,19 @@ import (
 
 var logger = slog.New(slog.NewTextHandler(io.Discard, nil))
 
-func TestAdd(t *testing.T) {
+func TestAddBatch(t *testing.T) {
 	testCase := []struct {
 		name     string
-		expected error
+		err      error
+		expected string
 	}{
 		{
-			name:     "success",
-			expected: nil,
+			name: "success",
 		},
 		{
 			name:     "unexpected error",
-			expected: errors.New("unexpected error"),
+			err:      errors.New("unexpected error"),
+			expected: "store batch of 1 comics: unexpected error",
 		},
 	}
 
@@ -40,20 +47,96 @@ func TestAdd(t *testing.T) {
 			mockDBops := mock_dbops.NewMockDBops(ctrl)
 			mockDBops.
 				EXPECT().
-				ExecContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(nil, tc.expected)
+				ExecContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+					gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+					gomock.Any(), gomock.Any()).
+				Return(nil, tc.err)
 
 			db := DB{
 				log:  logger,
 				conn: mockDBops,
 			}
 
-			err := db.Add(context.Background(), core.Comics{})
-			assert.Equal(t, tc.expected, err)
+			err := db.AddBatch(context.Background(), []core.Comics{{ID: 1}})
+			if tc.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expected)
+			assert.ErrorIs(t, err, tc.err)
 		})
 	}
 }
 
+func TestAddBatch_Empty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := DB{
+		log:  logger,
+		conn: mock_dbops.NewMockDBops(ctrl),
+	}
+
+	assert.NoError(t, db.AddBatch(context.Background(), nil))
+}
+
+func TestAddBatch_Chunks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batch := make([]core.Comics, maxBatchRows+1)
+	for i := range batch {
+		batch[i].ID = i + 1
+	}
+
+	var sizes []int
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, query string, args ...any) (sql.Result, error) {
+			assert.Contains(t, query, "WHERE comics.hash <> EXCLUDED.hash")
+			// последний аргумент - ID для очистки журнала ошибок
+			ids := args[len(args)-1].([]int)
+			assert.Len(t, args, len(ids)*11+1)
+			assert.Contains(t, query, fmt.Sprintf("ANY($%d)", len(args)))
+			sizes = append(sizes, len(ids))
+			return nil, nil
+		}).
+		Times(2)
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	assert.NoError(t, db.AddBatch(context.Background(), batch))
+	assert.Equal(t, []int{maxBatchRows, 1}, sizes)
+}
+
+func TestImportBatch_Overwrites(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, query string, args ...any) (sql.Result, error) {
+			// импорт перезаписывает комикс даже с тем же хэшем
+			assert.Contains(t, query, "ON CONFLICT (comics_id) DO UPDATE")
+			assert.NotContains(t, query, "comics.hash <> EXCLUDED.hash")
+			return nil, nil
+		})
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	assert.NoError(t, db.ImportBatch(context.Background(), []core.Comics{{ID: 1, Hash: "same"}}))
+}
+
 func TestStats(t *testing.T) {
 	testCase := []struct {
 		name     string
@@ -165,8 +248,581 @@ func TestDrop(t *testing.T) {
 				conn: mockDBops,
 			}
 
-			err := db.Drop(context.Background())
+			snapshot, err := db.Drop(context.Background(), "")
 			assert.Equal(t, tc.expected, err)
+			assert.Empty(t, snapshot)
+		})
+	}
+}
+
+func TestAddBatch_Metadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	published := time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(),
+			1, "url", []string{"word"},
+			"title", "alt", "transcript", "link", "news", sql.NullTime{Time: published, Valid: true}, "hash", "local",
+			2, "", gomock.Any(),
+			"", "", "", "", "", sql.NullTime{}, "", "",
+			[]int{1, 2}).
+		Return(nil, nil)
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	err := db.AddBatch(context.Background(), []core.Comics{
+		{
+			ID:     1,
+			URL:    "url",
+			Words:  []string{"word"},
+			Hash:   "hash",
+			Source: "local",
+			Metadata: core.Metadata{
+				Title:      "title",
+				Alt:        "alt",
+				Transcript: "transcript",
+				Link:       "link",
+				News:       "news",
+				Published:  published,
+			},
+		},
+		{ID: 2},
+	})
+	assert.NoError(t, err)
+}
+
+func TestAddFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), 42, "norm", "words error").
+		Return(nil, nil)
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	err := db.AddFailure(context.Background(), core.Failure{ID: 42, Stage: core.StageNorm, Error: "words error"})
+	assert.NoError(t, err)
+
+	expected := errors.New("unexpected error")
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, expected)
+
+	err = db.AddFailure(context.Background(), core.Failure{ID: 42})
+	assert.ErrorIs(t, err, expected)
+}
+
+func TestFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failedAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+			*dest.(*[]Failure) = []Failure{
+				{ID: 404, Stage: "fetch", Error: "not found", Attempts: 3, FailedAt: failedAt},
+			}
+			return nil
+		})
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	failures, err := db.Failures(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []core.Failure{
+		{ID: 404, Stage: core.StageFetch, Error: "not found", Attempts: 3, FailedAt: failedAt},
+	}, failures)
+
+	expected := errors.New("unexpected error")
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(expected)
+
+	_, err = db.Failures(context.Background())
+	assert.ErrorIs(t, err, expected)
+}
+
+func TestAddTombstone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), 404).
+		Return(nil, nil)
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	assert.NoError(t, db.AddTombstone(context.Background(), 404))
+
+	expected := errors.New("unexpected error")
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), 404).
+		Return(nil, expected)
+
+	assert.ErrorIs(t, db.AddTombstone(context.Background(), 404), expected)
+}
+
+func TestTombstones(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+			*dest.(*[]int) = []int{404}
+			return nil
+		})
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	ids, err := db.Tombstones(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int{404}, ids)
+
+	expected := errors.New("unexpected error")
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(expected)
+
+	_, err = db.Tombstones(context.Background())
+	assert.ErrorIs(t, err, expected)
+}
+
+func TestMissingIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), 1001, 1010).
+		DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+			*dest.(*[]int) = []int{1003, 1009, 1010}
+			return nil
+		})
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	ids, err := db.MissingIDs(context.Background(), 1001, 1010)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1003, 1009, 1010}, ids)
+
+	expected := errors.New("unexpected error")
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), 1001, 1010).
+		Return(expected)
+
+	_, err = db.MissingIDs(context.Background(), 1001, 1010)
+	assert.ErrorIs(t, err, expected)
+}
+
+func TestHashes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), 10, 20).
+		DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+			*dest.(*[]Hash) = []Hash{{ID: 10, Hash: "a"}, {ID: 11, Hash: ""}}
+			return nil
+		})
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	hashes, err := db.Hashes(context.Background(), 10, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]string{10: "a", 11: ""}, hashes)
+
+	expected := errors.New("unexpected error")
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), 0, 0).
+		Return(expected)
+
+	_, err = db.Hashes(context.Background(), 0, 0)
+	assert.ErrorIs(t, err, expected)
+}
+
+func TestWalk(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	page := make([]comicsRow, walkPageSize)
+	for i := range page {
+		page[i] = comicsRow{ID: i + 1, URL: "url"}
+		require.NoError(t, page[i].Keywords.Set([]string{"word"}))
+	}
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	gomock.InOrder(
+		mockDBops.
+			EXPECT().
+			SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), 0, walkPageSize).
+			DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+				*dest.(*[]comicsRow) = page
+				return nil
+			}),
+		// следующая страница начинается после последнего ID предыдущей
+		mockDBops.
+			EXPECT().
+			SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), walkPageSize, walkPageSize).
+			DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+				*dest.(*[]comicsRow) = []comicsRow{{
+					ID:        walkPageSize + 1,
+					URL:       "last",
+					Keywords:  pgtype.TextArray{Status: pgtype.Null},
+					Published: sql.NullTime{Time: time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+					Source:    "xkcd",
+				}}
+				return nil
+			}),
+	)
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	var walked []core.Comics
+	err := db.Walk(context.Background(), func(comics core.Comics) error {
+		walked = append(walked, comics)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, walked, walkPageSize+1)
+	assert.Equal(t, []string{"word"}, walked[0].Words)
+	assert.Equal(t, core.Comics{
+		ID:       walkPageSize + 1,
+		URL:      "last",
+		Source:   "xkcd",
+		Metadata: core.Metadata{Published: time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}, walked[walkPageSize])
+}
+
+func TestWalk_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	expected := errors.New("unexpected error")
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), 0, walkPageSize).
+		Return(expected)
+	assert.ErrorIs(t, db.Walk(context.Background(), func(core.Comics) error { return nil }), expected)
+
+	// ошибка получателя прерывает выгрузку
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), 0, walkPageSize).
+		DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+			null := pgtype.TextArray{Status: pgtype.Null}
+			*dest.(*[]comicsRow) = []comicsRow{{ID: 1, Keywords: null}, {ID: 2, Keywords: null}}
+			return nil
+		})
+	calls := 0
+	err := db.Walk(context.Background(), func(core.Comics) error {
+		calls++
+		return expected
+	})
+	assert.ErrorIs(t, err, expected)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDrop_Snapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), "drop-1").
+		DoAndReturn(func(_ context.Context, dest interface{}, query string, _ ...interface{}) error {
+			// снимок, копирование и удаление - один запрос
+			require.Contains(t, query, "INSERT INTO snapshots")
+			require.Contains(t, query, "INSERT INTO snapshot_comics")
+			require.Contains(t, query, "DELETE FROM comics")
+			*dest.(*[]snapshotRow) = []snapshotRow{{Name: "drop-1", Comics: 3, CreatedAt: created}}
+			return nil
+		})
+	// пустую таблицу не сохраняем
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), "drop-2").
+		Return(nil)
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	snapshot, err := db.Drop(context.Background(), "drop-1")
+	require.NoError(t, err)
+	require.Equal(t, core.Snapshot{Name: "drop-1", Comics: 3, CreatedAt: created}, snapshot)
+
+	snapshot, err = db.Drop(context.Background(), "drop-2")
+	require.NoError(t, err)
+	require.Empty(t, snapshot)
+}
+
+func TestRestore(t *testing.T) {
+	testCase := []struct {
+		name     string
+		affected int64
+		err      error
+		expected error
+	}{
+		{
+			name:     "success",
+			affected: 3,
+		},
+		{
+			name:     "not found",
+			affected: 0,
+			expected: core.ErrNotFound,
+		},
+		{
+			name:     "unexpected error",
+			err:      errors.New("unexpected error"),
+			expected: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDBops := mock_dbops.NewMockDBops(ctrl)
+			mockDBops.
+				EXPECT().
+				ExecContext(gomock.Any(), gomock.Any(), "drop-1").
+				Return(driver.RowsAffected(tc.affected), tc.err)
+
+			db := DB{
+				log:  logger,
+				conn: mockDBops,
+			}
+
+			err := db.Restore(context.Background(), "drop-1")
+			switch {
+			case tc.expected == nil:
+				require.NoError(t, err)
+			case errors.Is(tc.expected, core.ErrNotFound):
+				require.ErrorIs(t, err, core.ErrNotFound)
+			default:
+				require.ErrorContains(t, err, tc.expected.Error())
+			}
+		})
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+			*dest.(*[]snapshotRow) = []snapshotRow{{Name: "drop-2", Comics: 2, CreatedAt: created}, {Name: "drop-1", Comics: 1}}
+			return nil
 		})
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), "drop-1").
+		Return(driver.RowsAffected(0), nil)
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), 2).
+		Return(driver.RowsAffected(1), nil)
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	snapshots, err := db.Snapshots(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []core.Snapshot{{Name: "drop-2", Comics: 2, CreatedAt: created}, {Name: "drop-1", Comics: 1}}, snapshots)
+
+	require.ErrorIs(t, db.DeleteSnapshot(context.Background(), "drop-1"), core.ErrNotFound)
+	require.NoError(t, db.PruneSnapshots(context.Background(), 2))
+}
+
+func TestDeleteComics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), []int{3, 7}).
+		Return(driver.RowsAffected(2), nil)
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), 10, 20).
+		Return(nil, errors.New("unexpected error"))
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
 	}
+
+	deleted, err := db.DeleteIDs(context.Background(), []int{3, 7})
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	_, err = db.DeleteRange(context.Background(), 10, 20)
+	require.ErrorContains(t, err, "unexpected error")
+}
+
+func TestRuns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	started := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	finished := started.Add(time.Minute)
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(),
+			core.ModeUpdate, core.TriggerScheduled, core.RunCompleted, started, finished, 5, 1, 0, "").
+		Return(driver.RowsAffected(1), nil)
+	mockDBops.
+		EXPECT().
+		GetContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+			*dest.(*int) = 7
+			return nil
+		})
+	mockDBops.
+		EXPECT().
+		SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), 1, 2).
+		DoAndReturn(func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+			*dest.(*[]runRow) = []runRow{{
+				ID: 5, Mode: "update", Trigger: "scheduled", Status: "completed",
+				StartedAt: started, FinishedAt: finished, Stored: 5, Failed: 1,
+			}}
+			return nil
+		})
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	require.NoError(t, db.AddRun(context.Background(), core.UpdateRun{
+		Mode: core.ModeUpdate, Trigger: core.TriggerScheduled, Status: core.RunCompleted,
+		StartedAt: started, FinishedAt: finished, Stored: 5, Failed: 1,
+	}))
+
+	runs, total, err := db.Runs(context.Background(), 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, 7, total)
+	require.Equal(t, []core.UpdateRun{{
+		ID: 5, Mode: core.ModeUpdate, Trigger: core.TriggerScheduled, Status: core.RunCompleted,
+		StartedAt: started, FinishedAt: finished, Stored: 5, Failed: 1,
+	}}, runs)
+}
+
+func TestNotifyChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	db := DB{log: logger, conn: mockDBops}
+
+	mockDBops.EXPECT().ExecContext(gomock.Any(), `SELECT pg_notify($1, $2)`, ChangesChannel, "update").
+		Return(driver.RowsAffected(1), nil)
+	require.NoError(t, db.NotifyChanged(context.Background(), "update"))
+
+	mockDBops.EXPECT().ExecContext(gomock.Any(), gomock.Any(), ChangesChannel, "drop").
+		Return(nil, errors.New("db error"))
+	require.EqualError(t, db.NotifyChanged(context.Background(), "drop"), "notify comics_changed: db error")
+}
+
+func TestStats_ComicKeywords(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDBops := mock_dbops.NewMockDBops(ctrl)
+	mockDBops.
+		EXPECT().
+		GetContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, dest any, query string, _ ...any) error {
+			// слова считаются по comic_keywords, а не разворачиванием comics.keywords;
+			// слово из нескольких полей комикса считается один раз
+			assert.Contains(t, query, "FROM comic_keywords")
+			assert.Contains(t, query, "COUNT(DISTINCT (comic_id, keyword)) AS words_total")
+			assert.Contains(t, query, "COUNT(DISTINCT keyword) AS words_unique")
+			assert.NotContains(t, query, "unnest")
+			*dest.(*Stats) = Stats{WordsTotal: 12, WordsUnique: 7, ComicsFetched: 3}
+			return nil
+		})
+
+	db := DB{
+		log:  logger,
+		conn: mockDBops,
+	}
+
+	stats, err := db.Stats(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, core.DBStats{WordsTotal: 12, WordsUnique: 7, ComicsFetched: 3}, stats)
 }
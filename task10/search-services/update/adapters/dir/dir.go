@@ -0,0 +1,84 @@
+package dir
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"yadro.com/course/update/adapters/xkcd"
+	"yadro.com/course/update/core"
+)
+
+// Source читает комиксы из каталога JSON-файлов в формате xkcd,
+// номер комикса - имя файла: 1.json, 2.json, ...
+type Source struct {
+	log  *slog.Logger
+	path string
+}
+
+func New(path string, log *slog.Logger) (*Source, error) {
+	log.Debug("New Source", "path", path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("open comics directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", path)
+	}
+
+	return &Source{log: log, path: path}, nil
+}
+
+func (s Source) Get(_ context.Context, id int) (core.XKCDInfo, error) {
+	data, err := os.ReadFile(filepath.Join(s.path, strconv.Itoa(id)+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return core.XKCDInfo{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.XKCDInfo{}, fmt.Errorf("failed to read comics %d: %w", id, err)
+	}
+
+	var info xkcd.ComicsInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return core.XKCDInfo{}, fmt.Errorf("failed to decode comics %d: %w", id, err)
+	}
+
+	return info.Info(), nil
+}
+
+func (s Source) LastID(_ context.Context) (int, error) {
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list comics directory: %w", err)
+	}
+
+	lastID := 0
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		// посторонние файлы в каталоге не мешают
+		id, err := strconv.Atoi(name)
+		if err != nil || id < 1 {
+			s.log.Debug("skip file", "name", entry.Name())
+			continue
+		}
+		lastID = max(lastID, id)
+	}
+
+	return lastID, nil
+}
+
+// у локального каталога нет ни повторов, ни предохранителя
+func (s Source) State() core.XKCDState {
+	return core.XKCDState{Breaker: core.BreakerClosed}
+}
@@ -0,0 +1,86 @@
+package dir
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"yadro.com/course/update/core"
+)
+
+var logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := New(dir, logger)
+	require.NoError(t, err)
+
+	_, err = New(filepath.Join(dir, "missing"), logger)
+	require.Error(t, err)
+
+	writeFile(t, dir, "file", "")
+	_, err = New(filepath.Join(dir, "file"), logger)
+	require.Error(t, err)
+}
+
+func TestSource_Get(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "1.json", `{"num":1,"img":"https://example.com/1.png","title":"First","safe_title":"First",`+
+		`"alt":"alt","transcript":"text","day":"2","month":"1","year":"2006"}`)
+	writeFile(t, dir, "2.json", `not json`)
+
+	source, err := New(dir, logger)
+	require.NoError(t, err)
+
+	info, err := source.Get(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, core.XKCDInfo{
+		ID:          1,
+		URL:         "https://example.com/1.png",
+		Description: "First First text alt",
+		Metadata: core.Metadata{
+			Title:      "First",
+			Alt:        "alt",
+			Transcript: "text",
+			Published:  time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}, info)
+
+	_, err = source.Get(context.Background(), 2)
+	require.Error(t, err)
+
+	_, err = source.Get(context.Background(), 3)
+	require.ErrorIs(t, err, core.ErrNotFound)
+}
+
+func TestSource_LastID(t *testing.T) {
+	dir := t.TempDir()
+
+	source, err := New(dir, logger)
+	require.NoError(t, err)
+
+	lastID, err := source.LastID(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 0, lastID)
+
+	writeFile(t, dir, "3.json", "{}")
+	writeFile(t, dir, "12.json", "{}")
+	writeFile(t, dir, "README.md", "")
+	writeFile(t, dir, "draft.json", "{}")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "40.json"), 0o755))
+
+	lastID, err = source.LastID(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 12, lastID)
+}
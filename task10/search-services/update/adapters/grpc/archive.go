@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"bufio"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	updatepb "yadro.com/course/proto/update"
+	"yadro.com/course/update/adapters/archive"
+	"yadro.com/course/update/core"
+)
+
+// размер куска архива в одном сообщении
+const chunkSize = 64 << 10
+
+func (s *Server) Export(_ *emptypb.Empty, stream updatepb.Update_ExportServer) error {
+	buf := bufio.NewWriterSize(chunkWriter{stream: stream}, chunkSize)
+	w := archive.NewWriter(buf)
+
+	if err := s.service.Export(stream.Context(), w.Write); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+func (s *Server) Import(stream updatepb.Update_ImportServer) error {
+	r, err := archive.NewReader(&chunkReader{stream: stream})
+	if err != nil {
+		return updateError(err)
+	}
+	defer r.Close()
+
+	result, err := s.service.Import(stream.Context(), r.Next)
+	if err != nil {
+		return updateError(err)
+	}
+
+	return stream.SendAndClose(importReply(result))
+}
+
+func importReply(result core.ImportResult) *updatepb.ImportReply {
+	return &updatepb.ImportReply{
+		Imported: int64(result.Imported),
+		Skipped:  int64(result.Skipped),
+		Errors:   result.Errors,
+	}
+}
+
+// chunkWriter отправляет каждый сброс буфера отдельным сообщением;
+// Send сериализует данные сразу, поэтому p можно переиспользовать
+type chunkWriter struct {
+	stream updatepb.Update_ExportServer
+}
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&updatepb.ArchiveChunk{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// chunkReader склеивает сообщения клиента в один поток, конец потока - io.EOF
+type chunkReader struct {
+	stream updatepb.Update_ImportServer
+	buf    []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = chunk.GetData()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,124 @@
+package grpc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	updatepb "yadro.com/course/proto/update"
+	"yadro.com/course/update/adapters/archive"
+	"yadro.com/course/update/adapters/grpc"
+	"yadro.com/course/update/core"
+)
+
+type exportStream struct {
+	ggrpc.ServerStream
+	data bytes.Buffer
+}
+
+func (s *exportStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *exportStream) Send(chunk *updatepb.ArchiveChunk) error {
+	s.data.Write(chunk.GetData())
+	return nil
+}
+
+type importStream struct {
+	ggrpc.ServerStream
+	chunks [][]byte
+	reply  *updatepb.ImportReply
+}
+
+func (s *importStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *importStream) Recv() (*updatepb.ArchiveChunk, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return &updatepb.ArchiveChunk{Data: chunk}, nil
+}
+
+func (s *importStream) SendAndClose(reply *updatepb.ImportReply) error {
+	s.reply = reply
+	return nil
+}
+
+func TestServer_Export(t *testing.T) {
+	mockUpd := newMockUpdater(t)
+	mockUpd.
+		EXPECT().
+		Export(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(core.Comics) error) error {
+			return fn(core.Comics{ID: 1, URL: "url", Words: []string{"word"}})
+		})
+
+	stream := &exportStream{}
+	srv := grpc.NewServer(mockUpd, nil)
+	require.NoError(t, srv.Export(&emptypb.Empty{}, stream))
+
+	r, err := archive.NewReader(&stream.data)
+	require.NoError(t, err)
+	comics, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, core.Comics{ID: 1, URL: "url", Words: []string{"word"}}, comics)
+
+	expected := errors.New("db error")
+	mockUpd.EXPECT().Export(gomock.Any(), gomock.Any()).Return(expected)
+	require.ErrorIs(t, srv.Export(&emptypb.Empty{}, &exportStream{}), expected)
+}
+
+func TestServer_Import(t *testing.T) {
+	var data bytes.Buffer
+	w := archive.NewWriter(&data)
+	require.NoError(t, w.Write(core.Comics{ID: 1, URL: "url"}))
+	require.NoError(t, w.Close())
+
+	// архив приходит произвольными кусками
+	half := data.Len() / 2
+	stream := &importStream{chunks: [][]byte{data.Bytes()[:half], data.Bytes()[half:]}}
+
+	mockUpd := newMockUpdater(t)
+	mockUpd.
+		EXPECT().
+		Import(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, next func() (core.Comics, error)) (core.ImportResult, error) {
+			comics, err := next()
+			require.NoError(t, err)
+			require.Equal(t, 1, comics.ID)
+			_, err = next()
+			require.ErrorIs(t, err, io.EOF)
+			return core.ImportResult{Imported: 1, Skipped: 2, Errors: []string{"line 2", "line 3"}}, nil
+		})
+
+	srv := grpc.NewServer(mockUpd, nil)
+	require.NoError(t, srv.Import(stream))
+	require.Equal(t, int64(1), stream.reply.Imported)
+	require.Equal(t, int64(2), stream.reply.Skipped)
+	require.Equal(t, []string{"line 2", "line 3"}, stream.reply.Errors)
+
+	// не gzip
+	err := srv.Import(&importStream{chunks: [][]byte{[]byte("plain text")}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockUpd.
+		EXPECT().
+		Import(gomock.Any(), gomock.Any()).
+		Return(core.ImportResult{}, core.ErrAlreadyExists)
+	err = srv.Import(&importStream{chunks: [][]byte{data.Bytes()}})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+}
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: yadro.com/course/update/core (interfaces: Updater)
+// Source: yadro.com/course/update/core (interfaces: Updater,Scheduler)
 //
 // Generated by this command:
 //
-//	mockgen -package mock_grpc -destination ./mocks/ports.go yadro.com/course/update/core Updater
+//	mockgen -package mock_grpc -destination ./mocks/ports.go yadro.com/course/update/core Updater,Scheduler
 //
 
 // Package mock_grpc is a generated GoMock package.
@@ -41,20 +41,197 @@ func (m *MockUpdater) EXPECT() *MockUpdaterMockRecorder {
 	return m.recorder
 }
 
-// Drop mocks base method.
-func (m *MockUpdater) Drop(arg0 context.Context) error {
+// Cancel mocks base method.
+func (m *MockUpdater) Cancel(arg0 context.Context) (core.ServiceState, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Drop", arg0)
+	ret := m.ctrl.Call(m, "Cancel", arg0)
+	ret0, _ := ret[0].(core.ServiceState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockUpdaterMockRecorder) Cancel(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockUpdater)(nil).Cancel), arg0)
+}
+
+// Delete mocks base method.
+func (m *MockUpdater) Delete(arg0 context.Context, arg1 core.DeleteOptions) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUpdaterMockRecorder) Delete(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUpdater)(nil).Delete), arg0, arg1)
+}
+
+// DeleteSnapshot mocks base method.
+func (m *MockUpdater) DeleteSnapshot(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSnapshot", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// DeleteSnapshot indicates an expected call of DeleteSnapshot.
+func (mr *MockUpdaterMockRecorder) DeleteSnapshot(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSnapshot", reflect.TypeOf((*MockUpdater)(nil).DeleteSnapshot), arg0, arg1)
+}
+
+// Drop mocks base method.
+func (m *MockUpdater) Drop(arg0 context.Context) (core.Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drop", arg0)
+	ret0, _ := ret[0].(core.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // Drop indicates an expected call of Drop.
 func (mr *MockUpdaterMockRecorder) Drop(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drop", reflect.TypeOf((*MockUpdater)(nil).Drop), arg0)
 }
 
+// Export mocks base method.
+func (m *MockUpdater) Export(arg0 context.Context, arg1 func(core.Comics) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockUpdaterMockRecorder) Export(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockUpdater)(nil).Export), arg0, arg1)
+}
+
+// Failures mocks base method.
+func (m *MockUpdater) Failures(arg0 context.Context) ([]core.Failure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Failures", arg0)
+	ret0, _ := ret[0].([]core.Failure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Failures indicates an expected call of Failures.
+func (mr *MockUpdaterMockRecorder) Failures(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Failures", reflect.TypeOf((*MockUpdater)(nil).Failures), arg0)
+}
+
+// History mocks base method.
+func (m *MockUpdater) History(arg0 context.Context, arg1 core.HistoryOptions) (core.History, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", arg0, arg1)
+	ret0, _ := ret[0].(core.History)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockUpdaterMockRecorder) History(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockUpdater)(nil).History), arg0, arg1)
+}
+
+// Import mocks base method.
+func (m *MockUpdater) Import(arg0 context.Context, arg1 func() (core.Comics, error)) (core.ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1)
+	ret0, _ := ret[0].(core.ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockUpdaterMockRecorder) Import(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockUpdater)(nil).Import), arg0, arg1)
+}
+
+// Plan mocks base method.
+func (m *MockUpdater) Plan(arg0 context.Context, arg1 core.UpdateOptions) (core.UpdatePlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Plan", arg0, arg1)
+	ret0, _ := ret[0].(core.UpdatePlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Plan indicates an expected call of Plan.
+func (mr *MockUpdaterMockRecorder) Plan(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Plan", reflect.TypeOf((*MockUpdater)(nil).Plan), arg0, arg1)
+}
+
+// Refresh mocks base method.
+func (m *MockUpdater) Refresh(arg0 context.Context, arg1 core.RefreshOptions) (core.RefreshResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", arg0, arg1)
+	ret0, _ := ret[0].(core.RefreshResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockUpdaterMockRecorder) Refresh(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockUpdater)(nil).Refresh), arg0, arg1)
+}
+
+// Restore mocks base method.
+func (m *MockUpdater) Restore(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockUpdaterMockRecorder) Restore(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUpdater)(nil).Restore), arg0, arg1)
+}
+
+// RetryFailed mocks base method.
+func (m *MockUpdater) RetryFailed(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryFailed", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryFailed indicates an expected call of RetryFailed.
+func (mr *MockUpdaterMockRecorder) RetryFailed(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryFailed", reflect.TypeOf((*MockUpdater)(nil).RetryFailed), arg0)
+}
+
+// Snapshots mocks base method.
+func (m *MockUpdater) Snapshots(arg0 context.Context) ([]core.Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshots", arg0)
+	ret0, _ := ret[0].([]core.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshots indicates an expected call of Snapshots.
+func (mr *MockUpdaterMockRecorder) Snapshots(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshots", reflect.TypeOf((*MockUpdater)(nil).Snapshots), arg0)
+}
+
 // Stats mocks base method.
 func (m *MockUpdater) Stats(arg0 context.Context) (core.ServiceStats, error) {
 	m.ctrl.T.Helper()
@@ -71,10 +248,10 @@ func (mr *MockUpdaterMockRecorder) Stats(arg0 any) *gomock.Call {
 }
 
 // Status mocks base method.
-func (m *MockUpdater) Status(arg0 context.Context) core.ServiceStatus {
+func (m *MockUpdater) Status(arg0 context.Context) core.ServiceState {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Status", arg0)
-	ret0, _ := ret[0].(core.ServiceStatus)
+	ret0, _ := ret[0].(core.ServiceState)
 	return ret0
 }
 
@@ -85,15 +262,68 @@ func (mr *MockUpdaterMockRecorder) Status(arg0 any) *gomock.Call {
 }
 
 // Update mocks base method.
-func (m *MockUpdater) Update(arg0 context.Context) error {
+func (m *MockUpdater) Update(arg0 context.Context, arg1 core.UpdateOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", arg0)
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockUpdaterMockRecorder) Update(arg0 any) *gomock.Call {
+func (mr *MockUpdaterMockRecorder) Update(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUpdater)(nil).Update), arg0, arg1)
+}
+
+// MockScheduler is a mock of Scheduler interface.
+type MockScheduler struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchedulerMockRecorder
+	isgomock struct{}
+}
+
+// MockSchedulerMockRecorder is the mock recorder for MockScheduler.
+type MockSchedulerMockRecorder struct {
+	mock *MockScheduler
+}
+
+// NewMockScheduler creates a new mock instance.
+func NewMockScheduler(ctrl *gomock.Controller) *MockScheduler {
+	mock := &MockScheduler{ctrl: ctrl}
+	mock.recorder = &MockSchedulerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduler) EXPECT() *MockSchedulerMockRecorder {
+	return m.recorder
+}
+
+// Schedule mocks base method.
+func (m *MockScheduler) Schedule(arg0 context.Context) core.Schedule {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Schedule", arg0)
+	ret0, _ := ret[0].(core.Schedule)
+	return ret0
+}
+
+// Schedule indicates an expected call of Schedule.
+func (mr *MockSchedulerMockRecorder) Schedule(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedule", reflect.TypeOf((*MockScheduler)(nil).Schedule), arg0)
+}
+
+// SetSchedule mocks base method.
+func (m *MockScheduler) SetSchedule(arg0 context.Context, arg1 core.ScheduleSettings) (core.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSchedule", arg0, arg1)
+	ret0, _ := ret[0].(core.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSchedule indicates an expected call of SetSchedule.
+func (mr *MockSchedulerMockRecorder) SetSchedule(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUpdater)(nil).Update), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSchedule", reflect.TypeOf((*MockScheduler)(nil).SetSchedule), arg0, arg1)
 }
@@ -1,24 +1,30 @@
-//go:generate mockgen -package mock_grpc -destination ./mocks/ports.go "yadro.com/course/update/core" Updater
+//go:generate mockgen -package mock_grpc -destination ./mocks/ports.go "yadro.com/course/update/core" Updater,Scheduler
 package grpc
 
 import (
 	"context"
 	"errors"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	updatepb "yadro.com/course/proto/update"
 	"yadro.com/course/update/core"
 )
 
-func NewServer(service core.Updater) *Server {
-	return &Server{service: service}
+const watchInterval = 500 * time.Millisecond
+
+func NewServer(service core.Updater, scheduler core.Scheduler) *Server {
+	return &Server{service: service, scheduler: scheduler}
 }
 
 type Server struct {
 	updatepb.UnimplementedUpdateServer
-	service core.Updater
+	service   core.Updater
+	scheduler core.Scheduler
 }
 
 func (s *Server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
@@ -26,32 +32,250 @@ func (s *Server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, erro
 }
 
 func (s *Server) Status(ctx context.Context, _ *emptypb.Empty) (*updatepb.StatusReply, error) {
-	status := s.service.Status(ctx)
+	return statusReply(s.status(ctx)), nil
+}
+
+// status - состояние сервиса с учётом расписания
+func (s *Server) status(ctx context.Context) core.ServiceState {
+	state := s.service.Status(ctx)
+	if s.scheduler != nil {
+		state = state.WithSchedule(s.scheduler.Schedule(ctx))
+	}
+	return state
+}
+
+func (s *Server) WatchUpdate(_ *emptypb.Empty, stream updatepb.Update_WatchUpdateServer) error {
+	ctx := stream.Context()
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	var last core.ServiceState
+	first := true
+
+	for {
+		state := s.status(ctx)
+		if first || state != last {
+			if err := stream.Send(statusReply(state)); err != nil {
+				return err
+			}
+			last, first = state, false
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
 
+func statusReply(state core.ServiceState) *updatepb.StatusReply {
 	var response updatepb.StatusReply
 
-	switch status {
+	switch state.Status {
 	case core.StatusIdle:
 		response.Status = updatepb.Status_STATUS_IDLE
 	case core.StatusRunning:
 		response.Status = updatepb.Status_STATUS_RUNNING
+	case core.StatusCancelled:
+		response.Status = updatepb.Status_STATUS_CANCELLED
+	case core.StatusFailed:
+		response.Status = updatepb.Status_STATUS_FAILED
+	case core.StatusCancelling:
+		response.Status = updatepb.Status_STATUS_CANCELLING
 	default:
 		response.Status = updatepb.Status_STATUS_UNSPECIFIED
 	}
 
-	return &response, nil
+	response.Progress = &updatepb.Progress{
+		Total:      int64(state.Progress.Total),
+		Fetched:    int64(state.Progress.Fetched),
+		Normalized: int64(state.Progress.Normalized),
+		Stored:     int64(state.Progress.Stored),
+		Failed:     int64(state.Progress.Failed),
+		Unchanged:  int64(state.Progress.Unchanged),
+		StartedAt:  timestamp(state.Progress.StartedAt),
+	}
+
+	response.Replica = state.Replica
+	response.Scheduled = state.Scheduled
+	response.NextRun = timestamp(state.NextRun)
+	if !state.LastRun.FinishedAt.IsZero() {
+		response.LastRun = runReply(state.LastRun)
+	}
+	response.Pipeline = &updatepb.PipelineStats{
+		Fetch: stageReply(state.Pipeline.Fetch),
+		Norm:  stageReply(state.Pipeline.Norm),
+		Add:   stageReply(state.Pipeline.Add),
+	}
+	response.Xkcd = &updatepb.XKCDState{Retries: int64(state.XKCD.Retries)}
+	switch state.XKCD.Breaker {
+	case core.BreakerClosed:
+		response.Xkcd.Breaker = updatepb.BreakerState_BREAKER_STATE_CLOSED
+	case core.BreakerOpen:
+		response.Xkcd.Breaker = updatepb.BreakerState_BREAKER_STATE_OPEN
+	case core.BreakerHalfOpen:
+		response.Xkcd.Breaker = updatepb.BreakerState_BREAKER_STATE_HALF_OPEN
+	default:
+		response.Xkcd.Breaker = updatepb.BreakerState_BREAKER_STATE_UNSPECIFIED
+	}
+
+	return &response
 }
 
-func (s *Server) Update(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
-	if err := s.service.Update(ctx); err != nil {
-		if errors.Is(err, core.ErrAlreadyExists) {
-			return nil, status.Error(codes.AlreadyExists, "update already runs")
+func (s *Server) Update(ctx context.Context, in *updatepb.UpdateRequest) (*updatepb.UpdateReply, error) {
+	opts := core.UpdateOptions{
+		Reconcile:   in.GetReconcile(),
+		Resume:      in.GetResume(),
+		NewestFirst: in.GetNewestFirst(),
+	}
+	if in.GetDryRun() {
+		plan, err := s.service.Plan(ctx, opts)
+		if err != nil {
+			return nil, updateError(err)
 		}
-		return nil, err
+		return &updatepb.UpdateReply{Plan: planReply(plan)}, nil
+	}
+
+	if err := s.service.Update(ctx, opts); err != nil {
+		return nil, updateError(err)
+	}
+	return &updatepb.UpdateReply{}, nil
+}
+
+func planReply(plan core.UpdatePlan) *updatepb.UpdatePlan {
+	reply := &updatepb.UpdatePlan{
+		Mode:       string(plan.Mode),
+		Ranges:     make([]*updatepb.SourceRange, 0, len(plan.Ranges)),
+		Missing:    int64s(plan.Missing),
+		Tombstoned: int64s(plan.Tombstoned),
+		Failed:     int64s(plan.Failed),
+		Estimate:   durationpb.New(plan.Estimate),
+	}
+	for _, r := range plan.Ranges {
+		reply.Ranges = append(reply.Ranges, &updatepb.SourceRange{
+			Source: r.Source,
+			From:   int64(r.From),
+			To:     int64(r.To),
+		})
+	}
+	return reply
+}
+
+func int64s(ids []int) []int64 {
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, int64(id))
+	}
+	return out
+}
+
+func (s *Server) RetryFailed(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := s.service.RetryFailed(ctx); err != nil {
+		return nil, updateError(err)
 	}
 	return nil, nil
 }
 
+func (s *Server) Refresh(ctx context.Context, in *updatepb.RefreshRequest) (*updatepb.RefreshReply, error) {
+	result, err := s.service.Refresh(ctx, core.RefreshOptions{From: int(in.GetFrom()), To: int(in.GetTo())})
+	if err != nil {
+		return nil, updateError(err)
+	}
+	return &updatepb.RefreshReply{Checked: int64(result.Checked), Changed: int64(result.Changed)}, nil
+}
+
+func (s *Server) History(ctx context.Context, in *updatepb.HistoryRequest) (*updatepb.HistoryReply, error) {
+	history, err := s.service.History(ctx, core.HistoryOptions{Limit: int(in.GetLimit()), Offset: int(in.GetOffset())})
+	if err != nil {
+		return nil, updateError(err)
+	}
+
+	reply := &updatepb.HistoryReply{
+		Runs:  make([]*updatepb.UpdateRun, 0, len(history.Runs)),
+		Total: int64(history.Total),
+	}
+	for _, run := range history.Runs {
+		reply.Runs = append(reply.Runs, runReply(run))
+	}
+	return reply, nil
+}
+
+func stageReply(stats core.StageStats) *updatepb.StageStats {
+	return &updatepb.StageStats{
+		Workers:    int64(stats.Workers),
+		Buffer:     int64(stats.Buffer),
+		Queue:      int64(stats.Queue),
+		Processed:  int64(stats.Processed),
+		Throughput: stats.Throughput,
+		Latency:    durationpb.New(stats.Latency),
+	}
+}
+
+func runReply(run core.UpdateRun) *updatepb.UpdateRun {
+	return &updatepb.UpdateRun{
+		Id:         run.ID,
+		Mode:       string(run.Mode),
+		Trigger:    string(run.Trigger),
+		Status:     string(run.Status),
+		StartedAt:  timestamppb.New(run.StartedAt),
+		FinishedAt: timestamppb.New(run.FinishedAt),
+		Stored:     int64(run.Stored),
+		Failed:     int64(run.Failed),
+		Skipped:    int64(run.Skipped),
+		Error:      run.Error,
+	}
+}
+
+func updateError(err error) error {
+	switch {
+	case errors.Is(err, core.ErrBadArguments):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, core.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, core.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, "update already runs")
+	case errors.Is(err, core.ErrCancelled):
+		return status.Error(codes.Canceled, "update cancelled")
+	case errors.Is(err, core.ErrUpstreamUnavailable):
+		return status.Error(codes.Unavailable, "xkcd is unavailable")
+	default:
+		return err
+	}
+}
+
+func (s *Server) Failures(ctx context.Context, _ *emptypb.Empty) (*updatepb.FailuresReply, error) {
+	failures, err := s.service.Failures(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &updatepb.FailuresReply{Failures: make([]*updatepb.Failure, 0, len(failures))}
+	for _, failure := range failures {
+		reply.Failures = append(reply.Failures, &updatepb.Failure{
+			Id:       int64(failure.ID),
+			Stage:    string(failure.Stage),
+			Error:    failure.Error,
+			Attempts: int64(failure.Attempts),
+			FailedAt: timestamp(failure.FailedAt),
+		})
+	}
+
+	return reply, nil
+}
+
+func (s *Server) Cancel(ctx context.Context, _ *emptypb.Empty) (*updatepb.StatusReply, error) {
+	state, err := s.service.Cancel(ctx)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "update is not running")
+		}
+		return nil, err
+	}
+	return statusReply(state), nil
+}
+
 func (s *Server) Stats(ctx context.Context, _ *emptypb.Empty) (*updatepb.StatsReply, error) {
 	stats, err := s.service.Stats(ctx)
 
@@ -63,6 +287,101 @@ func (s *Server) Stats(ctx context.Context, _ *emptypb.Empty) (*updatepb.StatsRe
 		err
 }
 
-func (s *Server) Drop(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
-	return nil, s.service.Drop(ctx)
+func (s *Server) Delete(ctx context.Context, in *updatepb.DeleteRequest) (*updatepb.DeleteReply, error) {
+	opts := core.DeleteOptions{From: int(in.GetFrom()), To: int(in.GetTo())}
+	for _, id := range in.GetIds() {
+		opts.IDs = append(opts.IDs, int(id))
+	}
+
+	deleted, err := s.service.Delete(ctx, opts)
+	if err != nil {
+		return nil, updateError(err)
+	}
+	return &updatepb.DeleteReply{Deleted: int64(deleted)}, nil
+}
+
+func (s *Server) Drop(ctx context.Context, _ *emptypb.Empty) (*updatepb.Snapshot, error) {
+	snapshot, err := s.service.Drop(ctx)
+	if err != nil {
+		return nil, updateError(err)
+	}
+	return snapshotReply(snapshot), nil
+}
+
+func (s *Server) ListSnapshots(ctx context.Context, _ *emptypb.Empty) (*updatepb.SnapshotsReply, error) {
+	snapshots, err := s.service.Snapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &updatepb.SnapshotsReply{Snapshots: make([]*updatepb.Snapshot, 0, len(snapshots))}
+	for _, snapshot := range snapshots {
+		reply.Snapshots = append(reply.Snapshots, snapshotReply(snapshot))
+	}
+	return reply, nil
+}
+
+func (s *Server) Restore(ctx context.Context, in *updatepb.SnapshotRequest) (*emptypb.Empty, error) {
+	if err := s.service.Restore(ctx, in.GetName()); err != nil {
+		return nil, updateError(err)
+	}
+	return nil, nil
+}
+
+func (s *Server) DeleteSnapshot(ctx context.Context, in *updatepb.SnapshotRequest) (*emptypb.Empty, error) {
+	if err := s.service.DeleteSnapshot(ctx, in.GetName()); err != nil {
+		return nil, updateError(err)
+	}
+	return nil, nil
+}
+
+func snapshotReply(snapshot core.Snapshot) *updatepb.Snapshot {
+	if snapshot.Name == "" {
+		return &updatepb.Snapshot{}
+	}
+	return &updatepb.Snapshot{
+		Name:      snapshot.Name,
+		Comics:    int64(snapshot.Comics),
+		CreatedAt: timestamppb.New(snapshot.CreatedAt),
+	}
+}
+
+func (s *Server) Schedule(ctx context.Context, _ *emptypb.Empty) (*updatepb.ScheduleReply, error) {
+	return scheduleReply(s.scheduler.Schedule(ctx)), nil
+}
+
+func (s *Server) SetSchedule(ctx context.Context, in *updatepb.ScheduleRequest) (*updatepb.ScheduleReply, error) {
+	var settings core.ScheduleSettings
+
+	if in.GetPeriod() != nil {
+		period := in.GetPeriod().AsDuration()
+		settings.Period = &period
+	}
+	settings.Paused = in.Paused
+
+	schedule, err := s.scheduler.SetSchedule(ctx, settings)
+	if err != nil {
+		if errors.Is(err, core.ErrBadArguments) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	return scheduleReply(schedule), nil
+}
+
+func scheduleReply(schedule core.Schedule) *updatepb.ScheduleReply {
+	return &updatepb.ScheduleReply{
+		Period:  durationpb.New(schedule.Period),
+		Paused:  schedule.Paused,
+		NextRun: timestamp(schedule.NextRun),
+		LastRun: timestamp(schedule.LastRun),
+	}
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
 }
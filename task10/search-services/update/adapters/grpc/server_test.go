@@ -3,12 +3,16 @@ package grpc_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	ggrpc "google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	updatepb "yadro.com/course/proto/update"
@@ -27,7 +31,7 @@ func newMockUpdater(t *testing.T) *mock_grpc.MockUpdater {
 
 func TestServer_Ping(t *testing.T) {
 	upd := newMockUpdater(t)
-	srv := grpc.NewServer(upd)
+	srv := grpc.NewServer(upd, nil)
 
 	resp, err := srv.Ping(context.Background(), &emptypb.Empty{})
 	require.NoError(t, err)
@@ -42,34 +46,191 @@ func TestStatus(t *testing.T) {
 	mockUpd.
 		EXPECT().
 		Status(gomock.Any()).
-		Return(core.StatusIdle)
+		Return(core.ServiceState{Status: core.StatusIdle})
 
-	srv := grpc.NewServer(mockUpd)
+	srv := grpc.NewServer(mockUpd, nil)
 	reply, err := srv.Status(context.Background(), &emptypb.Empty{})
 	require.NoError(t, err)
 	require.Equal(t, updatepb.Status_STATUS_IDLE, reply.Status)
+	require.Nil(t, reply.Progress.StartedAt)
+	require.Nil(t, reply.LastRun)
 
+	startedAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
 	mockUpd.
 		EXPECT().
 		Status(gomock.Any()).
-		Return(core.StatusRunning)
-
-	srv = grpc.NewServer(mockUpd)
+		Return(core.ServiceState{
+			Status: core.StatusRunning,
+			Progress: core.UpdateProgress{
+				Total:      10,
+				Fetched:    5,
+				Normalized: 4,
+				Stored:     3,
+				Failed:     1,
+				StartedAt:  startedAt,
+			},
+			XKCD:    core.XKCDState{Retries: 3, Breaker: core.BreakerHalfOpen},
+			Replica: "update-2",
+			Pipeline: core.PipelineStats{
+				Fetch: core.StageStats{Workers: 10, Buffer: 10, Queue: 7, Processed: 5, Throughput: 2.5, Latency: 300 * time.Millisecond},
+				Norm:  core.StageStats{Workers: 4, Buffer: 10, Processed: 4},
+				Add:   core.StageStats{Workers: 2, Buffer: 100, Queue: 1, Processed: 3},
+			},
+		})
+
+	srv = grpc.NewServer(mockUpd, nil)
 	reply, err = srv.Status(context.Background(), &emptypb.Empty{})
 	require.NoError(t, err)
 	require.Equal(t, updatepb.Status_STATUS_RUNNING, reply.Status)
+	require.Equal(t, int64(10), reply.Progress.Total)
+	require.Equal(t, int64(5), reply.Progress.Fetched)
+	require.Equal(t, int64(4), reply.Progress.Normalized)
+	require.Equal(t, int64(3), reply.Progress.Stored)
+	require.Equal(t, int64(1), reply.Progress.Failed)
+	require.Equal(t, startedAt, reply.Progress.StartedAt.AsTime())
+	require.Equal(t, int64(3), reply.Xkcd.Retries)
+	require.Equal(t, updatepb.BreakerState_BREAKER_STATE_HALF_OPEN, reply.Xkcd.Breaker)
+	require.Equal(t, "update-2", reply.Replica)
+	require.Equal(t, int64(10), reply.Pipeline.Fetch.Workers)
+	require.Equal(t, int64(7), reply.Pipeline.Fetch.Queue)
+	require.Equal(t, 2.5, reply.Pipeline.Fetch.Throughput)
+	require.Equal(t, 300*time.Millisecond, reply.Pipeline.Fetch.Latency.AsDuration())
+	require.Equal(t, int64(4), reply.Pipeline.Norm.Processed)
+	require.Equal(t, int64(100), reply.Pipeline.Add.Buffer)
+	require.Equal(t, int64(1), reply.Pipeline.Add.Queue)
+
+	finishedAt := startedAt.Add(time.Minute)
+	mockUpd.
+		EXPECT().
+		Status(gomock.Any()).
+		Return(core.ServiceState{
+			Status: core.StatusFailed,
+			LastRun: core.UpdateRun{
+				ID:         7,
+				Mode:       core.ModeUpdate,
+				Status:     core.RunFailed,
+				StartedAt:  startedAt,
+				FinishedAt: finishedAt,
+				Stored:     2,
+				Failed:     1,
+				Error:      "xkcd is unavailable",
+			},
+		})
+
+	reply, err = srv.Status(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Equal(t, updatepb.Status_STATUS_FAILED, reply.Status)
+	require.NotNil(t, reply.LastRun)
+	require.Equal(t, int64(7), reply.LastRun.Id)
+	require.Equal(t, "failed", reply.LastRun.Status)
+	require.Equal(t, "xkcd is unavailable", reply.LastRun.Error)
+	require.Equal(t, finishedAt, reply.LastRun.FinishedAt.AsTime())
+	require.Equal(t, int64(2), reply.LastRun.Stored)
+
+	for status, expected := range map[core.ServiceStatus]updatepb.Status{
+		core.StatusCancelling: updatepb.Status_STATUS_CANCELLING,
+		core.StatusCancelled:  updatepb.Status_STATUS_CANCELLED,
+	} {
+		mockUpd.EXPECT().Status(gomock.Any()).Return(core.ServiceState{Status: status})
+		reply, err = srv.Status(context.Background(), &emptypb.Empty{})
+		require.NoError(t, err)
+		require.Equal(t, expected, reply.Status)
+	}
 
 	mockUpd.
 		EXPECT().
 		Status(gomock.Any()).
-		Return(core.ServiceStatus("something else"))
+		Return(core.ServiceState{Status: core.ServiceStatus("something else")})
 
-	srv = grpc.NewServer(mockUpd)
+	srv = grpc.NewServer(mockUpd, nil)
 	reply, err = srv.Status(context.Background(), &emptypb.Empty{})
 	require.NoError(t, err)
 	require.Equal(t, updatepb.Status_STATUS_UNSPECIFIED, reply.Status)
 }
 
+type watchStream struct {
+	ggrpc.ServerStream
+	ctx     context.Context
+	replies []*updatepb.StatusReply
+}
+
+func (s *watchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *watchStream) Send(reply *updatepb.StatusReply) error {
+	s.replies = append(s.replies, reply)
+	return nil
+}
+
+func TestStatus_Scheduled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpd := mock_grpc.NewMockUpdater(ctrl)
+	mockScheduler := mock_grpc.NewMockScheduler(ctrl)
+	srv := grpc.NewServer(mockUpd, mockScheduler)
+	next := time.Now().Add(time.Hour)
+
+	for _, tc := range []struct {
+		name      string
+		state     core.ServiceStatus
+		schedule  core.Schedule
+		expected  updatepb.Status
+		scheduled bool
+	}{
+		{"idle with next run", core.StatusIdle, core.Schedule{NextRun: next}, updatepb.Status_STATUS_IDLE, true},
+		{"paused", core.StatusIdle, core.Schedule{Paused: true}, updatepb.Status_STATUS_IDLE, false},
+		{"running", core.StatusRunning, core.Schedule{NextRun: next}, updatepb.Status_STATUS_RUNNING, true},
+		{"last run failed", core.StatusFailed, core.Schedule{NextRun: next}, updatepb.Status_STATUS_FAILED, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUpd.EXPECT().Status(gomock.Any()).Return(core.ServiceState{Status: tc.state})
+			mockScheduler.EXPECT().Schedule(gomock.Any()).Return(tc.schedule)
+
+			reply, err := srv.Status(context.Background(), &emptypb.Empty{})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, reply.Status)
+			require.Equal(t, tc.scheduled, reply.Scheduled)
+			if tc.scheduled {
+				require.True(t, next.Equal(reply.NextRun.AsTime()))
+			} else {
+				require.Nil(t, reply.NextRun)
+			}
+		})
+	}
+}
+
+func TestWatchUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	running := core.ServiceState{Status: core.StatusRunning, Progress: core.UpdateProgress{Total: 2}}
+	stored := core.ServiceState{Status: core.StatusRunning, Progress: core.UpdateProgress{Total: 2, Stored: 1}}
+
+	mockUpd := mock_grpc.NewMockUpdater(ctrl)
+	gomock.InOrder(
+		mockUpd.EXPECT().Status(gomock.Any()).Return(running),
+		mockUpd.EXPECT().Status(gomock.Any()).Return(running),
+		mockUpd.EXPECT().Status(gomock.Any()).DoAndReturn(func(context.Context) core.ServiceState {
+			cancel()
+			return stored
+		}),
+	)
+
+	stream := &watchStream{ctx: ctx}
+
+	srv := grpc.NewServer(mockUpd, nil)
+	require.NoError(t, srv.WatchUpdate(&emptypb.Empty{}, stream))
+
+	require.Len(t, stream.replies, 2)
+	require.Equal(t, int64(0), stream.replies[0].Progress.Stored)
+	require.Equal(t, int64(1), stream.replies[1].Progress.Stored)
+}
+
 func TestServer_Update(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -77,20 +238,28 @@ func TestServer_Update(t *testing.T) {
 	mockUpd := mock_grpc.NewMockUpdater(ctrl)
 	mockUpd.
 		EXPECT().
-		Update(gomock.Any()).
+		Update(gomock.Any(), core.UpdateOptions{}).
 		Return(nil)
 
-	srv := grpc.NewServer(mockUpd)
-	_, err := srv.Update(context.Background(), &emptypb.Empty{})
+	srv := grpc.NewServer(mockUpd, nil)
+	_, err := srv.Update(context.Background(), &updatepb.UpdateRequest{})
 	require.NoError(t, err)
 
 	mockUpd.
 		EXPECT().
-		Update(gomock.Any()).
+		Update(gomock.Any(), core.UpdateOptions{Reconcile: true}).
+		Return(nil)
+
+	_, err = srv.Update(context.Background(), &updatepb.UpdateRequest{Reconcile: true})
+	require.NoError(t, err)
+
+	mockUpd.
+		EXPECT().
+		Update(gomock.Any(), core.UpdateOptions{}).
 		Return(core.ErrAlreadyExists)
 
-	srv = grpc.NewServer(mockUpd)
-	_, err = srv.Update(context.Background(), &emptypb.Empty{})
+	srv = grpc.NewServer(mockUpd, nil)
+	_, err = srv.Update(context.Background(), &updatepb.UpdateRequest{})
 	grpcErr, ok := status.FromError(err)
 	require.True(t, ok, "ожидается grpc.Status")
 	require.Equal(t, codes.AlreadyExists, grpcErr.Code())
@@ -99,15 +268,213 @@ func TestServer_Update(t *testing.T) {
 	otherErr := errors.New("update failed")
 	mockUpd.
 		EXPECT().
-		Update(gomock.Any()).
+		Update(gomock.Any(), core.UpdateOptions{}).
 		Return(otherErr)
 
-	srv = grpc.NewServer(mockUpd)
-	_, err = srv.Update(context.Background(), &emptypb.Empty{})
+	srv = grpc.NewServer(mockUpd, nil)
+	_, err = srv.Update(context.Background(), &updatepb.UpdateRequest{})
 	require.Error(t, err)
 	require.Equal(t, otherErr.Error(), err.Error())
 }
 
+func TestServer_UpdateResume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpd := mock_grpc.NewMockUpdater(ctrl)
+	mockUpd.
+		EXPECT().
+		Update(gomock.Any(), core.UpdateOptions{Resume: true, NewestFirst: true}).
+		Return(nil)
+
+	srv := grpc.NewServer(mockUpd, nil)
+	_, err := srv.Update(context.Background(), &updatepb.UpdateRequest{Resume: true, NewestFirst: true})
+	require.NoError(t, err)
+
+	mockUpd.
+		EXPECT().
+		Update(gomock.Any(), core.UpdateOptions{Resume: true}).
+		Return(fmt.Errorf("%w: no interrupted update to resume", core.ErrNotFound))
+
+	_, err = srv.Update(context.Background(), &updatepb.UpdateRequest{Resume: true})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_UpdateDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpd := mock_grpc.NewMockUpdater(ctrl)
+	mockUpd.
+		EXPECT().
+		Plan(gomock.Any(), core.UpdateOptions{Reconcile: true}).
+		Return(core.UpdatePlan{
+			Mode:       core.ModeReconcile,
+			Ranges:     []core.SourceRange{{Source: "xkcd", From: 1, To: 10}},
+			Missing:    []int{3, 5},
+			Tombstoned: []int{4},
+			Failed:     []int{5},
+			Estimate:   90 * time.Second,
+		}, nil)
+
+	srv := grpc.NewServer(mockUpd, nil)
+	reply, err := srv.Update(context.Background(), &updatepb.UpdateRequest{Reconcile: true, DryRun: true})
+	require.NoError(t, err)
+
+	plan := reply.GetPlan()
+	require.NotNil(t, plan)
+	require.Equal(t, "reconcile", plan.Mode)
+	require.Len(t, plan.Ranges, 1)
+	require.Equal(t, "xkcd", plan.Ranges[0].Source)
+	require.Equal(t, int64(10), plan.Ranges[0].To)
+	require.Equal(t, []int64{3, 5}, plan.Missing)
+	require.Equal(t, []int64{4}, plan.Tombstoned)
+	require.Equal(t, []int64{5}, plan.Failed)
+	require.Equal(t, 90*time.Second, plan.Estimate.AsDuration())
+
+	mockUpd.
+		EXPECT().
+		Plan(gomock.Any(), core.UpdateOptions{}).
+		Return(core.UpdatePlan{}, core.ErrUpstreamUnavailable)
+
+	_, err = srv.Update(context.Background(), &updatepb.UpdateRequest{DryRun: true})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestServer_Cancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpd := mock_grpc.NewMockUpdater(ctrl)
+	mockUpd.
+		EXPECT().
+		Cancel(gomock.Any()).
+		Return(core.ServiceState{
+			Status:   core.StatusCancelled,
+			Progress: core.UpdateProgress{Total: 10, Stored: 4},
+		}, nil)
+
+	srv := grpc.NewServer(mockUpd, nil)
+	reply, err := srv.Cancel(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Equal(t, updatepb.Status_STATUS_CANCELLED, reply.Status)
+	require.Equal(t, int64(4), reply.Progress.Stored)
+
+	mockUpd.
+		EXPECT().
+		Cancel(gomock.Any()).
+		Return(core.ServiceState{}, core.ErrNotFound)
+
+	_, err = srv.Cancel(context.Background(), &emptypb.Empty{})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	mockUpd.
+		EXPECT().
+		Update(gomock.Any(), core.UpdateOptions{}).
+		Return(core.ErrCancelled)
+
+	_, err = srv.Update(context.Background(), &updatepb.UpdateRequest{})
+	require.Equal(t, codes.Canceled, status.Code(err))
+
+	mockUpd.
+		EXPECT().
+		Update(gomock.Any(), core.UpdateOptions{}).
+		Return(core.ErrUpstreamUnavailable)
+
+	_, err = srv.Update(context.Background(), &updatepb.UpdateRequest{})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestServer_RetryFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpd := mock_grpc.NewMockUpdater(ctrl)
+	mockUpd.
+		EXPECT().
+		RetryFailed(gomock.Any()).
+		Return(nil)
+
+	srv := grpc.NewServer(mockUpd, nil)
+	_, err := srv.RetryFailed(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+
+	mockUpd.
+		EXPECT().
+		RetryFailed(gomock.Any()).
+		Return(core.ErrAlreadyExists)
+
+	_, err = srv.RetryFailed(context.Background(), &emptypb.Empty{})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestServer_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpd := mock_grpc.NewMockUpdater(ctrl)
+	mockUpd.
+		EXPECT().
+		Refresh(gomock.Any(), core.RefreshOptions{From: 10, To: 20}).
+		Return(core.RefreshResult{Checked: 11, Changed: 2}, nil)
+
+	srv := grpc.NewServer(mockUpd, nil)
+	reply, err := srv.Refresh(context.Background(), &updatepb.RefreshRequest{From: 10, To: 20})
+	require.NoError(t, err)
+	require.Equal(t, int64(11), reply.Checked)
+	require.Equal(t, int64(2), reply.Changed)
+
+	mockUpd.
+		EXPECT().
+		Refresh(gomock.Any(), core.RefreshOptions{From: 20, To: 10}).
+		Return(core.RefreshResult{}, core.ErrBadArguments)
+
+	_, err = srv.Refresh(context.Background(), &updatepb.RefreshRequest{From: 20, To: 10})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockUpd.
+		EXPECT().
+		Refresh(gomock.Any(), core.RefreshOptions{}).
+		Return(core.RefreshResult{}, core.ErrAlreadyExists)
+
+	_, err = srv.Refresh(context.Background(), &updatepb.RefreshRequest{})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestServer_Failures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failedAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	mockUpd := mock_grpc.NewMockUpdater(ctrl)
+	mockUpd.
+		EXPECT().
+		Failures(gomock.Any()).
+		Return([]core.Failure{
+			{ID: 404, Stage: core.StageFetch, Error: "not found", Attempts: 2, FailedAt: failedAt},
+		}, nil)
+
+	srv := grpc.NewServer(mockUpd, nil)
+	reply, err := srv.Failures(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, reply.Failures, 1)
+	require.Equal(t, int64(404), reply.Failures[0].Id)
+	require.Equal(t, "fetch", reply.Failures[0].Stage)
+	require.Equal(t, "not found", reply.Failures[0].Error)
+	require.Equal(t, int64(2), reply.Failures[0].Attempts)
+	require.Equal(t, failedAt, reply.Failures[0].FailedAt.AsTime())
+
+	dbErr := errors.New("db error")
+	mockUpd.
+		EXPECT().
+		Failures(gomock.Any()).
+		Return(nil, dbErr)
+
+	_, err = srv.Failures(context.Background(), &emptypb.Empty{})
+	require.ErrorIs(t, err, dbErr)
+}
+
 func TestStats(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -125,7 +492,7 @@ func TestStats(t *testing.T) {
 		Stats(gomock.Any()).
 		Return(expectedStats, nil)
 
-	srv := grpc.NewServer(mockUpd)
+	srv := grpc.NewServer(mockUpd, nil)
 	reply, err := srv.Stats(context.Background(), &emptypb.Empty{})
 
 	require.NoError(t, err)
@@ -143,21 +510,182 @@ func TestDrop(t *testing.T) {
 	mockUpd.
 		EXPECT().
 		Drop(gomock.Any()).
-		Return(nil)
+		Return(core.Snapshot{Name: "drop-1", Comics: 3, CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}, nil)
 
-	srv := grpc.NewServer(mockUpd)
-	_, err := srv.Drop(context.Background(), &emptypb.Empty{})
+	srv := grpc.NewServer(mockUpd, nil)
+	reply, err := srv.Drop(context.Background(), &emptypb.Empty{})
 	require.NoError(t, err)
+	require.Equal(t, "drop-1", reply.GetName())
+	require.Equal(t, int64(3), reply.GetComics())
+	require.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), reply.GetCreatedAt().AsTime())
 
 	dropErr := errors.New("drop failed")
 	mockUpd.
 		EXPECT().
 		Drop(gomock.Any()).
-		Return(dropErr)
+		Return(core.Snapshot{}, dropErr)
 
-	srv = grpc.NewServer(mockUpd)
+	srv = grpc.NewServer(mockUpd, nil)
 	_, err = srv.Drop(context.Background(), &emptypb.Empty{})
 
 	require.Error(t, err)
 	require.Equal(t, dropErr.Error(), err.Error())
+
+	// пока идёт обновление, чистить нельзя
+	mockUpd.
+		EXPECT().
+		Drop(gomock.Any()).
+		Return(core.Snapshot{}, core.ErrAlreadyExists)
+
+	_, err = srv.Drop(context.Background(), &emptypb.Empty{})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestServer_History(t *testing.T) {
+	mockUpd := newMockUpdater(t)
+	srv := grpc.NewServer(mockUpd, nil)
+
+	started := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	mockUpd.
+		EXPECT().
+		History(gomock.Any(), core.HistoryOptions{Limit: 10, Offset: 5}).
+		Return(core.History{Total: 6, Runs: []core.UpdateRun{{
+			ID: 1, Mode: core.ModeRefresh, Trigger: core.TriggerManual, Status: core.RunFailed,
+			StartedAt: started, FinishedAt: started.Add(time.Second), Stored: 2, Failed: 1, Skipped: 3, Error: "boom",
+		}}}, nil)
+
+	reply, err := srv.History(context.Background(), &updatepb.HistoryRequest{Limit: 10, Offset: 5})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), reply.GetTotal())
+	require.Len(t, reply.GetRuns(), 1)
+
+	run := reply.GetRuns()[0]
+	require.Equal(t, "refresh", run.GetMode())
+	require.Equal(t, "manual", run.GetTrigger())
+	require.Equal(t, "failed", run.GetStatus())
+	require.Equal(t, started, run.GetStartedAt().AsTime())
+	require.Equal(t, int64(3), run.GetSkipped())
+	require.Equal(t, "boom", run.GetError())
+
+	mockUpd.
+		EXPECT().
+		History(gomock.Any(), core.HistoryOptions{}).
+		Return(core.History{}, core.ErrBadArguments)
+
+	_, err = srv.History(context.Background(), &updatepb.HistoryRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_Delete(t *testing.T) {
+	mockUpd := newMockUpdater(t)
+	srv := grpc.NewServer(mockUpd, nil)
+
+	mockUpd.
+		EXPECT().
+		Delete(gomock.Any(), core.DeleteOptions{IDs: []int{3, 7}}).
+		Return(2, nil)
+
+	reply, err := srv.Delete(context.Background(), &updatepb.DeleteRequest{Ids: []int64{3, 7}})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), reply.GetDeleted())
+
+	mockUpd.
+		EXPECT().
+		Delete(gomock.Any(), core.DeleteOptions{From: 20, To: 10}).
+		Return(0, core.ErrBadArguments)
+
+	_, err = srv.Delete(context.Background(), &updatepb.DeleteRequest{From: 20, To: 10})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSnapshots(t *testing.T) {
+	mockUpd := newMockUpdater(t)
+	srv := grpc.NewServer(mockUpd, nil)
+	ctx := context.Background()
+
+	created := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	mockUpd.
+		EXPECT().
+		Snapshots(gomock.Any()).
+		Return([]core.Snapshot{{Name: "drop-2", Comics: 2, CreatedAt: created}, {Name: "drop-1", Comics: 1, CreatedAt: created}}, nil)
+
+	reply, err := srv.ListSnapshots(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, reply.GetSnapshots(), 2)
+	require.Equal(t, "drop-2", reply.GetSnapshots()[0].GetName())
+	require.Equal(t, int64(1), reply.GetSnapshots()[1].GetComics())
+
+	mockUpd.EXPECT().Restore(gomock.Any(), "drop-1").Return(nil)
+	_, err = srv.Restore(ctx, &updatepb.SnapshotRequest{Name: "drop-1"})
+	require.NoError(t, err)
+
+	mockUpd.EXPECT().Restore(gomock.Any(), "missing").Return(core.ErrNotFound)
+	_, err = srv.Restore(ctx, &updatepb.SnapshotRequest{Name: "missing"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	mockUpd.EXPECT().Restore(gomock.Any(), "drop-1").Return(core.ErrAlreadyExists)
+	_, err = srv.Restore(ctx, &updatepb.SnapshotRequest{Name: "drop-1"})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	mockUpd.EXPECT().DeleteSnapshot(gomock.Any(), "drop-1").Return(nil)
+	_, err = srv.DeleteSnapshot(ctx, &updatepb.SnapshotRequest{Name: "drop-1"})
+	require.NoError(t, err)
+
+	mockUpd.EXPECT().DeleteSnapshot(gomock.Any(), "").Return(core.ErrBadArguments)
+	_, err = srv.DeleteSnapshot(ctx, &updatepb.SnapshotRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lastRun := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	mockScheduler := mock_grpc.NewMockScheduler(ctrl)
+	mockScheduler.
+		EXPECT().
+		Schedule(gomock.Any()).
+		Return(core.Schedule{Period: time.Hour, LastRun: lastRun})
+
+	srv := grpc.NewServer(nil, mockScheduler)
+	reply, err := srv.Schedule(context.Background(), &emptypb.Empty{})
+
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, reply.Period.AsDuration())
+	require.False(t, reply.Paused)
+	require.Nil(t, reply.NextRun)
+	require.Equal(t, lastRun, reply.LastRun.AsTime())
+}
+
+func TestSetSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	period := 30 * time.Minute
+	paused := true
+
+	mockScheduler := mock_grpc.NewMockScheduler(ctrl)
+	mockScheduler.
+		EXPECT().
+		SetSchedule(gomock.Any(), core.ScheduleSettings{Period: &period, Paused: &paused}).
+		Return(core.Schedule{Period: period, Paused: true}, nil)
+
+	srv := grpc.NewServer(nil, mockScheduler)
+	reply, err := srv.SetSchedule(context.Background(), &updatepb.ScheduleRequest{
+		Period: durationpb.New(period),
+		Paused: &paused,
+	})
+
+	require.NoError(t, err)
+	require.True(t, reply.Paused)
+	require.Equal(t, period, reply.Period.AsDuration())
+
+	mockScheduler.
+		EXPECT().
+		SetSchedule(gomock.Any(), gomock.Any()).
+		Return(core.Schedule{}, core.ErrBadArguments)
+
+	_, err = srv.SetSchedule(context.Background(), &updatepb.ScheduleRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
 }
@@ -0,0 +1,103 @@
+package xkcd
+
+import (
+	"sync"
+	"time"
+
+	"yadro.com/course/update/core"
+)
+
+type breaker struct {
+	mx        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow сообщает, можно ли сейчас идти в upstream.
+// После cooldown пропускается один пробный запрос.
+func (b *breaker) allow() bool {
+	if b == nil || b.threshold <= 0 {
+		return true
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) success() {
+	if b == nil {
+		return
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *breaker) failure() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// пробный запрос прерван отменой и ничего не говорит о состоянии upstream
+func (b *breaker) abort() {
+	if b == nil {
+		return
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.probing = false
+}
+
+func (b *breaker) state() core.BreakerState {
+	if b == nil || b.threshold <= 0 {
+		return core.BreakerClosed
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	switch {
+	case b.failures < b.threshold:
+		return core.BreakerClosed
+	case b.probing || b.now().Sub(b.openedAt) >= b.cooldown:
+		return core.BreakerHalfOpen
+	default:
+		return core.BreakerOpen
+	}
+}
@@ -0,0 +1,63 @@
+package xkcd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"yadro.com/course/update/core"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	b := newBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	require.True(t, b.allow())
+	b.failure()
+	require.Equal(t, core.BreakerClosed, b.state())
+
+	b.failure()
+	require.Equal(t, core.BreakerOpen, b.state())
+	require.False(t, b.allow())
+
+	now = now.Add(time.Minute)
+	require.Equal(t, core.BreakerHalfOpen, b.state())
+	require.True(t, b.allow())
+	// пока идёт пробный запрос, остальные ждут
+	require.False(t, b.allow())
+
+	b.failure()
+	require.Equal(t, core.BreakerOpen, b.state())
+
+	now = now.Add(time.Minute)
+	require.True(t, b.allow())
+	b.success()
+	require.Equal(t, core.BreakerClosed, b.state())
+	require.True(t, b.allow())
+}
+
+func TestBreaker_Abort(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	b := newBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.failure()
+	now = now.Add(time.Minute)
+	require.True(t, b.allow())
+	require.False(t, b.allow())
+
+	b.abort()
+	require.True(t, b.allow())
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := newBreaker(0, time.Minute)
+	for range 10 {
+		b.failure()
+	}
+	require.True(t, b.allow())
+	require.Equal(t, core.BreakerClosed, b.state())
+}
@@ -0,0 +1,122 @@
+package xkcd
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// на сколько шагов успешных запросов делится возврат к заданной скорости
+	recoverSteps = 20
+	// ниже этой доли заданной скорости 429 уже не притормаживает
+	minRateDivisor = 16
+)
+
+// bucket - token bucket по мотивам limiter/bucket. Токены досчитываются при
+// каждом запросе, поэтому фоновая горутина не нужна и скорость можно менять
+// на ходу: 429 вдвое снижает её, успешные запросы понемногу возвращают (AIMD).
+type bucket struct {
+	mx     sync.Mutex
+	rps    float64
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	paused time.Time
+	now    func() time.Time
+}
+
+// rps <= 0 - без ограничения, тогда возвращается nil
+func newBucket(rps float64, burst int) *bucket {
+	if rps <= 0 {
+		return nil
+	}
+	burst = max(burst, 1)
+	return &bucket{
+		rps:    rps,
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Wait ждёт свободный токен или отмену ctx
+func (b *bucket) Wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	for {
+		delay := b.take()
+		if delay == 0 {
+			return nil
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// take забирает токен и возвращает 0 либо сообщает, сколько ждать следующего
+func (b *bucket) take() time.Duration {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	now := b.now()
+	if now.Before(b.paused) {
+		return b.paused.Sub(now)
+	}
+
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// throttle реагирует на 429: скорость вдвое ниже, накопленные токены сгорают,
+// а на время pause запросы не выпускаются совсем
+func (b *bucket) throttle(pause time.Duration) {
+	if b == nil {
+		return
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.rate = max(b.rate/2, b.rps/minRateDivisor)
+	b.tokens = 0
+	if until := b.now().Add(pause); until.After(b.paused) {
+		b.paused = until
+	}
+}
+
+// success понемногу возвращает скорость к заданной
+func (b *bucket) success() {
+	if b == nil {
+		return
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.rate = min(b.rps, b.rate+b.rps/recoverSteps)
+}
+
+func (b *bucket) currentRate() float64 {
+	if b == nil {
+		return 0
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	return b.rate
+}
@@ -0,0 +1,76 @@
+package xkcd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucket(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	b := newBucket(10, 2)
+	b.now = func() time.Time { return now }
+
+	// burst выдаётся сразу
+	require.Zero(t, b.take())
+	require.Zero(t, b.take())
+	require.Equal(t, 100*time.Millisecond, b.take())
+
+	now = now.Add(100 * time.Millisecond)
+	require.Zero(t, b.take())
+
+	// за долгий простой копится не больше burst
+	now = now.Add(time.Minute)
+	require.Zero(t, b.take())
+	require.Zero(t, b.take())
+	require.NotZero(t, b.take())
+}
+
+func TestBucket_Throttle(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	b := newBucket(16, 4)
+	b.now = func() time.Time { return now }
+
+	b.throttle(time.Second)
+	require.Equal(t, float64(8), b.currentRate())
+	// на время Retry-After запросы не выпускаются
+	require.Equal(t, time.Second, b.take())
+
+	now = now.Add(time.Second)
+	require.NotZero(t, b.take())
+	now = now.Add(125 * time.Millisecond)
+	require.Zero(t, b.take())
+
+	// ниже нижней границы скорость не падает
+	for range 10 {
+		b.throttle(0)
+	}
+	require.Equal(t, float64(1), b.currentRate())
+
+	// успешные запросы возвращают скорость, но не выше заданной
+	for range recoverSteps * 2 {
+		b.success()
+	}
+	require.Equal(t, float64(16), b.currentRate())
+}
+
+func TestBucket_Wait(t *testing.T) {
+	b := newBucket(1, 1)
+	require.NoError(t, b.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, b.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestBucket_Disabled(t *testing.T) {
+	b := newBucket(0, 10)
+	require.Nil(t, b)
+	require.NoError(t, b.Wait(context.Background()))
+	b.throttle(time.Minute)
+	b.success()
+}
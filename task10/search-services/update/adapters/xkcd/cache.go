@@ -0,0 +1,181 @@
+package xkcd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const latestFile = "latest.json"
+
+// entry - ответ upstream вместе с валидаторами для условного запроса
+type entry struct {
+	Body         json.RawMessage `json:"body"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+}
+
+// same - ответ не изменился и перезаписывать кэш незачем
+func (e entry) same(other entry) bool {
+	return e.ETag == other.ETag && e.LastModified == other.LastModified && bytes.Equal(e.Body, other.Body)
+}
+
+// cache - кэш ответов xkcd вместе с ETag/Last-Modified. Комиксы в xkcd иногда
+// исправляют, поэтому закэшированный ответ перепроверяется условным запросом,
+// а без сети берётся как есть. Пустой dir - комиксы не кэшируются, последний
+// хранится только в памяти; nil-кэш ничего не хранит.
+type cache struct {
+	log    *slog.Logger
+	dir    string
+	mx     sync.Mutex
+	latest entry
+}
+
+// upstreamDir - подкаталог кэша для upstream: номера комиксов у xkcd, fake-xkcd
+// и других источников совпадают, и их ответы не должны перемешиваться
+func upstreamDir(dir, rawURL string) string {
+	if dir == "" {
+		return ""
+	}
+
+	key := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		key = u.Host + u.Path
+	}
+	key = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, strings.TrimRight(key, "/"))
+	return filepath.Join(dir, key)
+}
+
+func newCache(log *slog.Logger, dir string) (*cache, error) {
+	c := &cache{log: log, dir: dir}
+	if dir == "" {
+		return c, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, latestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cached latest comics: %w", err)
+	}
+	// битый файл не повод не стартовать, просто запросим заново
+	if err := json.Unmarshal(data, &c.latest); err != nil {
+		log.Warn("ignoring broken cached latest comics", "error", err)
+		c.latest = entry{}
+	}
+	return c, nil
+}
+
+func (c *cache) comics(id int) (entry, bool) {
+	if c == nil || c.dir == "" {
+		return entry{}, false
+	}
+
+	data, err := os.ReadFile(c.comicsPath(id))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			c.log.Warn("failed to read cached comics", "comic_id", id, "error", err)
+		}
+		return entry{}, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		c.log.Warn("ignoring broken cached comics", "comic_id", id, "error", err)
+		return entry{}, false
+	}
+	// старый формат кэша - голый JSON комикса без валидаторов
+	if e.Body == nil {
+		e.Body = data
+	}
+	return e, true
+}
+
+func (c *cache) storeComics(id int, e entry) {
+	if c == nil || c.dir == "" {
+		return
+	}
+
+	data, err := json.Marshal(e)
+	if err == nil {
+		err = c.write(c.comicsPath(id), data)
+	}
+	if err != nil {
+		c.log.Warn("failed to cache comics", "comic_id", id, "error", err)
+	}
+}
+
+func (c *cache) lastEntry() entry {
+	if c == nil {
+		return entry{}
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.latest
+}
+
+func (c *cache) storeLatest(e entry) {
+	if c == nil {
+		return
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.latest = e
+	if c.dir == "" {
+		return
+	}
+
+	data, err := json.Marshal(e)
+	if err == nil {
+		err = c.write(filepath.Join(c.dir, latestFile), data)
+	}
+	if err != nil {
+		c.log.Warn("failed to cache latest comics", "error", err)
+	}
+}
+
+func (c *cache) comicsPath(id int) string {
+	return filepath.Join(c.dir, strconv.Itoa(id)+".json")
+}
+
+// write пишет через временный файл, чтобы параллельный читатель не увидел половину
+func (c *cache) write(path string, data []byte) error {
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
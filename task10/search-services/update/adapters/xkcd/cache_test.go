@@ -0,0 +1,193 @@
+package xkcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Get_DiskCache(t *testing.T) {
+	dir := t.TempDir()
+
+	calls := 0
+	client := newRetryClient(t, Options{CacheDir: dir}, func(req *http.Request) (*http.Response, error) {
+		calls++
+		return comicsResponse(t, 7), nil
+	})
+
+	info, err := client.Get(context.Background(), 7)
+	require.NoError(t, err)
+	require.Equal(t, 7, info.ID)
+	require.FileExists(t, filepath.Join(dir, "xkcd.com", "7.json"))
+
+	// без сети тот же комикс берётся с диска, даже новым клиентом
+	offline := newRetryClient(t, Options{CacheDir: dir}, func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("network is unreachable")
+	})
+	info, err = offline.Get(context.Background(), 7)
+	require.NoError(t, err)
+	require.Equal(t, 7, info.ID)
+	require.Equal(t, 1, calls)
+}
+
+func TestClient_Get_Revalidate(t *testing.T) {
+	dir := t.TempDir()
+
+	calls := 0
+	client := newRetryClient(t, Options{CacheDir: dir}, func(req *http.Request) (*http.Response, error) {
+		calls++
+		switch calls {
+		case 1:
+			require.Empty(t, req.Header.Get("If-None-Match"))
+			resp := comicsResponse(t, 7)
+			resp.Header = http.Header{"Etag": {`"v1"`}}
+			return resp, nil
+		case 2:
+			require.Equal(t, `"v1"`, req.Header.Get("If-None-Match"))
+			return &http.Response{
+				StatusCode: http.StatusNotModified,
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		default:
+			// комикс исправили - приходит новое тело и новый ETag
+			require.Equal(t, `"v1"`, req.Header.Get("If-None-Match"))
+			body, err := json.Marshal(ComicsInfo{ID: 7, Title: "fixed"})
+			require.NoError(t, err)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Etag": {`"v2"`}},
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			}, nil
+		}
+	})
+
+	for _, title := range []string{"", "", "fixed"} {
+		info, err := client.Get(context.Background(), 7)
+		require.NoError(t, err)
+		require.Equal(t, title, info.Title)
+	}
+
+	cached, ok := client.cache.comics(7)
+	require.True(t, ok)
+	require.Equal(t, `"v2"`, cached.ETag)
+}
+
+func TestClient_Get_CachePerUpstream(t *testing.T) {
+	dir := t.TempDir()
+
+	upstream := newRetryClient(t, Options{CacheDir: dir}, func(req *http.Request) (*http.Response, error) {
+		return comicsResponse(t, 7), nil
+	})
+	_, err := upstream.Get(context.Background(), 7)
+	require.NoError(t, err)
+
+	// fake-xkcd с тем же каталогом кэша не видит комиксы настоящего xkcd
+	fake, err := NewClient("http://fake-xkcd:8080", 5*time.Second, Options{CacheDir: dir}, logger)
+	require.NoError(t, err)
+	fake.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("network is unreachable")
+	})
+	_, err = fake.Get(context.Background(), 7)
+	require.Error(t, err)
+	require.FileExists(t, filepath.Join(dir, "xkcd.com", "7.json"))
+	require.NoFileExists(t, filepath.Join(dir, "fake-xkcd_8080", "7.json"))
+}
+
+func TestUpstreamDir(t *testing.T) {
+	require.Empty(t, upstreamDir("", "https://xkcd.com"))
+	require.Equal(t, filepath.Join("cache", "xkcd.com"), upstreamDir("cache", "https://xkcd.com/"))
+	require.Equal(t, filepath.Join("cache", "fake-xkcd_8080"), upstreamDir("cache", "http://fake-xkcd:8080"))
+	require.Equal(t, filepath.Join("cache", "example.org_mirror"), upstreamDir("cache", "https://example.org/mirror"))
+}
+
+func TestCache_LegacyComics(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "7.json"), []byte(`{"num":7}`), 0o644))
+
+	c, err := newCache(logger, dir)
+	require.NoError(t, err)
+	e, ok := c.comics(7)
+	require.True(t, ok)
+	require.JSONEq(t, `{"num":7}`, string(e.Body))
+	require.Empty(t, e.ETag)
+}
+
+func TestClient_LastID_Revalidate(t *testing.T) {
+	const etag = `"abc"`
+	const modified = "Mon, 06 Jan 2025 10:00:00 GMT"
+
+	calls := 0
+	client := newRetryClient(t, Options{}, func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			require.Empty(t, req.Header.Get("If-None-Match"))
+			resp := comicsResponse(t, 3000)
+			resp.Header = http.Header{"Etag": {etag}, "Last-Modified": {modified}}
+			return resp, nil
+		}
+		require.Equal(t, etag, req.Header.Get("If-None-Match"))
+		require.Equal(t, modified, req.Header.Get("If-Modified-Since"))
+		return &http.Response{
+			StatusCode: http.StatusNotModified,
+			Body:       io.NopCloser(bytes.NewReader(nil)),
+		}, nil
+	})
+
+	for range 2 {
+		id, err := client.LastID(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 3000, id)
+	}
+	require.Equal(t, 2, calls)
+}
+
+func TestClient_LastID_Offline(t *testing.T) {
+	dir := t.TempDir()
+
+	online := newRetryClient(t, Options{CacheDir: dir}, func(req *http.Request) (*http.Response, error) {
+		return comicsResponse(t, 3000), nil
+	})
+	_, err := online.LastID(context.Background())
+	require.NoError(t, err)
+
+	offline := newRetryClient(t, Options{CacheDir: dir}, func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("network is unreachable")
+	})
+	id, err := offline.LastID(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3000, id)
+
+	// без кэша ошибку скрывать нечем
+	empty := newRetryClient(t, Options{}, func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("network is unreachable")
+	})
+	_, err = empty.LastID(context.Background())
+	require.Error(t, err)
+}
+
+func TestNewCache_BrokenLatest(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, latestFile), []byte("{broken"), 0o644))
+
+	c, err := newCache(logger, dir)
+	require.NoError(t, err)
+	require.Nil(t, c.lastEntry().Body)
+}
+
+func TestCache_Nil(t *testing.T) {
+	var c *cache
+	_, ok := c.comics(1)
+	require.False(t, ok)
+	c.storeComics(1, entry{Body: []byte("{}")})
+	c.storeLatest(entry{Body: []byte("{}")})
+	require.Nil(t, c.lastEntry().Body)
+}
@@ -3,18 +3,42 @@ package xkcd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"yadro.com/course/update/core"
 )
 
 type Client struct {
-	log    *slog.Logger
-	client http.Client
-	url    string
+	log     *slog.Logger
+	client  http.Client
+	url     string
+	opts    Options
+	breaker *breaker
+	limiter *bucket
+	cache   *cache
+	retries *atomic.Int64
+}
+
+type Options struct {
+	Retries          int
+	Backoff          time.Duration
+	MaxBackoff       time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// RPS - предел запросов в секунду к upstream, 0 - без ограничения
+	RPS   float64
+	Burst int
+	// CacheDir - каталог для JSON скачанных комиксов, пустой - кэш только в памяти.
+	// У каждого upstream в нём свой подкаталог.
+	CacheDir string
 }
 
 type ComicsInfo struct {
@@ -24,62 +48,286 @@ type ComicsInfo struct {
 	SafeTitle  string `json:"safe_title"`
 	Transcript string `json:"transcript"`
 	Alt        string `json:"alt"`
+	Link       string `json:"link"`
+	News       string `json:"news"`
+	Day        string `json:"day"`
+	Month      string `json:"month"`
+	Year       string `json:"year"`
+}
+
+// дата публикации приходит тремя строками, битую дату просто не заполняем
+func (info ComicsInfo) published() time.Time {
+	day, errDay := strconv.Atoi(info.Day)
+	month, errMonth := strconv.Atoi(info.Month)
+	year, errYear := strconv.Atoi(info.Year)
+	if errDay != nil || errMonth != nil || errYear != nil {
+		return time.Time{}
+	}
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
 }
 
 const lastPath = "/info.0.json"
 
-func NewClient(url string, timeout time.Duration, log *slog.Logger) (*Client, error) {
-	log.Debug("New Client", "url", url, "timeout", timeout)
+// 429 и 5xx, а также сетевые ошибки имеет смысл повторить
+type transientError struct {
+	err        error
+	retryAfter time.Duration
+	throttled  bool
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+func NewClient(url string, timeout time.Duration, opts Options, log *slog.Logger) (*Client, error) {
+	log.Debug("New Client", "url", url, "timeout", timeout, "options", opts)
 
 	if url == "" {
 		return nil, fmt.Errorf("empty base url specified")
 	}
+	if opts.Retries < 0 {
+		return nil, fmt.Errorf("wrong retries specified: %d", opts.Retries)
+	}
+	if opts.Retries > 0 && opts.Backoff <= 0 {
+		return nil, fmt.Errorf("wrong retry backoff specified: %v", opts.Backoff)
+	}
+	if opts.RPS < 0 {
+		return nil, fmt.Errorf("wrong rps specified: %v", opts.RPS)
+	}
+	if opts.MaxBackoff < opts.Backoff {
+		opts.MaxBackoff = opts.Backoff
+	}
+
+	cache, err := newCache(log, upstreamDir(opts.CacheDir, url))
+	if err != nil {
+		return nil, err
+	}
 
 	return &Client{
-		client: http.Client{Timeout: timeout},
-		log:    log,
-		url:    url,
+		client:  http.Client{Timeout: timeout},
+		log:     log,
+		url:     url,
+		opts:    opts,
+		breaker: newBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+		limiter: newBucket(opts.RPS, opts.Burst),
+		cache:   cache,
+		retries: &atomic.Int64{},
 	}, nil
 }
 
+func (c Client) State() core.XKCDState {
+	state := core.XKCDState{Breaker: c.breaker.state()}
+	if c.retries != nil {
+		state.Retries = int(c.retries.Load())
+	}
+	return state
+}
+
+// Get перепроверяет закэшированный комикс условным запросом, чтобы исправления
+// в xkcd доходили до Refresh; без upstream отдаёт закэшированный
 func (c Client) Get(ctx context.Context, id int) (core.XKCDInfo, error) {
-	return c.get(ctx, fmt.Sprintf("%s/%d/%s", c.url, id, lastPath))
+	cached, _ := c.cache.comics(id)
+
+	e, err := c.request(ctx, fmt.Sprintf("%s/%d/%s", c.url, id, lastPath), cached)
+	if err != nil {
+		if cached.Body == nil || errors.Is(err, core.ErrNotFound) || ctx.Err() != nil {
+			return core.XKCDInfo{}, err
+		}
+		c.log.Warn("xkcd is unavailable, using cached comics", "comic_id", id, "error", err)
+		e = cached
+	}
+
+	info, err := decode(e.Body)
+	if err != nil {
+		return core.XKCDInfo{}, err
+	}
+	if !e.same(cached) {
+		c.cache.storeComics(id, e)
+	}
+	return info, nil
 }
 
+// LastID перепроверяет последний комикс условным запросом; если upstream
+// недоступен, а номер уже известен, работаем по нему
 func (c Client) LastID(ctx context.Context) (int, error) {
-	comics, err := c.get(ctx, c.url+lastPath)
+	cached := c.cache.lastEntry()
+
+	e, err := c.request(ctx, c.url+lastPath, cached)
+	if err != nil {
+		if cached.Body == nil || errors.Is(err, core.ErrNotFound) || ctx.Err() != nil {
+			return 0, err
+		}
+		c.log.Warn("xkcd is unavailable, using cached latest comics", "error", err)
+		e = cached
+	}
+
+	comics, err := decode(e.Body)
 	if err != nil {
 		return 0, err
 	}
+	c.cache.storeLatest(e)
 	return comics.ID, nil
 }
 
-func (c Client) get(ctx context.Context, url string) (core.XKCDInfo, error) {
+// request запрашивает url с повторами; непустой cached превращает запрос
+// в условный, и на 304 возвращается он же
+func (c Client) request(ctx context.Context, url string, cached entry) (entry, error) {
 	c.log.Debug("get", "url", url)
 
+	if !c.breaker.allow() {
+		return entry{}, core.ErrUpstreamUnavailable
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			c.breaker.abort()
+			return entry{}, err
+		}
+
+		e, err := c.fetch(ctx, url, cached)
+
+		var transient *transientError
+		if !errors.As(err, &transient) {
+			c.breaker.success()
+			c.limiter.success()
+			return e, err
+		}
+		if transient.throttled {
+			// притормаживаем всех воркеров сразу, но не дольше допустимой паузы
+			c.limiter.throttle(min(transient.retryAfter, c.opts.MaxBackoff))
+			c.log.Warn("xkcd is throttling requests", "url", url, "rate", c.limiter.currentRate())
+		}
+		if ctx.Err() != nil {
+			c.breaker.abort()
+			return entry{}, err
+		}
+
+		delay := c.backoff(attempt, transient.retryAfter)
+		// Retry-After больше допустимой паузы - не ждём, а считаем попытку неудачной
+		if attempt >= c.opts.Retries || delay > c.opts.MaxBackoff {
+			c.breaker.failure()
+			return entry{}, err
+		}
+
+		c.log.Debug("retry xkcd request", "url", url, "attempt", attempt+1, "delay", delay, "error", err)
+		c.retries.Add(1)
+
+		if err := sleep(ctx, delay); err != nil {
+			c.breaker.abort()
+			return entry{}, err
+		}
+	}
+}
+
+func (c Client) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	delay := c.opts.Backoff << attempt
+	if delay <= 0 || delay > c.opts.MaxBackoff {
+		delay = c.opts.MaxBackoff
+	}
+	// jitter: от половины до полной задержки
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+func (c Client) fetch(ctx context.Context, url string, cached entry) (entry, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return core.XKCDInfo{}, fmt.Errorf("failed to create request: %w", err)
+		return entry{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	if cached.Body != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return core.XKCDInfo{}, fmt.Errorf("failed to request comics: %w", err)
+		return entry{}, &transientError{err: fmt.Errorf("failed to request comics: %w", err)}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return core.XKCDInfo{}, core.ErrNotFound
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached.Body != nil:
+		return cached, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return entry{}, core.ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return entry{}, &transientError{
+			err:        fmt.Errorf("xkcd responded with status %d", resp.StatusCode),
+			retryAfter: retryAfter(resp.Header.Get("Retry-After")),
+			throttled:  resp.StatusCode == http.StatusTooManyRequests,
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return entry{}, &transientError{err: fmt.Errorf("failed to read comics: %w", err)}
 	}
 
+	return entry{
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+func decode(body []byte) (core.XKCDInfo, error) {
 	var info ComicsInfo
-	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+	if err := json.Unmarshal(body, &info); err != nil {
 		return core.XKCDInfo{}, fmt.Errorf("failed to decode comics: %w", err)
 	}
 
+	return info.Info(), nil
+}
+
+// Info переводит комикс из формата xkcd в модель сервиса
+func (info ComicsInfo) Info() core.XKCDInfo {
 	return core.XKCDInfo{
 		ID:          info.ID,
 		URL:         info.URL,
 		Description: info.Title + " " + info.SafeTitle + " " + info.Transcript + " " + info.Alt,
-	}, nil
+		Metadata: core.Metadata{
+			Title:      info.Title,
+			Alt:        info.Alt,
+			Transcript: info.Transcript,
+			Link:       info.Link,
+			News:       info.News,
+			Published:  info.published(),
+		},
+	}
 }
@@ -9,11 +9,13 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"yadro.com/course/fakexkcd/server"
 	"yadro.com/course/update/core"
 )
 
@@ -29,30 +31,38 @@ func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 
 func TestNewClient(t *testing.T) {
 	t.Run("empty url", func(t *testing.T) {
-		_, err := NewClient("", 5*time.Second, logger)
+		_, err := NewClient("", 5*time.Second, Options{}, logger)
 		require.Error(t, err)
 	})
 
 	t.Run("valid url", func(t *testing.T) {
-		client, err := NewClient(clientURL, 5*time.Second, logger)
+		client, err := NewClient(clientURL, 5*time.Second, Options{}, logger)
 		require.NoError(t, err)
 		require.Equal(t, clientURL, client.url)
 	})
+
+	t.Run("negative retries", func(t *testing.T) {
+		_, err := NewClient(clientURL, 5*time.Second, Options{Retries: -1}, logger)
+		require.Error(t, err)
+	})
+
+	t.Run("retries without backoff", func(t *testing.T) {
+		_, err := NewClient(clientURL, 5*time.Second, Options{Retries: 3}, logger)
+		require.Error(t, err)
+	})
 }
 
-func TestClient_get(t *testing.T) {
+func TestClient_Get_Errors(t *testing.T) {
 	var requestErr error = fmt.Errorf("network error")
 
 	tests := []struct {
 		name         string
-		targetURL    string
 		roundTripFn  roundTripFunc
 		expectedInfo core.XKCDInfo
 		expectedErr  error
 	}{
 		{
-			name:      "success",
-			targetURL: clientURL,
+			name: "success",
 			roundTripFn: func(req *http.Request) (*http.Response, error) {
 				info := ComicsInfo{
 					ID:         101,
@@ -72,12 +82,12 @@ func TestClient_get(t *testing.T) {
 				ID:          101,
 				URL:         clientURL + "/img.png",
 				Description: "Title SafeTitle Transcript Alt",
+				Metadata:    core.Metadata{Title: "Title", Alt: "Alt", Transcript: "Transcript"},
 			},
 			expectedErr: nil,
 		},
 		{
-			name:      "not found",
-			targetURL: clientURL + "/notfound",
+			name: "not found",
 			roundTripFn: func(req *http.Request) (*http.Response, error) {
 				return &http.Response{
 					StatusCode: http.StatusNotFound,
@@ -88,8 +98,7 @@ func TestClient_get(t *testing.T) {
 			expectedErr:  core.ErrNotFound,
 		},
 		{
-			name:      "invalid json",
-			targetURL: clientURL + "/invalidjson",
+			name: "invalid json",
 			roundTripFn: func(req *http.Request) (*http.Response, error) {
 				return &http.Response{
 					StatusCode: http.StatusOK,
@@ -100,8 +109,7 @@ func TestClient_get(t *testing.T) {
 			expectedErr:  errors.New("failed to decode comics"),
 		},
 		{
-			name:      "request error",
-			targetURL: clientURL + "/requesterror",
+			name: "request error",
 			roundTripFn: func(req *http.Request) (*http.Response, error) {
 				return nil, requestErr
 			},
@@ -124,7 +132,7 @@ func TestClient_get(t *testing.T) {
 				url:    clientURL,
 			}
 
-			info, err := c.get(context.Background(), tc.targetURL)
+			info, err := c.Get(context.Background(), 101)
 
 			if tc.name == "invalid json" {
 				require.Error(t, err)
@@ -197,6 +205,11 @@ func TestClient_Get(t *testing.T) {
 		SafeTitle:  "SafeTitle",
 		Transcript: "Transcript",
 		Alt:        "Alt",
+		Link:       "https://example.com",
+		News:       "News",
+		Day:        "2",
+		Month:      "1",
+		Year:       "2006",
 	}
 	bodyBytes, _ := json.Marshal(info)
 
@@ -204,6 +217,14 @@ func TestClient_Get(t *testing.T) {
 		ID:          101,
 		URL:         clientURL + "/img.png",
 		Description: "Title" + " " + "SafeTitle" + " " + "Transcript" + " " + "Alt",
+		Metadata: core.Metadata{
+			Title:      "Title",
+			Alt:        "Alt",
+			Transcript: "Transcript",
+			Link:       "https://example.com",
+			News:       "News",
+			Published:  time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
 	}
 
 	client := Client{
@@ -225,3 +246,201 @@ func TestClient_Get(t *testing.T) {
 	require.Equal(t, expected, res)
 
 }
+
+func newRetryClient(t *testing.T, opts Options, fn roundTripFunc) *Client {
+	t.Helper()
+
+	client, err := NewClient(clientURL, 5*time.Second, opts, logger)
+	require.NoError(t, err)
+	client.client.Transport = fn
+	return client
+}
+
+func comicsResponse(t *testing.T, id int) *http.Response {
+	t.Helper()
+
+	body, err := json.Marshal(ComicsInfo{ID: id})
+	require.NoError(t, err)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+func TestClient_Get_Retries(t *testing.T) {
+	calls := 0
+	client := newRetryClient(t, Options{Retries: 3, Backoff: time.Millisecond}, func(req *http.Request) (*http.Response, error) {
+		calls++
+		switch calls {
+		case 1:
+			return nil, errors.New("connection reset")
+		case 2:
+			return &http.Response{
+				StatusCode: http.StatusBadGateway,
+				Body:       io.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		default:
+			return comicsResponse(t, 7), nil
+		}
+	})
+
+	info, err := client.Get(context.Background(), 7)
+	require.NoError(t, err)
+	require.Equal(t, 7, info.ID)
+	require.Equal(t, 3, calls)
+	require.Equal(t, core.XKCDState{Retries: 2, Breaker: core.BreakerClosed}, client.State())
+}
+
+func TestClient_Get_RetriesExhausted(t *testing.T) {
+	calls := 0
+	client := newRetryClient(t, Options{Retries: 2, Backoff: time.Millisecond}, func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       io.NopCloser(bytes.NewReader(nil)),
+		}, nil
+	})
+
+	_, err := client.Get(context.Background(), 7)
+	require.Error(t, err)
+	require.Equal(t, 3, calls)
+}
+
+func TestClient_Get_NotFoundIsNotRetried(t *testing.T) {
+	calls := 0
+	client := newRetryClient(t, Options{Retries: 3, Backoff: time.Millisecond}, func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(bytes.NewReader(nil)),
+		}, nil
+	})
+
+	_, err := client.Get(context.Background(), 404)
+	require.ErrorIs(t, err, core.ErrNotFound)
+	require.Equal(t, 1, calls)
+}
+
+func TestClient_Get_RetryAfter(t *testing.T) {
+	t.Run("respected", func(t *testing.T) {
+		calls := 0
+		var first time.Time
+		client := newRetryClient(t, Options{Retries: 1, Backoff: time.Millisecond, MaxBackoff: 5 * time.Second},
+			func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					first = time.Now()
+					return &http.Response{
+						StatusCode: http.StatusTooManyRequests,
+						Header:     http.Header{"Retry-After": []string{"1"}},
+						Body:       io.NopCloser(bytes.NewReader(nil)),
+					}, nil
+				}
+				require.GreaterOrEqual(t, time.Since(first), time.Second)
+				return comicsResponse(t, 1), nil
+			})
+
+		_, err := client.Get(context.Background(), 1)
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("longer than max backoff", func(t *testing.T) {
+		calls := 0
+		client := newRetryClient(t, Options{Retries: 3, Backoff: time.Millisecond, MaxBackoff: time.Second},
+			func(req *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Header:     http.Header{"Retry-After": []string{"120"}},
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			})
+
+		_, err := client.Get(context.Background(), 1)
+		require.Error(t, err)
+		require.Equal(t, 1, calls)
+	})
+}
+
+func TestClient_Get_BreakerOpens(t *testing.T) {
+	calls := 0
+	client := newRetryClient(t, Options{BreakerThreshold: 2, BreakerCooldown: time.Hour},
+		func(req *http.Request) (*http.Response, error) {
+			calls++
+			return nil, errors.New("connection refused")
+		})
+
+	for range 2 {
+		_, err := client.Get(context.Background(), 1)
+		require.Error(t, err)
+	}
+	require.Equal(t, core.BreakerOpen, client.State().Breaker)
+
+	_, err := client.Get(context.Background(), 1)
+	require.ErrorIs(t, err, core.ErrUpstreamUnavailable)
+	require.Equal(t, 2, calls)
+}
+
+func TestRetryAfter(t *testing.T) {
+	require.Equal(t, time.Duration(0), retryAfter(""))
+	require.Equal(t, time.Duration(0), retryAfter("soon"))
+	require.Equal(t, 3*time.Second, retryAfter("3"))
+
+	at := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	require.InDelta(t, time.Minute, retryAfter(at), float64(2*time.Second))
+}
+
+func TestComicsInfo_Published(t *testing.T) {
+	require.Equal(t, time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC),
+		ComicsInfo{Day: "2", Month: "1", Year: "2006"}.published())
+	require.True(t, ComicsInfo{}.published().IsZero())
+	require.True(t, ComicsInfo{Day: "2", Month: "jan", Year: "2006"}.published().IsZero())
+}
+
+func TestClient_Get_ThrottledSlowsDown(t *testing.T) {
+	calls := 0
+	client := newRetryClient(t, Options{Retries: 1, Backoff: time.Millisecond, MaxBackoff: time.Second, RPS: 100, Burst: 1},
+		func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+			return comicsResponse(t, 1), nil
+		})
+
+	_, err := client.Get(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+	// 429 вдвое снизил скорость, успешный повтор вернул только шаг
+	require.Equal(t, float64(50+100/recoverSteps), client.limiter.currentRate())
+}
+
+func TestClient_FakeXKCD(t *testing.T) {
+	fake, err := server.New(logger, nil, server.Options{Latest: 10, Missing: []int{4}, ErrorEvery: 3, ErrorBurst: 1})
+	require.NoError(t, err)
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	client, err := NewClient(ts.URL, 5*time.Second, Options{Retries: 2, Backoff: time.Millisecond}, logger)
+	require.NoError(t, err)
+
+	// каждый третий запрос получает 503, повторы его переживают
+	for id := 1; id <= 3; id++ {
+		info, err := client.Get(context.Background(), id)
+		require.NoError(t, err)
+		require.Equal(t, id, info.ID)
+	}
+
+	_, err = client.Get(context.Background(), 4)
+	require.ErrorIs(t, err, core.ErrNotFound)
+
+	fake.Publish()
+	last, err := client.LastID(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 11, last)
+	require.Positive(t, client.State().Retries)
+}
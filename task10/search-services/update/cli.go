@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"yadro.com/course/update/adapters/archive"
+	"yadro.com/course/update/core"
+)
+
+// runCommand выполняет разовую команду вместо запуска сервера:
+//
+//	update [-config file] export [archive]  - выгрузить комиксы, по умолчанию в stdout
+//	update [-config file] import [archive]  - загрузить комиксы, по умолчанию из stdin
+//
+// Архив - gzip-сжатый NDJSON, по комиксу на строку.
+func runCommand(log *slog.Logger, updater *core.Service, command, path string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch command {
+	case "export":
+		return exportArchive(ctx, updater, path)
+	case "import":
+		result, err := importArchive(ctx, updater, path)
+		if err != nil {
+			return err
+		}
+		log.Info("import finished", "imported", result.Imported, "skipped", result.Skipped)
+		for _, msg := range result.Errors {
+			log.Warn("record skipped", "error", msg)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q, expected export or import", command)
+	}
+}
+
+func exportArchive(ctx context.Context, updater *core.Service, path string) error {
+	if path == "" || path == "-" {
+		return writeArchive(ctx, updater, os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = writeArchive(ctx, updater, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	// недописанный архив хуже отсутствующего
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+func writeArchive(ctx context.Context, updater *core.Service, out io.Writer) error {
+	buf := bufio.NewWriter(out)
+	w := archive.NewWriter(buf)
+
+	if err := updater.Export(ctx, w.Write); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+func importArchive(ctx context.Context, updater *core.Service, path string) (core.ImportResult, error) {
+	in := io.Reader(os.Stdin)
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return core.ImportResult{}, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	r, err := archive.NewReader(bufio.NewReader(in))
+	if err != nil {
+		return core.ImportResult{}, err
+	}
+	defer r.Close()
+
+	return updater.Import(ctx, r.Next)
+}
@@ -1,24 +1,80 @@
 package config
 
 import (
+	"os"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type XKCD struct {
-	URL         string        `yaml:"url" env:"XKCD_URL" env-default:"xkcd.com"`
-	Concurrency int           `yaml:"concurrency" env:"XKCD_CONCURRENCY" env-default:"1"`
-	Timeout     time.Duration `yaml:"timeout" env:"XKCD_TIMEOUT" env-default:"10s"`
-	CheckPeriod time.Duration `yaml:"check_period" env:"XKCD_CHECK_PERIOD" env-default:"1h"`
+	URL              string        `yaml:"url" env:"XKCD_URL" env-default:"xkcd.com"`
+	Concurrency      int           `yaml:"concurrency" env:"XKCD_CONCURRENCY" env-default:"1"`
+	Timeout          time.Duration `yaml:"timeout" env:"XKCD_TIMEOUT" env-default:"10s"`
+	CheckPeriod      time.Duration `yaml:"check_period" env:"XKCD_CHECK_PERIOD" env-default:"1h"`
+	Retries          int           `yaml:"retries" env:"XKCD_RETRIES" env-default:"3"`
+	RetryBackoff     time.Duration `yaml:"retry_backoff" env:"XKCD_RETRY_BACKOFF" env-default:"500ms"`
+	RetryMaxBackoff  time.Duration `yaml:"retry_max_backoff" env:"XKCD_RETRY_MAX_BACKOFF" env-default:"30s"`
+	BreakerThreshold int           `yaml:"breaker_threshold" env:"XKCD_BREAKER_THRESHOLD" env-default:"20"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown" env:"XKCD_BREAKER_COOLDOWN" env-default:"1m"`
+	// RPS - предел запросов в секунду на источник, 0 - без ограничения.
+	// Перепроверка кэша тоже идёт через предел, поэтому полный обход займёт
+	// не меньше (число комиксов / RPS) секунд
+	RPS   float64 `yaml:"rps" env:"XKCD_RPS" env-default:"0"`
+	Burst int     `yaml:"burst" env:"XKCD_BURST" env-default:"5"`
+	// CacheDir - дисковый кэш JSON комиксов с подкаталогом на каждый upstream,
+	// пустой - не кэшировать на диске
+	CacheDir string `yaml:"cache_dir" env:"XKCD_CACHE_DIR"`
+}
+
+const (
+	SourceXKCD = "xkcd"
+	SourceDir  = "dir"
+)
+
+// Source - источник комиксов: xkcd-совместимый архив по url или каталог
+// JSON-файлов по path. Номера комиксов источника сдвигаются в БД на id_offset.
+type Source struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	URL      string `yaml:"url"`
+	Path     string `yaml:"path"`
+	IDOffset int    `yaml:"id_offset"`
+}
+
+type Batch struct {
+	Size          int           `yaml:"size" env:"BATCH_SIZE" env-default:"100"`
+	FlushInterval time.Duration `yaml:"flush_interval" env:"BATCH_FLUSH_INTERVAL" env-default:"1s"`
+}
+
+// Pipeline - воркеры и размер входного буфера стадий конвейера обновления.
+// Воркеров загрузки задаёт xkcd.concurrency, 0 воркеров norm и add - столько же.
+type Pipeline struct {
+	FetchBuffer int `yaml:"fetch_buffer" env:"PIPELINE_FETCH_BUFFER" env-default:"10"`
+	NormWorkers int `yaml:"norm_workers" env:"PIPELINE_NORM_WORKERS"`
+	NormBuffer  int `yaml:"norm_buffer" env:"PIPELINE_NORM_BUFFER" env-default:"10"`
+	AddWorkers  int `yaml:"add_workers" env:"PIPELINE_ADD_WORKERS"`
+	AddBuffer   int `yaml:"add_buffer" env:"PIPELINE_ADD_BUFFER" env-default:"100"`
+}
+
+// Snapshots - сколько снимков, создаваемых перед Drop, хранить; 0 - не создавать
+type Snapshots struct {
+	Keep int `yaml:"keep" env:"SNAPSHOTS_KEEP" env-default:"3"`
 }
 
 type Config struct {
 	LogLevel     string `yaml:"log_level" env:"LOG_LEVEL" env-default:"DEBUG"`
 	Address      string `yaml:"update_address" env:"UPDATE_ADDRESS" env-default:"localhost:80"`
 	XKCD         `yaml:"xkcd"`
-	DBAddress    string `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
-	WordsAddress string `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:81"`
+	Batch        Batch     `yaml:"batch"`
+	Pipeline     Pipeline  `yaml:"pipeline"`
+	Snapshots    Snapshots `yaml:"snapshots"`
+	Sources      []Source  `yaml:"sources"`
+	DBAddress    string    `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
+	WordsAddress string    `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:81"`
+	// Replica - имя реплики в аренде обновления, по умолчанию имя хоста.
+	// Должно быть уникальным: реплика с тем же именем перехватывает аренду
+	Replica string `yaml:"replica" env:"REPLICA"`
 }
 
 func MustLoad(configPath string) Config {
@@ -26,5 +82,31 @@ func MustLoad(configPath string) Config {
 	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
 		panic(err)
 	}
+
+	// без явного списка источников индексируем только xkcd
+	if len(cfg.Sources) == 0 {
+		cfg.Sources = []Source{{Name: SourceXKCD, Type: SourceXKCD}}
+	}
+	for i := range cfg.Sources {
+		if cfg.Sources[i].Type == SourceXKCD && cfg.Sources[i].URL == "" {
+			cfg.Sources[i].URL = cfg.XKCD.URL
+		}
+	}
+
+	if cfg.Pipeline.NormWorkers == 0 {
+		cfg.Pipeline.NormWorkers = cfg.XKCD.Concurrency
+	}
+	if cfg.Pipeline.AddWorkers == 0 {
+		cfg.Pipeline.AddWorkers = cfg.XKCD.Concurrency
+	}
+
+	if cfg.Replica == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			panic(err)
+		}
+		cfg.Replica = hostname
+	}
+
 	return cfg
 }
@@ -21,8 +21,27 @@ func TestMustLoad(t *testing.T) {
 	assert.NotEmpty(t, cfg.LogLevel)
 	assert.NotEmpty(t, cfg.WordsAddress)
 	assert.NotEmpty(t, cfg.URL)
+	assert.NotEmpty(t, cfg.Replica)
 
 	assert.Greater(t, cfg.Concurrency, 0)
 	assert.Greater(t, cfg.CheckPeriod, int64(0))
 	assert.Greater(t, cfg.Timeout, int64(0))
+	assert.GreaterOrEqual(t, cfg.Retries, 0)
+	assert.Greater(t, cfg.RetryBackoff, int64(0))
+	assert.Greater(t, cfg.BreakerCooldown, int64(0))
+	assert.GreaterOrEqual(t, cfg.RPS, float64(0))
+	assert.Greater(t, cfg.Batch.Size, 0)
+	assert.Greater(t, cfg.Batch.FlushInterval, int64(0))
+	assert.Greater(t, cfg.Pipeline.NormWorkers, 0)
+	assert.Greater(t, cfg.Pipeline.AddWorkers, 0)
+	assert.GreaterOrEqual(t, cfg.Pipeline.AddBuffer, 0)
+	assert.GreaterOrEqual(t, cfg.Snapshots.Keep, 0)
+
+	assert.NotEmpty(t, cfg.Sources)
+	for _, source := range cfg.Sources {
+		assert.NotEmpty(t, source.Name)
+		if source.Type == SourceXKCD {
+			assert.NotEmpty(t, source.URL)
+		}
+	}
 }
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// сколько ошибок проверки попадает в отчёт об импорте
+const maxImportErrors = 10
+
+// Export отдаёт все сохранённые комиксы по возрастанию ID
+func (s *Service) Export(ctx context.Context, fn func(Comics) error) error {
+	if err := s.db.Walk(ctx, fn); err != nil {
+		s.log.Error("failed to export comics", "error", err)
+		return err
+	}
+	return nil
+}
+
+// Import сохраняет комиксы пачками до io.EOF от next. Комиксы перезаписываются
+// по comics_id безусловно, поэтому после импорта в БД лежат ровно записи архива,
+// а повторный импорт того же архива ничего не меняет.
+// Записи с ErrBadArguments и не прошедшие проверку пропускаются, остальные ошибки прерывают импорт.
+func (s *Service) Import(ctx context.Context, next func() (Comics, error)) (ImportResult, error) {
+	// импорт не смешиваем с обновлением
+	unlock, _, err := s.lock(ctx)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer unlock()
+
+	var result ImportResult
+	defer func() {
+		if result.Imported > 0 {
+			s.changed(context.WithoutCancel(ctx), "import")
+		}
+	}()
+
+	skip := func(err error) {
+		result.Skipped++
+		if len(result.Errors) < maxImportErrors {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+
+	batch := make([]Comics, 0, s.batch.Size)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := s.db.ImportBatch(ctx, batch); err != nil {
+			return err
+		}
+		result.Imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	// один ID дважды в одном INSERT ... ON CONFLICT DO UPDATE postgres не примет
+	seen := make(map[int]struct{})
+
+	for {
+		comics, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, ErrBadArguments) {
+			skip(err)
+			continue
+		}
+		if err != nil {
+			s.log.Error("failed to read comics for import", "error", err)
+			return result, err
+		}
+
+		if err := s.validate(&comics); err != nil {
+			skip(err)
+			continue
+		}
+		if _, ok := seen[comics.ID]; ok {
+			skip(fmt.Errorf("%w: comics %d is duplicated", ErrBadArguments, comics.ID))
+			continue
+		}
+		seen[comics.ID] = struct{}{}
+
+		batch = append(batch, comics)
+		if len(batch) >= s.batch.Size {
+			if err := flush(); err != nil {
+				s.log.Error("failed to import comics batch", "error", err)
+				return result, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		s.log.Error("failed to import comics batch", "error", err)
+		return result, err
+	}
+
+	s.log.Info("comics imported", "imported", result.Imported, "skipped", result.Skipped)
+	return result, nil
+}
+
+// validate проверяет запись архива, у записи без источника он восстанавливается по ID
+func (s *Service) validate(comics *Comics) error {
+	if comics.ID < 1 {
+		return fmt.Errorf("%w: wrong comics id %d", ErrBadArguments, comics.ID)
+	}
+	if comics.URL == "" {
+		return fmt.Errorf("%w: comics %d has no url", ErrBadArguments, comics.ID)
+	}
+	if comics.Source == "" {
+		name, _, _, ok := s.sources.Lookup(comics.ID)
+		if !ok {
+			return fmt.Errorf("%w: no source for comics %d", ErrBadArguments, comics.ID)
+		}
+		comics.Source = name
+	}
+	return nil
+}
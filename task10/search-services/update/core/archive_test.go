@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// records превращает срез в источник записей для Import
+func records(items ...any) func() (Comics, error) {
+	return func() (Comics, error) {
+		if len(items) == 0 {
+			return Comics{}, io.EOF
+		}
+		item := items[0]
+		items = items[1:]
+		if err, ok := item.(error); ok {
+			return Comics{}, err
+		}
+		return item.(Comics), nil
+	}
+}
+
+func TestExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	db.EXPECT().Walk(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(Comics) error) error {
+			for _, comics := range []Comics{{ID: 1}, {ID: 2}} {
+				if err := fn(comics); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	var exported []int
+	err = svc.Export(context.Background(), func(comics Comics) error {
+		exported = append(exported, comics.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, exported)
+
+	expected := errors.New("db error")
+	db.EXPECT().Walk(gomock.Any(), gomock.Any()).Return(expected)
+	require.ErrorIs(t, svc.Export(context.Background(), func(Comics) error { return nil }), expected)
+}
+
+func TestImport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1),
+		BatchOptions{Size: 2, FlushInterval: time.Second}, 0, nil)
+	require.NoError(t, err)
+	db.EXPECT().NotifyChanged(gomock.Any(), "import").Return(nil)
+
+	gomock.InOrder(
+		db.EXPECT().ImportBatch(gomock.Any(), []Comics{
+			{ID: 1, URL: "url1", Source: "xkcd"},
+			{ID: 2, URL: "url2", Source: "local"},
+		}).Return(nil),
+		db.EXPECT().ImportBatch(gomock.Any(), []Comics{{ID: 3, URL: "url3", Source: "xkcd"}}).Return(nil),
+	)
+
+	result, err := svc.Import(context.Background(), records(
+		Comics{ID: 1, URL: "url1"},
+		Comics{ID: 2, URL: "url2", Source: "local"},
+		fmt.Errorf("line 3: %w", ErrBadArguments),
+		Comics{ID: 4},
+		Comics{ID: 1, URL: "url1"},
+		Comics{ID: 3, URL: "url3"},
+	))
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Imported)
+	require.Equal(t, 3, result.Skipped)
+	require.Len(t, result.Errors, 3)
+}
+
+func TestImport_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	// ошибка чтения архива прерывает импорт
+	expected := errors.New("unexpected EOF")
+	_, err = svc.Import(context.Background(), records(expected))
+	require.ErrorIs(t, err, expected)
+
+	expected = errors.New("db error")
+	db.EXPECT().ImportBatch(gomock.Any(), gomock.Any()).Return(expected)
+	_, err = svc.Import(context.Background(), records(Comics{ID: 1, URL: "url"}))
+	require.ErrorIs(t, err, expected)
+
+	// во время обновления импорт не запускается
+	svc.mx.Lock()
+	defer svc.mx.Unlock()
+	_, err = svc.Import(context.Background(), records())
+	require.ErrorIs(t, err, ErrAlreadyExists)
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// checkpointInterval - как часто отметка обхода сохраняется во время запуска
+const checkpointInterval = time.Second
+
+// target - что обходит Update: новая отметка или незакрытая для Resume
+func (s *Service) target(ctx context.Context, opts UpdateOptions) (Checkpoint, error) {
+	if opts.Resume {
+		cp, err := s.db.Checkpoint(ctx)
+		if err != nil {
+			s.log.Error("failed to retrieve interrupted update checkpoint", "error", err)
+			return Checkpoint{}, err
+		}
+		return cp, nil
+	}
+
+	ranges, err := s.ranges(ctx)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	cp := Checkpoint{Mode: ModeUpdate, Ranges: ranges, NewestFirst: opts.NewestFirst}
+	if opts.Reconcile {
+		cp.Mode = ModeReconcile
+	}
+	return cp, nil
+}
+
+// pending - ещё не пройденные ID отметки в порядке обхода. Упавшие до
+// Committed комиксы не повторяются, для них есть RetryFailed.
+func (s *Service) pending(ctx context.Context, cp Checkpoint) ([]int, error) {
+	var ids []int
+	var err error
+	if cp.Mode == ModeReconcile {
+		ids, err = s.reconcileIn(ctx, cp.Ranges)
+	} else {
+		ids, err = s.missingIn(ctx, cp.Ranges)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cp.NewestFirst {
+		slices.Reverse(ids)
+	}
+	return slices.DeleteFunc(ids, func(id int) bool { return !cp.after(id) }), nil
+}
+
+// tracker двигает Committed, хотя воркеры обрабатывают комиксы не по порядку
+type tracker struct {
+	mx      sync.Mutex
+	cp      Checkpoint
+	ids     []int
+	index   map[int]int
+	handled []bool
+	next    int
+	dirty   bool
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newTracker(cp Checkpoint, ids []int) *tracker {
+	t := &tracker{
+		cp:      cp,
+		ids:     ids,
+		index:   make(map[int]int, len(ids)),
+		handled: make([]bool, len(ids)),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	for i, id := range ids {
+		t.index[id] = i
+	}
+	return t
+}
+
+// commit отмечает комикс обработанным, nil - запуск без отметки
+func (t *tracker) commit(id int) {
+	if t == nil {
+		return
+	}
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	i, ok := t.index[id]
+	if !ok {
+		return
+	}
+	t.handled[i] = true
+	for t.next < len(t.ids) && t.handled[t.next] {
+		t.next++
+	}
+	if t.next > 0 && t.cp.Committed != t.ids[t.next-1] {
+		t.cp.Committed = t.ids[t.next-1]
+		t.dirty = true
+	}
+}
+
+// committed отдаёт Committed, если он сдвинулся с прошлого вызова
+func (t *tracker) committed() (int, bool) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	dirty := t.dirty
+	t.dirty = false
+	return t.cp.Committed, dirty
+}
+
+// startCheckpoint сохраняет новую отметку и дальше пишет её раз в
+// checkpointInterval. Без сохранённой отметки обновление идёт, но продолжить
+// его после падения будет нельзя.
+func (s *Service) startCheckpoint(ctx context.Context, cp Checkpoint, ids []int) *tracker {
+	if cp.ID == 0 {
+		id, err := s.db.StartCheckpoint(ctx, cp)
+		if err != nil {
+			s.log.Warn("failed to save update checkpoint, run cannot be resumed", "error", err)
+			return nil
+		}
+		cp.ID = id
+	}
+
+	t := newTracker(cp, ids)
+	go func() {
+		defer close(t.stopped)
+
+		ticker := time.NewTicker(checkpointInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+				s.saveCheckpoint(ctx, t)
+			}
+		}
+	}()
+	return t
+}
+
+func (s *Service) saveCheckpoint(ctx context.Context, t *tracker) {
+	committed, dirty := t.committed()
+	if !dirty {
+		return
+	}
+	if err := s.db.SaveCheckpoint(ctx, t.cp.ID, committed); err != nil {
+		s.log.Warn("failed to save update checkpoint", "checkpoint", t.cp.ID, "error", err)
+	}
+}
+
+// finishCheckpoint сохраняет итоговую отметку и закрывает её, если запуск
+// завершился или был отменён
+func (s *Service) finishCheckpoint(ctx context.Context, t *tracker, err error) {
+	if t == nil {
+		return
+	}
+	close(t.stop)
+	<-t.stopped
+
+	s.saveCheckpoint(ctx, t)
+	if err != nil && !errors.Is(err, ErrCancelled) {
+		s.log.Info("update can be resumed", "checkpoint", t.cp.ID, "committed", t.cp.Committed)
+		return
+	}
+	if err := s.db.FinishCheckpoint(ctx, t.cp.ID); err != nil {
+		s.log.Warn("failed to close update checkpoint", "checkpoint", t.cp.ID, "error", err)
+	}
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTracker_Commit(t *testing.T) {
+	tr := newTracker(Checkpoint{NewestFirst: true}, []int{9, 7, 5, 3})
+
+	// обработан 7, но 9 ещё нет - отметка стоит на месте
+	tr.commit(7)
+	_, dirty := tr.committed()
+	require.False(t, dirty)
+
+	tr.commit(9)
+	committed, dirty := tr.committed()
+	require.True(t, dirty)
+	require.Equal(t, 7, committed)
+
+	_, dirty = tr.committed()
+	require.False(t, dirty)
+
+	// чужие ID отметку не двигают
+	tr.commit(100)
+	tr.commit(3)
+	tr.commit(5)
+	committed, _ = tr.committed()
+	require.Equal(t, 3, committed)
+
+	var none *tracker
+	none.commit(1)
+}
+
+func TestCheckpoint_After(t *testing.T) {
+	require.True(t, Checkpoint{}.after(1))
+	require.True(t, Checkpoint{Committed: 5}.after(6))
+	require.False(t, Checkpoint{Committed: 5}.after(5))
+	require.True(t, Checkpoint{Committed: 5, NewestFirst: true}.after(4))
+	require.False(t, Checkpoint{Committed: 5, NewestFirst: true}.after(6))
+}
+
+func TestUpdate_NewestFirst(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	xkcd.EXPECT().LastID(gomock.Any()).Return(3, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1, 3).Return([]int{1, 2, 3}, nil)
+	db.EXPECT().StartCheckpoint(gomock.Any(), Checkpoint{
+		Mode:        ModeUpdate,
+		Ranges:      []SourceRange{{Source: "xkcd", From: 1, To: 3}},
+		NewestFirst: true,
+	}).Return(int64(4), nil)
+
+	// один воркер - комиксы идут строго от новых к старым
+	gomock.InOrder(
+		xkcd.EXPECT().Get(gomock.Any(), 3).Return(XKCDInfo{ID: 3, Description: "three"}, nil),
+		xkcd.EXPECT().Get(gomock.Any(), 2).Return(XKCDInfo{}, errors.New("xkcd error")),
+		xkcd.EXPECT().Get(gomock.Any(), 1).Return(XKCDInfo{ID: 1, Description: "one"}, nil),
+	)
+	words.EXPECT().Norm(gomock.Any(), gomock.Any()).Return([]string{"word"}, nil).Times(2)
+	db.EXPECT().AddBatch(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	db.EXPECT().AddFailure(gomock.Any(), gomock.Any()).Return(nil)
+	db.EXPECT().NotifyChanged(gomock.Any(), "update").Return(nil)
+	expectRun(db, ModeUpdate, RunCompleted)
+
+	// упавший комикс тоже пройден, отметка доходит до самого старого
+	db.EXPECT().SaveCheckpoint(gomock.Any(), int64(4), 1).Return(nil)
+	db.EXPECT().FinishCheckpoint(gomock.Any(), int64(4)).Return(nil)
+
+	require.NoError(t, svc.Update(context.Background(), UpdateOptions{NewestFirst: true}))
+}
+
+func TestUpdate_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	// до падения пройдены 1 и 2, 2 упал и в БД его нет
+	cp := Checkpoint{
+		ID:        7,
+		Mode:      ModeReconcile,
+		Ranges:    []SourceRange{{Source: "xkcd", From: 1, To: 5}},
+		Committed: 2,
+	}
+	db.EXPECT().Checkpoint(gomock.Any()).Return(cp, nil).Times(2)
+
+	// источник не спрашиваем: диапазон берётся из отметки
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	db.EXPECT().IDs(gomock.Any()).Return([]int{1, 3}, nil)
+	db.EXPECT().Tombstones(gomock.Any()).Return(nil, nil)
+	xkcd.EXPECT().Get(gomock.Any(), 4).Return(XKCDInfo{ID: 4, Description: "four"}, nil)
+	xkcd.EXPECT().Get(gomock.Any(), 5).Return(XKCDInfo{}, ErrNotFound)
+	words.EXPECT().Norm(gomock.Any(), "four").Return([]string{"four"}, nil)
+	db.EXPECT().AddBatch(gomock.Any(), gomock.Any()).Return(nil)
+	db.EXPECT().AddTombstone(gomock.Any(), 5).Return(nil)
+	db.EXPECT().NotifyChanged(gomock.Any(), "reconcile").Return(nil)
+	db.EXPECT().AddRun(gomock.Any(), gomock.Cond(func(run UpdateRun) bool {
+		return run.Mode == ModeReconcile && run.Trigger == TriggerScheduled && run.Status == RunCompleted && run.Stored == 1
+	}))
+
+	db.EXPECT().SaveCheckpoint(gomock.Any(), int64(7), 5).Return(nil)
+	db.EXPECT().FinishCheckpoint(gomock.Any(), int64(7)).Return(nil)
+
+	require.NoError(t, svc.Update(context.Background(), UpdateOptions{Resume: true, Trigger: TriggerScheduled}))
+}
+
+func TestUpdate_ResumeNothing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	// нечего продолжать - запуск не начинается и в журнал не попадает
+	db.EXPECT().Checkpoint(gomock.Any()).Return(Checkpoint{}, ErrNotFound)
+	require.ErrorIs(t, svc.Update(context.Background(), UpdateOptions{Resume: true}), ErrNotFound)
+}
+
+func TestUpdate_CheckpointKeptOnFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	xkcd.EXPECT().LastID(gomock.Any()).Return(2, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1, 2).Return([]int{1, 2}, nil)
+	db.EXPECT().StartCheckpoint(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+	xkcd.EXPECT().Get(gomock.Any(), 1).Return(XKCDInfo{}, ErrUpstreamUnavailable)
+	expectRun(db, ModeUpdate, RunFailed)
+
+	// ничего не пройдено и отметка не закрыта - её продолжит Resume
+	require.ErrorIs(t, svc.Update(context.Background(), UpdateOptions{}), ErrUpstreamUnavailable)
+}
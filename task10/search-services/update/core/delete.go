@@ -0,0 +1,40 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Delete удаляет выбранные комиксы и возвращает, сколько их было удалено.
+// Журнал отсутствующих комиксов не трогается, поэтому следующее обновление
+// скачает удалённые комиксы заново - так исправляются неудачно проиндексированные.
+func (s *Service) Delete(ctx context.Context, opts DeleteOptions) (int, error) {
+	var (
+		deleted int
+		err     error
+	)
+	switch {
+	case len(opts.IDs) > 0 && (opts.From != 0 || opts.To != 0):
+		return 0, fmt.Errorf("%w: either ids or range expected", ErrBadArguments)
+	case len(opts.IDs) > 0:
+		for _, id := range opts.IDs {
+			if id < 1 {
+				return 0, fmt.Errorf("%w: wrong comics id %d", ErrBadArguments, id)
+			}
+		}
+		deleted, err = s.db.DeleteIDs(ctx, opts.IDs)
+	case opts.From >= 1 && opts.To >= opts.From:
+		deleted, err = s.db.DeleteRange(ctx, opts.From, opts.To)
+	default:
+		return 0, fmt.Errorf("%w: wrong range %d..%d", ErrBadArguments, opts.From, opts.To)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	s.log.Info("comics deleted", "deleted", deleted, "ids", opts.IDs, "from", opts.From, "to", opts.To)
+	if deleted > 0 {
+		s.changed(ctx, "delete")
+	}
+	return deleted, nil
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+	db.EXPECT().NotifyChanged(gomock.Any(), "delete").Return(nil).Times(2)
+
+	ctx := context.Background()
+
+	db.EXPECT().DeleteIDs(gomock.Any(), []int{3, 7}).Return(1, nil)
+	deleted, err := svc.Delete(ctx, DeleteOptions{IDs: []int{3, 7}})
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	db.EXPECT().DeleteRange(gomock.Any(), 10, 20).Return(11, nil)
+	deleted, err = svc.Delete(ctx, DeleteOptions{From: 10, To: 20})
+	require.NoError(t, err)
+	require.Equal(t, 11, deleted)
+
+	expected := errors.New("db error")
+	db.EXPECT().DeleteRange(gomock.Any(), 5, 5).Return(0, expected)
+	_, err = svc.Delete(ctx, DeleteOptions{From: 5, To: 5})
+	require.ErrorIs(t, err, expected)
+}
+
+func TestDelete_BadArguments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc, err := NewService(logger, NewMockDB(ctrl), single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	for name, opts := range map[string]DeleteOptions{
+		"nothing":        {},
+		"ids and range":  {IDs: []int{1}, From: 1, To: 2},
+		"wrong id":       {IDs: []int{1, 0}},
+		"reversed range": {From: 20, To: 10},
+		"open range":     {From: 10},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.Delete(context.Background(), opts)
+			require.ErrorIs(t, err, ErrBadArguments)
+		})
+	}
+}
+
+func TestDelete_NotifyFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	// комиксы уже удалены, индекс догонит по таймеру
+	db.EXPECT().DeleteIDs(gomock.Any(), []int{3}).Return(1, nil)
+	db.EXPECT().NotifyChanged(gomock.Any(), "delete").Return(errors.New("db error"))
+
+	deleted, err := svc.Delete(context.Background(), DeleteOptions{IDs: []int{3}})
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	// ничего не удалено - и уведомлять не о чем
+	db.EXPECT().DeleteRange(gomock.Any(), 5, 6).Return(0, nil)
+	_, err = svc.Delete(context.Background(), DeleteOptions{From: 5, To: 6})
+	require.NoError(t, err)
+}
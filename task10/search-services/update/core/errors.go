@@ -5,3 +5,6 @@ import "errors"
 var ErrBadArguments = errors.New("arguments are not acceptable")
 var ErrAlreadyExists = errors.New("resource or task already exists")
 var ErrNotFound = errors.New("resource is not found")
+var ErrCancelled = errors.New("task is cancelled")
+var ErrUpstreamUnavailable = errors.New("upstream is unavailable")
+var ErrLeaseLost = errors.New("update lease is lost")
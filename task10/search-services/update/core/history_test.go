@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdate_RecordsRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+	expectCheckpoints(db)
+	db.EXPECT().NotifyChanged(gomock.Any(), "update").Return(nil)
+
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	xkcd.EXPECT().LastID(gomock.Any()).Return(3, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1, 3).Return([]int{1, 2, 3}, nil)
+
+	// 1 сохранён, 2 упал, 3 отсутствует в источнике
+	xkcd.EXPECT().Get(gomock.Any(), 1).Return(XKCDInfo{ID: 1, Description: "one"}, nil)
+	xkcd.EXPECT().Get(gomock.Any(), 2).Return(XKCDInfo{}, errors.New("xkcd error"))
+	xkcd.EXPECT().Get(gomock.Any(), 3).Return(XKCDInfo{}, ErrNotFound)
+	words.EXPECT().Norm(gomock.Any(), "one").Return([]string{"one"}, nil)
+	db.EXPECT().AddBatch(gomock.Any(), gomock.Any()).Return(nil)
+	db.EXPECT().AddFailure(gomock.Any(), gomock.Any()).Return(nil)
+	db.EXPECT().AddTombstone(gomock.Any(), 3).Return(nil)
+
+	var record UpdateRun
+	db.EXPECT().AddRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, run UpdateRun) error {
+		record = run
+		// журнал недоступен, но обновление всё равно успешно
+		return errors.New("db error")
+	})
+
+	require.NoError(t, svc.Update(context.Background(), UpdateOptions{Trigger: TriggerScheduled}))
+
+	require.Equal(t, ModeUpdate, record.Mode)
+	require.Equal(t, TriggerScheduled, record.Trigger)
+	require.Equal(t, RunCompleted, record.Status)
+	require.Equal(t, 1, record.Stored)
+	require.Equal(t, 1, record.Failed)
+	// отсутствующий комикс выпадает из плана, а не пропускается
+	require.Equal(t, 0, record.Skipped)
+	require.Empty(t, record.Error)
+	require.False(t, record.FinishedAt.Before(record.StartedAt))
+}
+
+func TestHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	runs := []UpdateRun{{ID: 2, Mode: ModeUpdate, Status: RunCompleted, StartedAt: time.Now()}}
+	db.EXPECT().Runs(gomock.Any(), 10, 20).Return(runs, 21, nil)
+
+	history, err := svc.History(context.Background(), HistoryOptions{Limit: 10, Offset: 20})
+	require.NoError(t, err)
+	require.Equal(t, History{Runs: runs, Total: 21}, history)
+
+	for _, opts := range []HistoryOptions{{}, {Limit: maxHistoryLimit + 1}, {Limit: 10, Offset: -1}} {
+		_, err := svc.History(context.Background(), opts)
+		require.ErrorIs(t, err, ErrBadArguments)
+	}
+}
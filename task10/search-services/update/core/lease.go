@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const defaultLeaseTTL = 30 * time.Second
+
+// lock захватывает право на запуск сначала внутри процесса, затем среди реплик.
+// Пока блокировка держится, аренда продлевается; lost закрывается, если аренду потеряли.
+func (s *Service) lock(ctx context.Context) (unlock func(), lost <-chan struct{}, err error) {
+	if !s.mx.TryLock() {
+		return nil, nil, ErrAlreadyExists
+	}
+	if s.lease == nil {
+		return s.mx.Unlock, nil, nil
+	}
+
+	ok, err := s.lease.Acquire(ctx, s.leaseTTL)
+	if err != nil {
+		s.mx.Unlock()
+		return nil, nil, fmt.Errorf("acquire update lease: %w", err)
+	}
+	if !ok {
+		s.mx.Unlock()
+		return nil, nil, ErrAlreadyExists
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	lostCh := make(chan struct{})
+	go s.heartbeat(context.WithoutCancel(ctx), stop, done, lostCh)
+
+	unlock = func() {
+		close(stop)
+		<-done
+		if err := s.lease.Release(context.WithoutCancel(ctx)); err != nil {
+			s.log.Error("failed to release update lease", "error", err)
+		}
+		s.mx.Unlock()
+	}
+	return unlock, lostCh, nil
+}
+
+func (s *Service) heartbeat(ctx context.Context, stop <-chan struct{}, done, lost chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.leaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		ok, err := s.lease.Renew(ctx, s.leaseTTL)
+		if err != nil {
+			// аренда ещё может быть жива, попробуем на следующем тике
+			s.log.Warn("failed to renew update lease", "error", err)
+			continue
+		}
+		if !ok {
+			s.log.Error("update lease is taken by another replica")
+			close(lost)
+			return
+		}
+	}
+}
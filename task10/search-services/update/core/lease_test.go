@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLease_HeldByAnotherReplica(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lease := NewMockLease(ctrl)
+	svc, err := NewService(logger, NewMockDB(ctrl), single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 0, lease)
+	require.NoError(t, err)
+
+	lease.EXPECT().Acquire(gomock.Any(), defaultLeaseTTL).Return(false, nil)
+
+	require.ErrorIs(t, svc.RetryFailed(context.Background()), ErrAlreadyExists)
+
+	// локальная блокировка отпущена
+	require.True(t, svc.mx.TryLock())
+	svc.mx.Unlock()
+}
+
+func TestLease_AcquireError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lease := NewMockLease(ctrl)
+	svc, err := NewService(logger, NewMockDB(ctrl), single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 0, lease)
+	require.NoError(t, err)
+
+	dbErr := errors.New("db error")
+	lease.EXPECT().Acquire(gomock.Any(), defaultLeaseTTL).Return(false, dbErr)
+
+	require.ErrorIs(t, svc.Restore(context.Background(), "drop-1"), dbErr)
+}
+
+func TestLease_AcquiredAndReleased(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	lease := NewMockLease(ctrl)
+	xkcd := NewMockSource(ctrl)
+	svc, err := NewService(logger, db, single(t, xkcd), NewMockWords(ctrl), pipeline(1), batch, 0, lease)
+	require.NoError(t, err)
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+
+	gomock.InOrder(
+		lease.EXPECT().Acquire(gomock.Any(), defaultLeaseTTL).Return(true, nil),
+		db.EXPECT().Failures(gomock.Any()).Return(nil, nil),
+		lease.EXPECT().Release(gomock.Any()).Return(nil),
+	)
+	expectRun(db, ModeRetry, RunCompleted)
+
+	require.NoError(t, svc.RetryFailed(context.Background()))
+}
+
+func TestLease_Lost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	lease := NewMockLease(ctrl)
+	svc, err := NewService(logger, db, single(t, xkcd), NewMockWords(ctrl), pipeline(1), batch, 0, lease)
+	require.NoError(t, err)
+	svc.leaseTTL = 30 * time.Millisecond
+
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	lease.EXPECT().Acquire(gomock.Any(), svc.leaseTTL).Return(true, nil)
+	db.EXPECT().Failures(gomock.Any()).Return([]Failure{{ID: 1}}, nil)
+	// загрузка висит, пока аренду не перехватят
+	xkcd.EXPECT().Get(gomock.Any(), 1).DoAndReturn(func(ctx context.Context, _ int) (XKCDInfo, error) {
+		<-ctx.Done()
+		return XKCDInfo{}, ctx.Err()
+	})
+	lease.EXPECT().Renew(gomock.Any(), svc.leaseTTL).Return(false, nil)
+	lease.EXPECT().Release(gomock.Any()).Return(nil)
+	expectRun(db, ModeRetry, RunFailed)
+
+	require.ErrorIs(t, svc.RetryFailed(context.Background()), ErrLeaseLost)
+}
+
+func TestStatus_RemoteReplica(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	xkcd := NewMockSource(ctrl)
+	lease := NewMockLease(ctrl)
+	svc, err := NewService(logger, NewMockDB(ctrl), single(t, xkcd), NewMockWords(ctrl), pipeline(1), batch, 0, lease)
+	require.NoError(t, err)
+
+	started := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	lease.EXPECT().Holder(gomock.Any()).Return(LeaseHolder{Replica: "update-2", AcquiredAt: started}, nil)
+
+	state := svc.Status(context.Background())
+	require.Equal(t, StatusRunning, state.Status)
+	require.Equal(t, "update-2", state.Replica)
+	require.Equal(t, started, state.Progress.StartedAt)
+
+	// держателя нет - сервис простаивает
+	lease.EXPECT().Holder(gomock.Any()).Return(LeaseHolder{}, nil)
+
+	state = svc.Status(context.Background())
+	require.Equal(t, StatusIdle, state.Status)
+	require.Empty(t, state.Replica)
+}
@@ -12,6 +12,7 @@ package core
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,20 +41,197 @@ func (m *MockUpdater) EXPECT() *MockUpdaterMockRecorder {
 	return m.recorder
 }
 
-// Drop mocks base method.
-func (m *MockUpdater) Drop(arg0 context.Context) error {
+// Cancel mocks base method.
+func (m *MockUpdater) Cancel(arg0 context.Context) (ServiceState, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Drop", arg0)
+	ret := m.ctrl.Call(m, "Cancel", arg0)
+	ret0, _ := ret[0].(ServiceState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockUpdaterMockRecorder) Cancel(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockUpdater)(nil).Cancel), arg0)
+}
+
+// Delete mocks base method.
+func (m *MockUpdater) Delete(arg0 context.Context, arg1 DeleteOptions) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUpdaterMockRecorder) Delete(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUpdater)(nil).Delete), arg0, arg1)
+}
+
+// DeleteSnapshot mocks base method.
+func (m *MockUpdater) DeleteSnapshot(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSnapshot", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// DeleteSnapshot indicates an expected call of DeleteSnapshot.
+func (mr *MockUpdaterMockRecorder) DeleteSnapshot(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSnapshot", reflect.TypeOf((*MockUpdater)(nil).DeleteSnapshot), arg0, arg1)
+}
+
+// Drop mocks base method.
+func (m *MockUpdater) Drop(arg0 context.Context) (Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drop", arg0)
+	ret0, _ := ret[0].(Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // Drop indicates an expected call of Drop.
 func (mr *MockUpdaterMockRecorder) Drop(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drop", reflect.TypeOf((*MockUpdater)(nil).Drop), arg0)
 }
 
+// Export mocks base method.
+func (m *MockUpdater) Export(arg0 context.Context, arg1 func(Comics) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockUpdaterMockRecorder) Export(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockUpdater)(nil).Export), arg0, arg1)
+}
+
+// Failures mocks base method.
+func (m *MockUpdater) Failures(arg0 context.Context) ([]Failure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Failures", arg0)
+	ret0, _ := ret[0].([]Failure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Failures indicates an expected call of Failures.
+func (mr *MockUpdaterMockRecorder) Failures(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Failures", reflect.TypeOf((*MockUpdater)(nil).Failures), arg0)
+}
+
+// History mocks base method.
+func (m *MockUpdater) History(arg0 context.Context, arg1 HistoryOptions) (History, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", arg0, arg1)
+	ret0, _ := ret[0].(History)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockUpdaterMockRecorder) History(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockUpdater)(nil).History), arg0, arg1)
+}
+
+// Import mocks base method.
+func (m *MockUpdater) Import(arg0 context.Context, arg1 func() (Comics, error)) (ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", arg0, arg1)
+	ret0, _ := ret[0].(ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockUpdaterMockRecorder) Import(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockUpdater)(nil).Import), arg0, arg1)
+}
+
+// Plan mocks base method.
+func (m *MockUpdater) Plan(arg0 context.Context, arg1 UpdateOptions) (UpdatePlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Plan", arg0, arg1)
+	ret0, _ := ret[0].(UpdatePlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Plan indicates an expected call of Plan.
+func (mr *MockUpdaterMockRecorder) Plan(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Plan", reflect.TypeOf((*MockUpdater)(nil).Plan), arg0, arg1)
+}
+
+// Refresh mocks base method.
+func (m *MockUpdater) Refresh(arg0 context.Context, arg1 RefreshOptions) (RefreshResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", arg0, arg1)
+	ret0, _ := ret[0].(RefreshResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockUpdaterMockRecorder) Refresh(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockUpdater)(nil).Refresh), arg0, arg1)
+}
+
+// Restore mocks base method.
+func (m *MockUpdater) Restore(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockUpdaterMockRecorder) Restore(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUpdater)(nil).Restore), arg0, arg1)
+}
+
+// RetryFailed mocks base method.
+func (m *MockUpdater) RetryFailed(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryFailed", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryFailed indicates an expected call of RetryFailed.
+func (mr *MockUpdaterMockRecorder) RetryFailed(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryFailed", reflect.TypeOf((*MockUpdater)(nil).RetryFailed), arg0)
+}
+
+// Snapshots mocks base method.
+func (m *MockUpdater) Snapshots(arg0 context.Context) ([]Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshots", arg0)
+	ret0, _ := ret[0].([]Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshots indicates an expected call of Snapshots.
+func (mr *MockUpdaterMockRecorder) Snapshots(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshots", reflect.TypeOf((*MockUpdater)(nil).Snapshots), arg0)
+}
+
 // Stats mocks base method.
 func (m *MockUpdater) Stats(arg0 context.Context) (ServiceStats, error) {
 	m.ctrl.T.Helper()
@@ -70,10 +248,10 @@ func (mr *MockUpdaterMockRecorder) Stats(arg0 any) *gomock.Call {
 }
 
 // Status mocks base method.
-func (m *MockUpdater) Status(arg0 context.Context) ServiceStatus {
+func (m *MockUpdater) Status(arg0 context.Context) ServiceState {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Status", arg0)
-	ret0, _ := ret[0].(ServiceStatus)
+	ret0, _ := ret[0].(ServiceState)
 	return ret0
 }
 
@@ -84,17 +262,70 @@ func (mr *MockUpdaterMockRecorder) Status(arg0 any) *gomock.Call {
 }
 
 // Update mocks base method.
-func (m *MockUpdater) Update(arg0 context.Context) error {
+func (m *MockUpdater) Update(arg0 context.Context, arg1 UpdateOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", arg0)
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockUpdaterMockRecorder) Update(arg0 any) *gomock.Call {
+func (mr *MockUpdaterMockRecorder) Update(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUpdater)(nil).Update), arg0, arg1)
+}
+
+// MockScheduler is a mock of Scheduler interface.
+type MockScheduler struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchedulerMockRecorder
+	isgomock struct{}
+}
+
+// MockSchedulerMockRecorder is the mock recorder for MockScheduler.
+type MockSchedulerMockRecorder struct {
+	mock *MockScheduler
+}
+
+// NewMockScheduler creates a new mock instance.
+func NewMockScheduler(ctrl *gomock.Controller) *MockScheduler {
+	mock := &MockScheduler{ctrl: ctrl}
+	mock.recorder = &MockSchedulerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduler) EXPECT() *MockSchedulerMockRecorder {
+	return m.recorder
+}
+
+// Schedule mocks base method.
+func (m *MockScheduler) Schedule(arg0 context.Context) Schedule {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Schedule", arg0)
+	ret0, _ := ret[0].(Schedule)
+	return ret0
+}
+
+// Schedule indicates an expected call of Schedule.
+func (mr *MockSchedulerMockRecorder) Schedule(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedule", reflect.TypeOf((*MockScheduler)(nil).Schedule), arg0)
+}
+
+// SetSchedule mocks base method.
+func (m *MockScheduler) SetSchedule(arg0 context.Context, arg1 ScheduleSettings) (Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSchedule", arg0, arg1)
+	ret0, _ := ret[0].(Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSchedule indicates an expected call of SetSchedule.
+func (mr *MockSchedulerMockRecorder) SetSchedule(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUpdater)(nil).Update), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSchedule", reflect.TypeOf((*MockScheduler)(nil).SetSchedule), arg0, arg1)
 }
 
 // MockDB is a mock of DB interface.
@@ -121,32 +352,178 @@ func (m *MockDB) EXPECT() *MockDBMockRecorder {
 	return m.recorder
 }
 
-// Add mocks base method.
-func (m *MockDB) Add(arg0 context.Context, arg1 Comics) error {
+// AddBatch mocks base method.
+func (m *MockDB) AddBatch(arg0 context.Context, arg1 []Comics) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Add", arg0, arg1)
+	ret := m.ctrl.Call(m, "AddBatch", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Add indicates an expected call of Add.
-func (mr *MockDBMockRecorder) Add(arg0, arg1 any) *gomock.Call {
+// AddBatch indicates an expected call of AddBatch.
+func (mr *MockDBMockRecorder) AddBatch(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockDB)(nil).Add), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBatch", reflect.TypeOf((*MockDB)(nil).AddBatch), arg0, arg1)
 }
 
-// Drop mocks base method.
-func (m *MockDB) Drop(arg0 context.Context) error {
+// AddFailure mocks base method.
+func (m *MockDB) AddFailure(arg0 context.Context, arg1 Failure) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Drop", arg0)
+	ret := m.ctrl.Call(m, "AddFailure", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFailure indicates an expected call of AddFailure.
+func (mr *MockDBMockRecorder) AddFailure(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFailure", reflect.TypeOf((*MockDB)(nil).AddFailure), arg0, arg1)
+}
+
+// AddRun mocks base method.
+func (m *MockDB) AddRun(arg0 context.Context, arg1 UpdateRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRun indicates an expected call of AddRun.
+func (mr *MockDBMockRecorder) AddRun(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRun", reflect.TypeOf((*MockDB)(nil).AddRun), arg0, arg1)
+}
+
+// AddTombstone mocks base method.
+func (m *MockDB) AddTombstone(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTombstone", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTombstone indicates an expected call of AddTombstone.
+func (mr *MockDBMockRecorder) AddTombstone(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTombstone", reflect.TypeOf((*MockDB)(nil).AddTombstone), arg0, arg1)
+}
+
+// Checkpoint mocks base method.
+func (m *MockDB) Checkpoint(arg0 context.Context) (Checkpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkpoint", arg0)
+	ret0, _ := ret[0].(Checkpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkpoint indicates an expected call of Checkpoint.
+func (mr *MockDBMockRecorder) Checkpoint(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkpoint", reflect.TypeOf((*MockDB)(nil).Checkpoint), arg0)
+}
+
+// DeleteIDs mocks base method.
+func (m *MockDB) DeleteIDs(ctx context.Context, ids []int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIDs", ctx, ids)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteIDs indicates an expected call of DeleteIDs.
+func (mr *MockDBMockRecorder) DeleteIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIDs", reflect.TypeOf((*MockDB)(nil).DeleteIDs), ctx, ids)
+}
+
+// DeleteRange mocks base method.
+func (m *MockDB) DeleteRange(ctx context.Context, from, to int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRange", ctx, from, to)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRange indicates an expected call of DeleteRange.
+func (mr *MockDBMockRecorder) DeleteRange(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRange", reflect.TypeOf((*MockDB)(nil).DeleteRange), ctx, from, to)
+}
+
+// DeleteSnapshot mocks base method.
+func (m *MockDB) DeleteSnapshot(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSnapshot", ctx, name)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// DeleteSnapshot indicates an expected call of DeleteSnapshot.
+func (mr *MockDBMockRecorder) DeleteSnapshot(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSnapshot", reflect.TypeOf((*MockDB)(nil).DeleteSnapshot), ctx, name)
+}
+
+// Drop mocks base method.
+func (m *MockDB) Drop(ctx context.Context, snapshot string) (Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drop", ctx, snapshot)
+	ret0, _ := ret[0].(Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // Drop indicates an expected call of Drop.
-func (mr *MockDBMockRecorder) Drop(arg0 any) *gomock.Call {
+func (mr *MockDBMockRecorder) Drop(ctx, snapshot any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drop", reflect.TypeOf((*MockDB)(nil).Drop), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drop", reflect.TypeOf((*MockDB)(nil).Drop), ctx, snapshot)
+}
+
+// Failures mocks base method.
+func (m *MockDB) Failures(arg0 context.Context) ([]Failure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Failures", arg0)
+	ret0, _ := ret[0].([]Failure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Failures indicates an expected call of Failures.
+func (mr *MockDBMockRecorder) Failures(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Failures", reflect.TypeOf((*MockDB)(nil).Failures), arg0)
+}
+
+// FinishCheckpoint mocks base method.
+func (m *MockDB) FinishCheckpoint(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishCheckpoint", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishCheckpoint indicates an expected call of FinishCheckpoint.
+func (mr *MockDBMockRecorder) FinishCheckpoint(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishCheckpoint", reflect.TypeOf((*MockDB)(nil).FinishCheckpoint), ctx, id)
+}
+
+// Hashes mocks base method.
+func (m *MockDB) Hashes(ctx context.Context, from, to int) (map[int]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hashes", ctx, from, to)
+	ret0, _ := ret[0].(map[int]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hashes indicates an expected call of Hashes.
+func (mr *MockDBMockRecorder) Hashes(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hashes", reflect.TypeOf((*MockDB)(nil).Hashes), ctx, from, to)
 }
 
 // IDs mocks base method.
@@ -164,6 +541,137 @@ func (mr *MockDBMockRecorder) IDs(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IDs", reflect.TypeOf((*MockDB)(nil).IDs), arg0)
 }
 
+// ImportBatch mocks base method.
+func (m *MockDB) ImportBatch(arg0 context.Context, arg1 []Comics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportBatch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportBatch indicates an expected call of ImportBatch.
+func (mr *MockDBMockRecorder) ImportBatch(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportBatch", reflect.TypeOf((*MockDB)(nil).ImportBatch), arg0, arg1)
+}
+
+// MissingIDs mocks base method.
+func (m *MockDB) MissingIDs(ctx context.Context, from, to int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MissingIDs", ctx, from, to)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MissingIDs indicates an expected call of MissingIDs.
+func (mr *MockDBMockRecorder) MissingIDs(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MissingIDs", reflect.TypeOf((*MockDB)(nil).MissingIDs), ctx, from, to)
+}
+
+// NotifyChanged mocks base method.
+func (m *MockDB) NotifyChanged(ctx context.Context, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyChanged", ctx, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyChanged indicates an expected call of NotifyChanged.
+func (mr *MockDBMockRecorder) NotifyChanged(ctx, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyChanged", reflect.TypeOf((*MockDB)(nil).NotifyChanged), ctx, reason)
+}
+
+// PruneSnapshots mocks base method.
+func (m *MockDB) PruneSnapshots(ctx context.Context, keep int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneSnapshots", ctx, keep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PruneSnapshots indicates an expected call of PruneSnapshots.
+func (mr *MockDBMockRecorder) PruneSnapshots(ctx, keep any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneSnapshots", reflect.TypeOf((*MockDB)(nil).PruneSnapshots), ctx, keep)
+}
+
+// Restore mocks base method.
+func (m *MockDB) Restore(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockDBMockRecorder) Restore(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockDB)(nil).Restore), ctx, name)
+}
+
+// Runs mocks base method.
+func (m *MockDB) Runs(ctx context.Context, limit, offset int) ([]UpdateRun, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Runs", ctx, limit, offset)
+	ret0, _ := ret[0].([]UpdateRun)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Runs indicates an expected call of Runs.
+func (mr *MockDBMockRecorder) Runs(ctx, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Runs", reflect.TypeOf((*MockDB)(nil).Runs), ctx, limit, offset)
+}
+
+// SaveCheckpoint mocks base method.
+func (m *MockDB) SaveCheckpoint(ctx context.Context, id int64, committed int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCheckpoint", ctx, id, committed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCheckpoint indicates an expected call of SaveCheckpoint.
+func (mr *MockDBMockRecorder) SaveCheckpoint(ctx, id, committed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCheckpoint", reflect.TypeOf((*MockDB)(nil).SaveCheckpoint), ctx, id, committed)
+}
+
+// Snapshots mocks base method.
+func (m *MockDB) Snapshots(arg0 context.Context) ([]Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshots", arg0)
+	ret0, _ := ret[0].([]Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshots indicates an expected call of Snapshots.
+func (mr *MockDBMockRecorder) Snapshots(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshots", reflect.TypeOf((*MockDB)(nil).Snapshots), arg0)
+}
+
+// StartCheckpoint mocks base method.
+func (m *MockDB) StartCheckpoint(arg0 context.Context, arg1 Checkpoint) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartCheckpoint", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartCheckpoint indicates an expected call of StartCheckpoint.
+func (mr *MockDBMockRecorder) StartCheckpoint(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartCheckpoint", reflect.TypeOf((*MockDB)(nil).StartCheckpoint), arg0, arg1)
+}
+
 // Stats mocks base method.
 func (m *MockDB) Stats(arg0 context.Context) (DBStats, error) {
 	m.ctrl.T.Helper()
@@ -179,32 +687,144 @@ func (mr *MockDBMockRecorder) Stats(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockDB)(nil).Stats), arg0)
 }
 
-// MockXKCD is a mock of XKCD interface.
-type MockXKCD struct {
+// Tombstones mocks base method.
+func (m *MockDB) Tombstones(arg0 context.Context) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tombstones", arg0)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tombstones indicates an expected call of Tombstones.
+func (mr *MockDBMockRecorder) Tombstones(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tombstones", reflect.TypeOf((*MockDB)(nil).Tombstones), arg0)
+}
+
+// Walk mocks base method.
+func (m *MockDB) Walk(arg0 context.Context, arg1 func(Comics) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Walk", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Walk indicates an expected call of Walk.
+func (mr *MockDBMockRecorder) Walk(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Walk", reflect.TypeOf((*MockDB)(nil).Walk), arg0, arg1)
+}
+
+// MockLease is a mock of Lease interface.
+type MockLease struct {
+	ctrl     *gomock.Controller
+	recorder *MockLeaseMockRecorder
+	isgomock struct{}
+}
+
+// MockLeaseMockRecorder is the mock recorder for MockLease.
+type MockLeaseMockRecorder struct {
+	mock *MockLease
+}
+
+// NewMockLease creates a new mock instance.
+func NewMockLease(ctrl *gomock.Controller) *MockLease {
+	mock := &MockLease{ctrl: ctrl}
+	mock.recorder = &MockLeaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLease) EXPECT() *MockLeaseMockRecorder {
+	return m.recorder
+}
+
+// Acquire mocks base method.
+func (m *MockLease) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Acquire", ctx, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Acquire indicates an expected call of Acquire.
+func (mr *MockLeaseMockRecorder) Acquire(ctx, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Acquire", reflect.TypeOf((*MockLease)(nil).Acquire), ctx, ttl)
+}
+
+// Holder mocks base method.
+func (m *MockLease) Holder(arg0 context.Context) (LeaseHolder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Holder", arg0)
+	ret0, _ := ret[0].(LeaseHolder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Holder indicates an expected call of Holder.
+func (mr *MockLeaseMockRecorder) Holder(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Holder", reflect.TypeOf((*MockLease)(nil).Holder), arg0)
+}
+
+// Release mocks base method.
+func (m *MockLease) Release(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockLeaseMockRecorder) Release(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockLease)(nil).Release), arg0)
+}
+
+// Renew mocks base method.
+func (m *MockLease) Renew(ctx context.Context, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Renew", ctx, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Renew indicates an expected call of Renew.
+func (mr *MockLeaseMockRecorder) Renew(ctx, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Renew", reflect.TypeOf((*MockLease)(nil).Renew), ctx, ttl)
+}
+
+// MockSource is a mock of Source interface.
+type MockSource struct {
 	ctrl     *gomock.Controller
-	recorder *MockXKCDMockRecorder
+	recorder *MockSourceMockRecorder
 	isgomock struct{}
 }
 
-// MockXKCDMockRecorder is the mock recorder for MockXKCD.
-type MockXKCDMockRecorder struct {
-	mock *MockXKCD
+// MockSourceMockRecorder is the mock recorder for MockSource.
+type MockSourceMockRecorder struct {
+	mock *MockSource
 }
 
-// NewMockXKCD creates a new mock instance.
-func NewMockXKCD(ctrl *gomock.Controller) *MockXKCD {
-	mock := &MockXKCD{ctrl: ctrl}
-	mock.recorder = &MockXKCDMockRecorder{mock}
+// NewMockSource creates a new mock instance.
+func NewMockSource(ctrl *gomock.Controller) *MockSource {
+	mock := &MockSource{ctrl: ctrl}
+	mock.recorder = &MockSourceMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockXKCD) EXPECT() *MockXKCDMockRecorder {
+func (m *MockSource) EXPECT() *MockSourceMockRecorder {
 	return m.recorder
 }
 
 // Get mocks base method.
-func (m *MockXKCD) Get(arg0 context.Context, arg1 int) (XKCDInfo, error) {
+func (m *MockSource) Get(arg0 context.Context, arg1 int) (XKCDInfo, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get", arg0, arg1)
 	ret0, _ := ret[0].(XKCDInfo)
@@ -213,13 +833,13 @@ func (m *MockXKCD) Get(arg0 context.Context, arg1 int) (XKCDInfo, error) {
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockXKCDMockRecorder) Get(arg0, arg1 any) *gomock.Call {
+func (mr *MockSourceMockRecorder) Get(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockXKCD)(nil).Get), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSource)(nil).Get), arg0, arg1)
 }
 
 // LastID mocks base method.
-func (m *MockXKCD) LastID(arg0 context.Context) (int, error) {
+func (m *MockSource) LastID(arg0 context.Context) (int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "LastID", arg0)
 	ret0, _ := ret[0].(int)
@@ -228,9 +848,23 @@ func (m *MockXKCD) LastID(arg0 context.Context) (int, error) {
 }
 
 // LastID indicates an expected call of LastID.
-func (mr *MockXKCDMockRecorder) LastID(arg0 any) *gomock.Call {
+func (mr *MockSourceMockRecorder) LastID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastID", reflect.TypeOf((*MockSource)(nil).LastID), arg0)
+}
+
+// State mocks base method.
+func (m *MockSource) State() XKCDState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "State")
+	ret0, _ := ret[0].(XKCDState)
+	return ret0
+}
+
+// State indicates an expected call of State.
+func (mr *MockSourceMockRecorder) State() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastID", reflect.TypeOf((*MockXKCD)(nil).LastID), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockSource)(nil).State))
 }
 
 // MockWords is a mock of Words interface.
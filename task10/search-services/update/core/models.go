@@ -1,12 +1,82 @@
 package core
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
 type ServiceStatus string
 
 const (
-	StatusRunning ServiceStatus = "running"
-	StatusIdle    ServiceStatus = "idle"
+	StatusRunning    ServiceStatus = "running"
+	StatusIdle       ServiceStatus = "idle"
+	StatusCancelling ServiceStatus = "cancelling"
+	// итоги прошлого запуска держатся до следующего
+	StatusCancelled ServiceStatus = "cancelled"
+	StatusFailed    ServiceStatus = "failed"
+)
+
+// Active - запуск ещё не завершён
+func (s ServiceStatus) Active() bool {
+	return s == StatusRunning || s == StatusCancelling
+}
+
+type UpdateProgress struct {
+	Total      int
+	Fetched    int
+	Normalized int
+	Stored     int
+	Failed     int
+	Unchanged  int
+	StartedAt  time.Time
+}
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
 )
 
+type XKCDState struct {
+	// Retries - повторы запросов с начала текущего или последнего запуска,
+	// до первого запуска - с запуска сервиса
+	Retries int
+	Breaker BreakerState
+}
+
+type ServiceState struct {
+	Status   ServiceStatus
+	Progress UpdateProgress
+	XKCD     XKCDState
+	// Replica - реплика, которая держит блокировку обновления
+	Replica string
+	// LastRun - итог последнего запуска на этой реплике, пустой FinishedAt - запусков не было
+	LastRun  UpdateRun
+	Pipeline PipelineStats
+	// Scheduled - планировщик назначил следующий запуск на NextRun
+	Scheduled bool
+	NextRun   time.Time
+}
+
+// WithSchedule дополняет состояние следующим запуском планировщика, статус не меняется
+func (s ServiceState) WithSchedule(schedule Schedule) ServiceState {
+	if !schedule.Paused && !schedule.NextRun.IsZero() {
+		s.Scheduled = true
+		s.NextRun = schedule.NextRun
+	}
+	return s
+}
+
+// LeaseHolder - текущий держатель аренды, пустой Replica - аренда свободна
+type LeaseHolder struct {
+	Replica    string
+	AcquiredAt time.Time
+	ExpiresAt  time.Time
+}
+
 type DBStats struct {
 	WordsTotal    int
 	WordsUnique   int
@@ -18,14 +88,235 @@ type ServiceStats struct {
 	ComicsTotal int
 }
 
+type Metadata struct {
+	Title      string
+	Alt        string
+	Transcript string
+	Link       string
+	News       string
+	Published  time.Time
+}
+
 type Comics struct {
-	ID    int
-	URL   string
-	Words []string
+	ID     int
+	URL    string
+	Words  []string
+	Hash   string
+	Source string
+	Metadata
 }
 
 type XKCDInfo struct {
 	ID          int
 	URL         string
 	Description string
+	Source      string
+	Metadata
+}
+
+// Hash - отпечаток содержимого комикса, по нему Refresh находит исправленные комиксы
+func (x XKCDInfo) Hash() string {
+	h := sha256.New()
+	for _, field := range []string{
+		x.URL, x.Description, x.Title, x.Alt, x.Transcript, x.Link, x.News,
+		x.Published.Format(time.DateOnly),
+	} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type FailureStage string
+
+const (
+	StageFetch FailureStage = "fetch"
+	StageNorm  FailureStage = "norm"
+	StageAdd   FailureStage = "add"
+)
+
+type Failure struct {
+	ID       int
+	Stage    FailureStage
+	Error    string
+	Attempts int
+	FailedAt time.Time
+}
+
+// StageOptions - число воркеров стадии конвейера и буфер её входного канала
+type StageOptions struct {
+	Workers int
+	Buffer  int
+}
+
+// PipelineOptions - настройки стадий конвейера: загрузка из источника,
+// нормализация в words и запись в БД
+type PipelineOptions struct {
+	Fetch StageOptions
+	Norm  StageOptions
+	Add   StageOptions
+}
+
+// StageStats - метрики стадии за текущий или последний запуск. Queue - сколько
+// элементов ждёт во входном канале, Latency - среднее время одной операции
+// стадии: запроса к источнику, нормализации или записи пачки.
+type StageStats struct {
+	Workers    int
+	Buffer     int
+	Queue      int
+	Processed  int
+	Throughput float64
+	Latency    time.Duration
+}
+
+type PipelineStats struct {
+	Fetch StageStats
+	Norm  StageStats
+	Add   StageStats
+}
+
+type BatchOptions struct {
+	Size          int
+	FlushInterval time.Duration
+}
+
+// UpdateOptions: Resume продолжает прерванный обход с его незакрытой отметки,
+// NewestFirst обходит комиксы от новых к старым, чтобы свежие раньше попали в поиск
+type UpdateOptions struct {
+	Reconcile   bool
+	Resume      bool
+	NewestFirst bool
+	Trigger     RunTrigger
+}
+
+// Checkpoint - отметка обхода Update: диапазоны ID, порядок обхода и Committed -
+// ID, до которого в порядке обхода все комиксы уже сохранены, упали или
+// отсутствуют. Завершённый или отменённый запуск закрывает отметку, после
+// падения или сбоя она остаётся открытой и продолжается через Resume.
+type Checkpoint struct {
+	ID          int64
+	Mode        RunMode
+	Ranges      []SourceRange
+	NewestFirst bool
+	Committed   int
+	StartedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// after - ID идёт в порядке обхода после Committed, то есть ещё не пройден
+func (c Checkpoint) after(id int) bool {
+	if c.Committed == 0 {
+		return true
+	}
+	if c.NewestFirst {
+		return id < c.Committed
+	}
+	return id > c.Committed
+}
+
+// UpdatePlan - что сделал бы запуск обновления. Missing - комиксы, которые
+// будут скачаны; Tombstoned и Failed - несуществующие и упавшие ранее
+// комиксы в диапазонах источников, Failed повторяются и в Missing.
+type UpdatePlan struct {
+	Mode       RunMode
+	Ranges     []SourceRange
+	Missing    []int
+	Tombstoned []int
+	Failed     []int
+	Estimate   time.Duration
+}
+
+type RunTrigger string
+
+const (
+	TriggerManual    RunTrigger = "manual"
+	TriggerScheduled RunTrigger = "scheduled"
+)
+
+type RunMode string
+
+const (
+	ModeUpdate    RunMode = "update"
+	ModeReconcile RunMode = "reconcile"
+	ModeRetry     RunMode = "retry"
+	ModeRefresh   RunMode = "refresh"
+)
+
+type RunStatus string
+
+const (
+	RunCompleted RunStatus = "completed"
+	RunCancelled RunStatus = "cancelled"
+	RunFailed    RunStatus = "failed"
+)
+
+// UpdateRun - запись журнала запусков. Skipped - запланированные комиксы,
+// которые не сохранены и не упали: неизменившиеся при Refresh или не
+// обработанные из-за отмены. Отсутствующие в источнике в план не входят.
+type UpdateRun struct {
+	ID         int64
+	Mode       RunMode
+	Trigger    RunTrigger
+	Status     RunStatus
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Stored     int
+	Failed     int
+	Skipped    int
+	Error      string
+}
+
+type HistoryOptions struct {
+	Limit  int
+	Offset int
+}
+
+// History - страница журнала запусков, от новых к старым
+type History struct {
+	Runs  []UpdateRun
+	Total int
+}
+
+// RefreshOptions ограничивает диапазон ID, 0 - без ограничения
+type RefreshOptions struct {
+	From int
+	To   int
+}
+
+type RefreshResult struct {
+	Checked int
+	Changed int
+}
+
+// DeleteOptions - удаляемые комиксы: либо список IDs, либо диапазон From..To включительно
+type DeleteOptions struct {
+	IDs  []int
+	From int
+	To   int
+}
+
+// ImportResult - итог импорта, Errors - первые ошибки проверки пропущенных записей
+type ImportResult struct {
+	Imported int
+	Skipped  int
+	Errors   []string
+}
+
+// Snapshot - сохранённая перед Drop копия комиксов
+type Snapshot struct {
+	Name      string
+	Comics    int
+	CreatedAt time.Time
+}
+
+type Schedule struct {
+	Period  time.Duration
+	Paused  bool
+	NextRun time.Time
+	LastRun time.Time
+}
+
+type ScheduleSettings struct {
+	Period *time.Duration
+	Paused *bool
 }
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func (o PipelineOptions) validate() error {
+	for name, stage := range map[string]StageOptions{"fetch": o.Fetch, "norm": o.Norm, "add": o.Add} {
+		if stage.Workers < 1 {
+			return fmt.Errorf("wrong %s concurrency specified: %d", name, stage.Workers)
+		}
+		if stage.Buffer < 0 {
+			return fmt.Errorf("wrong %s buffer specified: %d", name, stage.Buffer)
+		}
+	}
+	return nil
+}
+
+// meter - счётчики стадии конвейера, queue - длина её входного канала
+type meter struct {
+	opts      StageOptions
+	queue     func() int
+	processed atomic.Int64
+	calls     atomic.Int64
+	busy      atomic.Int64
+}
+
+// observe учитывает одну операцию стадии над n комиксами
+func (m *meter) observe(n int, started time.Time) {
+	m.processed.Add(int64(n))
+	m.calls.Add(1)
+	m.busy.Add(int64(time.Since(started)))
+}
+
+func (m *meter) stats(elapsed time.Duration) StageStats {
+	stats := StageStats{
+		Workers:   m.opts.Workers,
+		Buffer:    m.opts.Buffer,
+		Processed: int(m.processed.Load()),
+	}
+	if m.queue != nil {
+		stats.Queue = m.queue()
+	}
+	if calls := m.calls.Load(); calls > 0 {
+		stats.Latency = time.Duration(m.busy.Load() / calls)
+	}
+	if elapsed > 0 {
+		stats.Throughput = float64(stats.Processed) / elapsed.Seconds()
+	}
+	return stats
+}
+
+// meters - метрики стадий одного запуска; finished пустой, пока запуск идёт
+type meters struct {
+	fetch, norm, add meter
+	started          time.Time
+	finished         time.Time
+}
+
+func newMeters(opts PipelineOptions) *meters {
+	m := &meters{started: time.Now()}
+	m.fetch.opts, m.norm.opts, m.add.opts = opts.Fetch, opts.Norm, opts.Add
+	return m
+}
+
+func (m *meters) stats() PipelineStats {
+	end := m.finished
+	if end.IsZero() {
+		end = time.Now()
+	}
+	elapsed := end.Sub(m.started)
+	return PipelineStats{
+		Fetch: m.fetch.stats(elapsed),
+		Norm:  m.norm.stats(elapsed),
+		Add:   m.add.stats(elapsed),
+	}
+}
+
+// spawn запускает n воркеров стадии и закрывает её выходной канал,
+// когда все они завершатся
+func spawn[T any](n int, out chan T, work func()) {
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for range n {
+		go func() {
+			defer wg.Done()
+			work()
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+}
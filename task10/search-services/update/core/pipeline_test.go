@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewService_InvalidPipeline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, opts := range []PipelineOptions{
+		{Fetch: StageOptions{Workers: 1}, Norm: StageOptions{Workers: 0}, Add: StageOptions{Workers: 1}},
+		{Fetch: StageOptions{Workers: 1}, Norm: StageOptions{Workers: 1}, Add: StageOptions{Workers: 1, Buffer: -1}},
+	} {
+		_, err := NewService(logger, NewMockDB(ctrl), single(t, NewMockSource(ctrl)), NewMockWords(ctrl), opts, batch, 0, nil)
+		require.Error(t, err)
+	}
+}
+
+func TestUpdate_PipelineStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	opts := PipelineOptions{
+		Fetch: StageOptions{Workers: 3, Buffer: 5},
+		Norm:  StageOptions{Workers: 2, Buffer: 1},
+		Add:   StageOptions{Workers: 1, Buffer: 10},
+	}
+	svc, err := NewService(logger, db, single(t, xkcd), words, opts, BatchOptions{Size: 2, FlushInterval: time.Second}, 0, nil)
+	require.NoError(t, err)
+	expectCheckpoints(db)
+
+	// до первого запуска видны только настройки стадий
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	stats := svc.Status(context.Background()).Pipeline
+	require.Equal(t, StageStats{Workers: 3, Buffer: 5}, stats.Fetch)
+	require.Equal(t, StageStats{Workers: 1, Buffer: 10}, stats.Add)
+
+	xkcd.EXPECT().LastID(gomock.Any()).Return(2, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1, 2).Return([]int{1, 2}, nil)
+	xkcd.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id int) (XKCDInfo, error) {
+		time.Sleep(10 * time.Millisecond)
+		return XKCDInfo{ID: id, Description: "comic"}, nil
+	}).Times(2)
+	words.EXPECT().Norm(gomock.Any(), "comic").Return([]string{"comic"}, nil).Times(2)
+	db.EXPECT().AddBatch(gomock.Any(), gomock.Len(2)).Return(nil)
+	db.EXPECT().NotifyChanged(gomock.Any(), "update").Return(nil)
+	expectRun(db, ModeUpdate, RunCompleted)
+
+	require.NoError(t, svc.Update(context.Background(), UpdateOptions{}))
+
+	stats = svc.Status(context.Background()).Pipeline
+	require.Equal(t, 3, stats.Fetch.Workers)
+	require.Equal(t, 2, stats.Norm.Workers)
+	require.Equal(t, 2, stats.Fetch.Processed)
+	require.Equal(t, 2, stats.Norm.Processed)
+	require.Equal(t, 2, stats.Add.Processed)
+	require.Zero(t, stats.Fetch.Queue)
+	require.GreaterOrEqual(t, stats.Fetch.Latency, 10*time.Millisecond)
+	require.Greater(t, stats.Fetch.Throughput, 0.0)
+
+	// после запуска метрики не меняются
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, stats, svc.Status(context.Background()).Pipeline)
+}
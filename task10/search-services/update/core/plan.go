@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+const (
+	// planHistory - сколько последних запусков учитывать в оценке длительности
+	planHistory = 20
+	// defaultComicTime - время одного воркера на комикс, пока журнал запусков пуст
+	defaultComicTime = 500 * time.Millisecond
+)
+
+// Plan считает, что сделал бы Update с теми же опциями, ничего не скачивая
+// и не записывая в БД. Аренду не берёт: план можно смотреть и во время запуска.
+func (s *Service) Plan(ctx context.Context, opts UpdateOptions) (UpdatePlan, error) {
+	cp, err := s.target(ctx, opts)
+	if err != nil {
+		return UpdatePlan{}, err
+	}
+	ranges := cp.Ranges
+	plan := UpdatePlan{Mode: cp.Mode, Ranges: ranges}
+
+	plan.Missing, err = s.pending(ctx, cp)
+	if err != nil {
+		return UpdatePlan{}, err
+	}
+
+	tombstones, err := s.db.Tombstones(ctx)
+	if err != nil {
+		s.log.Error("failed to retrieve tombstones from database", "error", err)
+		return UpdatePlan{}, err
+	}
+	plan.Tombstoned = inRanges(tombstones, ranges)
+
+	failed, err := s.failedIDs(ctx)
+	if err != nil {
+		return UpdatePlan{}, err
+	}
+	plan.Failed = inRanges(failed, ranges)
+
+	plan.Estimate, err = s.estimate(ctx, len(plan.Missing))
+	if err != nil {
+		return UpdatePlan{}, err
+	}
+
+	return plan, nil
+}
+
+// estimate берёт среднее время на комикс из завершённых запусков; в них
+// уже учтена параллельность, поэтому на воркеры загрузки делится только
+// время по умолчанию
+func (s *Service) estimate(ctx context.Context, comics int) (time.Duration, error) {
+	if comics == 0 {
+		return 0, nil
+	}
+
+	runs, _, err := s.db.Runs(ctx, planHistory, 0)
+	if err != nil {
+		s.log.Error("failed to retrieve update history", "error", err)
+		return 0, err
+	}
+
+	var spent time.Duration
+	var processed int
+	for _, run := range runs {
+		n := run.Stored + run.Failed
+		if run.Status != RunCompleted || n == 0 {
+			continue
+		}
+		spent += run.FinishedAt.Sub(run.StartedAt)
+		processed += n
+	}
+
+	perComic := defaultComicTime / time.Duration(s.pipeline.Fetch.Workers)
+	if processed > 0 {
+		perComic = spent / time.Duration(processed)
+	}
+
+	return (perComic * time.Duration(comics)).Round(time.Second), nil
+}
+
+func inRanges(ids []int, ranges []SourceRange) []int {
+	var found []int
+	for _, id := range ids {
+		for _, r := range ranges {
+			if id >= r.From && id <= r.To {
+				found = append(found, id)
+				break
+			}
+		}
+	}
+	slices.Sort(found)
+	return found
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPlan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+
+	// план только читает: AddBatch, AddRun и аренда не ожидаются
+	svc, err := NewService(logger, db, single(t, xkcd), NewMockWords(ctrl), pipeline(4), batch, 0, nil)
+	require.NoError(t, err)
+
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	xkcd.EXPECT().LastID(gomock.Any()).Return(10, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1, 10).Return([]int{3, 5, 9}, nil)
+	db.EXPECT().Tombstones(gomock.Any()).Return([]int{404, 4}, nil)
+	db.EXPECT().Failures(gomock.Any()).Return([]Failure{{ID: 9}, {ID: 5}}, nil)
+
+	started := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	db.EXPECT().Runs(gomock.Any(), planHistory, 0).Return([]UpdateRun{
+		{Status: RunCompleted, StartedAt: started, FinishedAt: started.Add(8 * time.Second), Stored: 3, Failed: 1},
+		// отменённые и пустые запуски в оценку не идут
+		{Status: RunCancelled, StartedAt: started, FinishedAt: started.Add(time.Hour), Stored: 1},
+		{Status: RunCompleted, StartedAt: started, FinishedAt: started.Add(time.Minute)},
+	}, 3, nil)
+
+	plan, err := svc.Plan(context.Background(), UpdateOptions{})
+	require.NoError(t, err)
+	require.Equal(t, UpdatePlan{
+		Mode:       ModeUpdate,
+		Ranges:     []SourceRange{{Source: "xkcd", From: 1, To: 10}},
+		Missing:    []int{3, 5, 9},
+		Tombstoned: []int{4},
+		Failed:     []int{5, 9},
+		Estimate:   6 * time.Second,
+	}, plan)
+	require.Equal(t, StatusIdle, svc.Status(context.Background()).Status)
+}
+
+func TestPlan_Reconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), NewMockWords(ctrl), pipeline(2), batch, 0, nil)
+	require.NoError(t, err)
+
+	xkcd.EXPECT().LastID(gomock.Any()).Return(40, nil)
+	db.EXPECT().IDs(gomock.Any()).Return([]int{1, 4}, nil)
+	db.EXPECT().Tombstones(gomock.Any()).Return([]int{2}, nil).Times(2)
+	db.EXPECT().Failures(gomock.Any()).Return(nil, nil)
+	// журнал пуст - время по умолчанию делится между воркерами
+	db.EXPECT().Runs(gomock.Any(), planHistory, 0).Return(nil, 0, nil)
+
+	plan, err := svc.Plan(context.Background(), UpdateOptions{Reconcile: true})
+	require.NoError(t, err)
+	require.Equal(t, ModeReconcile, plan.Mode)
+	require.Len(t, plan.Missing, 37)
+	require.Equal(t, 3, plan.Missing[0])
+	require.Equal(t, []int{2}, plan.Tombstoned)
+	require.Empty(t, plan.Failed)
+	// 37 комиксов по 500ms на двух воркерах
+	require.Equal(t, 9*time.Second, plan.Estimate)
+}
+
+func TestPlan_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	xkcd.EXPECT().LastID(gomock.Any()).Return(0, ErrUpstreamUnavailable)
+	_, err = svc.Plan(context.Background(), UpdateOptions{})
+	require.ErrorIs(t, err, ErrUpstreamUnavailable)
+
+	xkcd.EXPECT().LastID(gomock.Any()).Return(3, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1, 3).Return([]int{1}, nil)
+	db.EXPECT().Tombstones(gomock.Any()).Return(nil, nil)
+	db.EXPECT().Failures(gomock.Any()).Return(nil, nil)
+	db.EXPECT().Runs(gomock.Any(), planHistory, 0).Return(nil, 0, errors.New("db error"))
+	_, err = svc.Plan(context.Background(), UpdateOptions{})
+	require.Error(t, err)
+}
@@ -2,25 +2,75 @@ package core
 
 import (
 	"context"
+	"time"
 )
 
 type Updater interface {
-	Update(context.Context) error
+	Update(context.Context, UpdateOptions) error
+	Plan(context.Context, UpdateOptions) (UpdatePlan, error)
+	RetryFailed(context.Context) error
+	Refresh(context.Context, RefreshOptions) (RefreshResult, error)
+	Failures(context.Context) ([]Failure, error)
+	History(context.Context, HistoryOptions) (History, error)
+	Export(context.Context, func(Comics) error) error
+	Import(context.Context, func() (Comics, error)) (ImportResult, error)
 	Stats(context.Context) (ServiceStats, error)
-	Status(context.Context) ServiceStatus
-	Drop(context.Context) error
+	Status(context.Context) ServiceState
+	Cancel(context.Context) (ServiceState, error)
+	Delete(context.Context, DeleteOptions) (int, error)
+	Drop(context.Context) (Snapshot, error)
+	Snapshots(context.Context) ([]Snapshot, error)
+	Restore(context.Context, string) error
+	DeleteSnapshot(context.Context, string) error
+}
+
+type Scheduler interface {
+	Schedule(context.Context) Schedule
+	SetSchedule(context.Context, ScheduleSettings) (Schedule, error)
 }
 
 type DB interface {
-	Add(context.Context, Comics) error
+	AddBatch(context.Context, []Comics) error
+	ImportBatch(context.Context, []Comics) error
+	Walk(context.Context, func(Comics) error) error
 	Stats(context.Context) (DBStats, error)
-	Drop(context.Context) error
+	DeleteIDs(ctx context.Context, ids []int) (int, error)
+	DeleteRange(ctx context.Context, from, to int) (int, error)
+	Drop(ctx context.Context, snapshot string) (Snapshot, error)
+	Snapshots(context.Context) ([]Snapshot, error)
+	Restore(ctx context.Context, name string) error
+	DeleteSnapshot(ctx context.Context, name string) error
+	PruneSnapshots(ctx context.Context, keep int) error
 	IDs(context.Context) ([]int, error)
+	MissingIDs(ctx context.Context, from, to int) ([]int, error)
+	Hashes(ctx context.Context, from, to int) (map[int]string, error)
+	AddRun(context.Context, UpdateRun) error
+	Runs(ctx context.Context, limit, offset int) ([]UpdateRun, int, error)
+	NotifyChanged(ctx context.Context, reason string) error
+	StartCheckpoint(context.Context, Checkpoint) (int64, error)
+	SaveCheckpoint(ctx context.Context, id int64, committed int) error
+	FinishCheckpoint(ctx context.Context, id int64) error
+	Checkpoint(context.Context) (Checkpoint, error)
+	AddFailure(context.Context, Failure) error
+	Failures(context.Context) ([]Failure, error)
+	AddTombstone(context.Context, int) error
+	Tombstones(context.Context) ([]int, error)
+}
+
+// Lease - аренда права на запуск обновления, общая для всех реплик.
+// Аренда истекает через ttl, если держатель перестал её продлевать.
+type Lease interface {
+	Acquire(ctx context.Context, ttl time.Duration) (bool, error)
+	Renew(ctx context.Context, ttl time.Duration) (bool, error)
+	Release(context.Context) error
+	Holder(context.Context) (LeaseHolder, error)
 }
 
-type XKCD interface {
+// Source - источник комиксов в формате xkcd, ID - номер комикса в самом источнике
+type Source interface {
 	Get(context.Context, int) (XKCDInfo, error)
 	LastID(context.Context) (int, error)
+	State() XKCDState
 }
 
 type Words interface {
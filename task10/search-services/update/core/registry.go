@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Registry - набор источников комиксов. Каждому источнику отведён свой
+// диапазон ID в БД: номер комикса в источнике сдвигается на offset,
+// поэтому поиску и журналам достаточно одного целого ID.
+type Registry struct {
+	sources []registered
+}
+
+type registered struct {
+	name   string
+	offset int
+	source Source
+}
+
+// SourceRange - диапазон ID источника в БД, включая границы
+type SourceRange struct {
+	Source string
+	From   int
+	To     int
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) Register(name string, offset int, source Source) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty source name", ErrBadArguments)
+	}
+	if offset < 0 {
+		return fmt.Errorf("%w: wrong ID offset %d of source %s", ErrBadArguments, offset, name)
+	}
+	for _, reg := range r.sources {
+		if reg.name == name {
+			return fmt.Errorf("%w: source %s is registered twice", ErrBadArguments, name)
+		}
+		if reg.offset == offset {
+			return fmt.Errorf("%w: sources %s and %s share ID offset %d", ErrBadArguments, reg.name, name, offset)
+		}
+	}
+
+	r.sources = append(r.sources, registered{name: name, offset: offset, source: source})
+	slices.SortFunc(r.sources, func(a, b registered) int { return a.offset - b.offset })
+
+	return nil
+}
+
+func (r *Registry) Len() int {
+	return len(r.sources)
+}
+
+// Lookup находит источник по ID в БД и возвращает номер комикса в этом источнике
+func (r *Registry) Lookup(id int) (string, Source, int, bool) {
+	for i := len(r.sources) - 1; i >= 0; i-- {
+		if reg := r.sources[i]; id > reg.offset {
+			return reg.name, reg.source, id - reg.offset, true
+		}
+	}
+	return "", nil, 0, false
+}
+
+// Ranges спрашивает у источников последний номер и переводит его в диапазоны ID БД
+func (r *Registry) Ranges(ctx context.Context) ([]SourceRange, error) {
+	ranges := make([]SourceRange, 0, len(r.sources))
+	for i, reg := range r.sources {
+		lastID, err := reg.source.LastID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", reg.name, err)
+		}
+
+		to := reg.offset + lastID
+		if i+1 < len(r.sources) && to > r.sources[i+1].offset {
+			return nil, fmt.Errorf("source %s overflows its ID range: last ID %d, next offset %d",
+				reg.name, to, r.sources[i+1].offset)
+		}
+		ranges = append(ranges, SourceRange{Source: reg.name, From: reg.offset + 1, To: to})
+	}
+
+	return ranges, nil
+}
+
+// State сводит состояние источников: повторы суммируются,
+// предохранитель показывается самый тревожный
+func (r *Registry) State() XKCDState {
+	state := XKCDState{Breaker: BreakerClosed}
+	for _, reg := range r.sources {
+		s := reg.source.State()
+		state.Retries += s.Retries
+		if severity(s.Breaker) > severity(state.Breaker) {
+			state.Breaker = s.Breaker
+		}
+	}
+	return state
+}
+
+func severity(state BreakerState) int {
+	switch state {
+	case BreakerOpen:
+		return 2
+	case BreakerHalfOpen:
+		return 1
+	default:
+		return 0
+	}
+}
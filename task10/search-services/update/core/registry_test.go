@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sources := NewRegistry()
+	require.NoError(t, sources.Register("xkcd", 0, NewMockSource(ctrl)))
+
+	require.ErrorIs(t, sources.Register("", 100, NewMockSource(ctrl)), ErrBadArguments)
+	require.ErrorIs(t, sources.Register("local", -1, NewMockSource(ctrl)), ErrBadArguments)
+	require.ErrorIs(t, sources.Register("xkcd", 100, NewMockSource(ctrl)), ErrBadArguments)
+	require.ErrorIs(t, sources.Register("local", 0, NewMockSource(ctrl)), ErrBadArguments)
+
+	require.Equal(t, 1, sources.Len())
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	xkcd := NewMockSource(ctrl)
+	local := NewMockSource(ctrl)
+
+	sources := NewRegistry()
+	// порядок регистрации не важен, важны смещения
+	require.NoError(t, sources.Register("local", 1000, local))
+	require.NoError(t, sources.Register("xkcd", 0, xkcd))
+
+	name, source, id, ok := sources.Lookup(1000)
+	require.True(t, ok)
+	require.Equal(t, "xkcd", name)
+	require.Equal(t, xkcd, source)
+	require.Equal(t, 1000, id)
+
+	name, source, id, ok = sources.Lookup(1001)
+	require.True(t, ok)
+	require.Equal(t, "local", name)
+	require.Equal(t, local, source)
+	require.Equal(t, 1, id)
+
+	_, _, _, ok = sources.Lookup(0)
+	require.False(t, ok)
+}
+
+func TestRegistry_Ranges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	xkcd := NewMockSource(ctrl)
+	local := NewMockSource(ctrl)
+
+	sources := NewRegistry()
+	require.NoError(t, sources.Register("xkcd", 0, xkcd))
+	require.NoError(t, sources.Register("local", 1000, local))
+
+	xkcd.EXPECT().LastID(gomock.Any()).Return(900, nil)
+	local.EXPECT().LastID(gomock.Any()).Return(5, nil)
+
+	ranges, err := sources.Ranges(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []SourceRange{
+		{Source: "xkcd", From: 1, To: 900},
+		{Source: "local", From: 1001, To: 1005},
+	}, ranges)
+
+	// источник вылез за свой диапазон
+	xkcd.EXPECT().LastID(gomock.Any()).Return(1001, nil)
+	_, err = sources.Ranges(context.Background())
+	require.Error(t, err)
+
+	xkcd.EXPECT().LastID(gomock.Any()).Return(0, ErrUpstreamUnavailable)
+	_, err = sources.Ranges(context.Background())
+	require.ErrorIs(t, err, ErrUpstreamUnavailable)
+}
+
+func TestRegistry_State(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	xkcd := NewMockSource(ctrl)
+	local := NewMockSource(ctrl)
+
+	sources := NewRegistry()
+	require.NoError(t, sources.Register("xkcd", 0, xkcd))
+	require.NoError(t, sources.Register("local", 1000, local))
+
+	xkcd.EXPECT().State().Return(XKCDState{Retries: 3, Breaker: BreakerHalfOpen})
+	local.EXPECT().State().Return(XKCDState{Retries: 1, Breaker: BreakerClosed})
+
+	require.Equal(t, XKCDState{Retries: 4, Breaker: BreakerHalfOpen}, sources.State())
+}
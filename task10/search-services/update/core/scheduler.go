@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MinSchedulePeriod - нижняя граница периода, задаваемого через SetSchedule
+const MinSchedulePeriod = time.Minute
+
+type UpdateScheduler struct {
+	log     *slog.Logger
+	updater Updater
+	mx      sync.Mutex
+	period  time.Duration
+	paused  bool
+	nextRun time.Time
+	lastRun time.Time
+	reset   chan struct{}
+}
+
+func NewUpdateScheduler(log *slog.Logger, updater Updater, period time.Duration) (*UpdateScheduler, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("wrong check period specified: %v", period)
+	}
+	return &UpdateScheduler{
+		log:     log,
+		updater: updater,
+		period:  period,
+		reset:   make(chan struct{}, 1),
+	}, nil
+}
+
+func (s *UpdateScheduler) Start(ctx context.Context) {
+	s.mx.Lock()
+	if !s.paused {
+		s.nextRun = time.Now().Add(s.period)
+	}
+	s.mx.Unlock()
+
+	s.log.Info("Start update scheduler", "period", s.period)
+
+	go func() {
+		s.resume(ctx)
+
+		for {
+			timer, wait := s.timer()
+
+			select {
+			case <-ctx.Done():
+				stopTimer(timer)
+				s.log.Info("Update scheduler stopped")
+				return
+			case <-s.reset:
+				stopTimer(timer)
+			case <-wait:
+				s.run(ctx)
+			}
+		}
+	}()
+}
+
+func (s *UpdateScheduler) timer() (*time.Timer, <-chan time.Time) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	// на паузе ждём только reset или остановку
+	if s.paused {
+		return nil, nil
+	}
+
+	timer := time.NewTimer(time.Until(s.nextRun))
+	return timer, timer.C
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
+
+func (s *UpdateScheduler) run(ctx context.Context) {
+	started := time.Now()
+	s.log.Info("scheduled update started")
+
+	err := s.updater.Update(ctx, UpdateOptions{Trigger: TriggerScheduled})
+
+	s.mx.Lock()
+	if !errors.Is(err, ErrAlreadyExists) {
+		s.lastRun = started
+	}
+	// на паузу могли поставить во время запуска, тогда следующего нет
+	if !s.paused {
+		s.nextRun = nextTick(s.nextRun, s.period, time.Now())
+	}
+	s.mx.Unlock()
+
+	switch {
+	case errors.Is(err, ErrAlreadyExists):
+		s.log.Info("update already runs, scheduled run skipped")
+	case errors.Is(err, ErrCancelled):
+		s.log.Info("scheduled update cancelled")
+	case err != nil:
+		s.log.Error("scheduled update failed", "error", err)
+	default:
+		s.log.Info("scheduled update finished", "duration", time.Since(started))
+	}
+}
+
+// nextTick - ближайший после now тик сетки next + k*period: тики, пропущенные
+// во время долгого обновления, не наверстываем
+func nextTick(next time.Time, period time.Duration, now time.Time) time.Time {
+	if next.IsZero() {
+		return now.Add(period)
+	}
+	if next.After(now) {
+		return next
+	}
+	return now.Add(period - now.Sub(next)%period)
+}
+
+// resume продолжает обход, прерванный падением или сбоем до перезапуска
+func (s *UpdateScheduler) resume(ctx context.Context) {
+	err := s.updater.Update(ctx, UpdateOptions{Trigger: TriggerScheduled, Resume: true})
+	switch {
+	case errors.Is(err, ErrNotFound):
+		s.log.Debug("no interrupted update to resume")
+	case errors.Is(err, ErrAlreadyExists):
+		s.log.Info("update already runs, interrupted update is not resumed")
+	case errors.Is(err, ErrCancelled):
+		s.log.Info("resumed update cancelled")
+	case err != nil:
+		s.log.Error("resumed update failed", "error", err)
+	default:
+		s.log.Info("interrupted update resumed and finished")
+	}
+}
+
+func (s *UpdateScheduler) Schedule(_ context.Context) Schedule {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return s.schedule()
+}
+
+func (s *UpdateScheduler) SetSchedule(_ context.Context, settings ScheduleSettings) (Schedule, error) {
+	if settings.Period != nil && *settings.Period < MinSchedulePeriod {
+		return Schedule{}, fmt.Errorf("%w: period must be at least %v", ErrBadArguments, MinSchedulePeriod)
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if settings.Period != nil {
+		s.period = *settings.Period
+	}
+	if settings.Paused != nil {
+		s.paused = *settings.Paused
+	}
+
+	if s.paused {
+		s.nextRun = time.Time{}
+	} else {
+		s.nextRun = time.Now().Add(s.period)
+	}
+
+	select {
+	case s.reset <- struct{}{}:
+	default:
+	}
+
+	s.log.Info("schedule changed", "period", s.period, "paused", s.paused)
+
+	return s.schedule(), nil
+}
+
+func (s *UpdateScheduler) schedule() Schedule {
+	return Schedule{
+		Period:  s.period,
+		Paused:  s.paused,
+		NextRun: s.nextRun,
+		LastRun: s.lastRun,
+	}
+}
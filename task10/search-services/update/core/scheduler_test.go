@@ -0,0 +1,186 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewUpdateScheduler_InvalidPeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, err := NewUpdateScheduler(logger, NewMockUpdater(ctrl), 0)
+	require.Error(t, err)
+}
+
+func TestUpdateScheduler_RunsUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	updater := NewMockUpdater(ctrl)
+	// прерванного обхода нет
+	updater.EXPECT().Update(gomock.Any(), UpdateOptions{Trigger: TriggerScheduled, Resume: true}).Return(ErrNotFound)
+
+	done := make(chan struct{})
+	updater.EXPECT().Update(gomock.Any(), UpdateOptions{Trigger: TriggerScheduled}).DoAndReturn(func(context.Context, UpdateOptions) error {
+		close(done)
+		return nil
+	})
+	updater.EXPECT().Update(gomock.Any(), UpdateOptions{Trigger: TriggerScheduled}).Return(nil).AnyTimes()
+
+	scheduler, err := NewUpdateScheduler(logger, updater, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheduler.Start(ctx)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduled update was not started")
+	}
+
+	require.Eventually(t, func() bool {
+		return !scheduler.Schedule(ctx).LastRun.IsZero()
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestUpdateScheduler_ResumesOnStart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	updater := NewMockUpdater(ctrl)
+
+	resumed := make(chan struct{})
+	updater.EXPECT().Update(gomock.Any(), UpdateOptions{Trigger: TriggerScheduled, Resume: true}).
+		DoAndReturn(func(context.Context, UpdateOptions) error {
+			close(resumed)
+			return nil
+		})
+
+	scheduler, err := NewUpdateScheduler(logger, updater, time.Hour)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheduler.Start(ctx)
+
+	select {
+	case <-resumed:
+	case <-time.After(time.Second):
+		t.Fatal("interrupted update was not resumed")
+	}
+}
+
+func TestUpdateScheduler_SkipsRunningUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	updater := NewMockUpdater(ctrl)
+
+	scheduler, err := NewUpdateScheduler(logger, updater, time.Hour)
+	require.NoError(t, err)
+
+	updater.EXPECT().Update(gomock.Any(), UpdateOptions{Trigger: TriggerScheduled}).Return(ErrAlreadyExists)
+
+	scheduler.nextRun = time.Now()
+	scheduler.run(context.Background())
+
+	schedule := scheduler.Schedule(context.Background())
+	require.True(t, schedule.LastRun.IsZero())
+	require.True(t, schedule.NextRun.After(time.Now()))
+
+	updater.EXPECT().Update(gomock.Any(), UpdateOptions{Trigger: TriggerScheduled}).Return(errors.New("xkcd error"))
+
+	scheduler.run(context.Background())
+	require.False(t, scheduler.Schedule(context.Background()).LastRun.IsZero())
+}
+
+func TestUpdateScheduler_SetSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scheduler, err := NewUpdateScheduler(logger, NewMockUpdater(ctrl), time.Hour)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	paused := true
+	schedule, err := scheduler.SetSchedule(ctx, ScheduleSettings{Paused: &paused})
+	require.NoError(t, err)
+	require.True(t, schedule.Paused)
+	require.True(t, schedule.NextRun.IsZero())
+	require.Equal(t, time.Hour, schedule.Period)
+
+	period := 2 * time.Hour
+	paused = false
+	schedule, err = scheduler.SetSchedule(ctx, ScheduleSettings{Period: &period, Paused: &paused})
+	require.NoError(t, err)
+	require.False(t, schedule.Paused)
+	require.Equal(t, period, schedule.Period)
+	require.WithinDuration(t, time.Now().Add(period), schedule.NextRun, time.Second)
+
+	for _, wrong := range []time.Duration{-time.Minute, time.Nanosecond, MinSchedulePeriod - time.Second} {
+		_, err = scheduler.SetSchedule(ctx, ScheduleSettings{Period: &wrong})
+		require.ErrorIs(t, err, ErrBadArguments)
+	}
+	require.Equal(t, period, scheduler.Schedule(ctx).Period)
+}
+
+func TestUpdateScheduler_PausedDuringRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	updater := NewMockUpdater(ctrl)
+	scheduler, err := NewUpdateScheduler(logger, updater, time.Nanosecond)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	paused := true
+	updater.EXPECT().Update(gomock.Any(), UpdateOptions{Trigger: TriggerScheduled}).
+		DoAndReturn(func(context.Context, UpdateOptions) error {
+			_, err := scheduler.SetSchedule(ctx, ScheduleSettings{Paused: &paused})
+			return err
+		})
+
+	scheduler.nextRun = time.Now()
+	scheduler.run(ctx)
+
+	schedule := scheduler.Schedule(ctx)
+	require.True(t, schedule.Paused)
+	require.True(t, schedule.NextRun.IsZero())
+}
+
+func TestServiceState_WithSchedule(t *testing.T) {
+	next := Schedule{NextRun: time.Now().Add(time.Hour)}
+
+	state := ServiceState{Status: StatusIdle}.WithSchedule(next)
+	require.Equal(t, StatusIdle, state.Status)
+	require.True(t, state.Scheduled)
+	require.Equal(t, next.NextRun, state.NextRun)
+
+	require.False(t, ServiceState{Status: StatusIdle}.WithSchedule(Schedule{Paused: true}).Scheduled)
+	require.False(t, ServiceState{Status: StatusIdle}.WithSchedule(Schedule{}).Scheduled)
+	// статус запуска расписание не перекрывает
+	require.Equal(t, StatusRunning, ServiceState{Status: StatusRunning}.WithSchedule(next).Status)
+}
+
+func TestNextTick(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	require.Equal(t, now.Add(time.Hour), nextTick(time.Time{}, time.Hour, now))
+	require.Equal(t, now.Add(time.Minute), nextTick(now.Add(time.Minute), time.Hour, now))
+	// запуск занял 2.5 периода - следующий тик через половину периода
+	require.Equal(t, now.Add(30*time.Minute), nextTick(now.Add(-150*time.Minute), time.Hour, now))
+	require.Equal(t, now.Add(time.Hour), nextTick(now, time.Hour, now))
+	// с крошечным периодом тоже без перебора
+	require.Equal(t, now.Add(time.Nanosecond), nextTick(time.Time{}.Add(time.Nanosecond), time.Nanosecond, now))
+}
@@ -3,203 +3,468 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Service struct {
-	mx          sync.Mutex
-	updateNow   bool
-	log         *slog.Logger
-	db          DB
-	xkcd        XKCD
-	words       Words
-	concurrency int
+	mx       sync.Mutex
+	stateMx  sync.RWMutex
+	state    ServiceState
+	cancel   context.CancelFunc
+	done     chan struct{}
+	log      *slog.Logger
+	db       DB
+	sources  *Registry
+	words    Words
+	pipeline PipelineOptions
+	meters   *meters
+	// retries - повторы источников к началу запуска, в статусе показываем только прирост
+	retries   int
+	batch     BatchOptions
+	snapshots int
+	lease     Lease
+	leaseTTL  time.Duration
 }
 
 func NewService(
-	log *slog.Logger, db DB, xkcd XKCD, words Words, concurrency int,
+	log *slog.Logger, db DB, sources *Registry, words Words, pipeline PipelineOptions, batch BatchOptions,
+	snapshots int, lease Lease,
 ) (*Service, error) {
-	if concurrency < 1 {
-		return nil, fmt.Errorf("wrong concurrency specified: %d", concurrency)
+	if sources == nil || sources.Len() == 0 {
+		return nil, fmt.Errorf("no comic sources specified")
+	}
+	if err := pipeline.validate(); err != nil {
+		return nil, err
+	}
+	if batch.Size < 1 {
+		return nil, fmt.Errorf("wrong batch size specified: %d", batch.Size)
+	}
+	if batch.FlushInterval <= 0 {
+		return nil, fmt.Errorf("wrong batch flush interval specified: %v", batch.FlushInterval)
+	}
+	if snapshots < 0 {
+		return nil, fmt.Errorf("wrong snapshots count specified: %d", snapshots)
 	}
 	return &Service{
-		log:         log,
-		db:          db,
-		xkcd:        xkcd,
-		words:       words,
-		concurrency: concurrency,
+		log:       log,
+		db:        db,
+		sources:   sources,
+		words:     words,
+		pipeline:  pipeline,
+		batch:     batch,
+		snapshots: snapshots,
+		lease:     lease,
+		leaseTTL:  defaultLeaseTTL,
+		state:     ServiceState{Status: StatusIdle},
 	}, nil
 }
 
-func (s *Service) Update(ctx context.Context) (err error) {
-	if !s.mx.TryLock() {
-		return ErrAlreadyExists
+func (s *Service) Update(ctx context.Context, opts UpdateOptions) error {
+	record := UpdateRun{Mode: ModeUpdate, Trigger: opts.Trigger}
+	if record.Trigger == "" {
+		record.Trigger = TriggerManual
+	}
+	if opts.Reconcile {
+		record.Mode = ModeReconcile
+	}
+	// продолжать нечего - такой запуск не попадает в журнал
+	if opts.Resume {
+		cp, err := s.db.Checkpoint(ctx)
+		if err != nil {
+			return err
+		}
+		record.Mode = cp.Mode
 	}
-	defer s.mx.Unlock()
 
-	s.updateNow = true
-	defer func() {
-		s.updateNow = false
-	}()
+	// под блокировкой отметка перечитывается: её мог закрыть запуск другой реплики
+	var cp Checkpoint
+	plan := func(ctx context.Context) ([]int, error) {
+		var err error
+		if cp, err = s.target(ctx, opts); err != nil {
+			return nil, err
+		}
+		return s.pending(ctx, cp)
+	}
+	return s.run(ctx, record, plan, nil, &cp)
+}
+
+// RetryFailed прогоняет через конвейер только комиксы из журнала ошибок
+func (s *Service) RetryFailed(ctx context.Context) error {
+	return s.run(ctx, UpdateRun{Mode: ModeRetry, Trigger: TriggerManual}, s.failedIDs, nil, nil)
+}
+
+// Refresh перечитывает сохранённые комиксы и переиндексирует только те,
+// у которых поменялось содержимое
+func (s *Service) Refresh(ctx context.Context, opts RefreshOptions) (RefreshResult, error) {
+	if opts.From < 0 || opts.To < 0 || (opts.To > 0 && opts.To < opts.From) {
+		return RefreshResult{}, fmt.Errorf("%w: wrong range %d..%d", ErrBadArguments, opts.From, opts.To)
+	}
+
+	var hashes map[int]string
+	var changed atomic.Int64
+
+	plan := func(ctx context.Context) ([]int, error) {
+		var err error
+		hashes, err = s.db.Hashes(ctx, opts.From, opts.To)
+		if err != nil {
+			s.log.Error("failed to retrieve comic hashes from database", "error", err)
+			return nil, err
+		}
+		return slices.Sorted(maps.Keys(hashes)), nil
+	}
+	keep := func(xkcd XKCDInfo) bool {
+		if hashes[xkcd.ID] == xkcd.Hash() {
+			return false
+		}
+		changed.Add(1)
+		return true
+	}
+
+	err := s.run(ctx, UpdateRun{Mode: ModeRefresh, Trigger: TriggerManual}, plan, keep, nil)
 
-	lastID, err := s.xkcd.LastID(ctx)
+	return RefreshResult{Checked: len(hashes), Changed: int(changed.Load())}, err
+}
+
+func (s *Service) ranges(ctx context.Context) ([]SourceRange, error) {
+	ranges, err := s.sources.Ranges(ctx)
 	if err != nil {
-		s.log.Error("failed to fetch last comic ID from XKCD", "error", err)
-		return err
+		s.log.Error("failed to fetch last comic IDs from sources", "error", err)
+		return nil, err
+	}
+	return ranges, nil
+}
+
+// пропуски ищет сама БД, в сервис приходят только недостающие ID
+func (s *Service) missingIn(ctx context.Context, ranges []SourceRange) ([]int, error) {
+	var missing []int
+	for _, r := range ranges {
+		ids, err := s.db.MissingIDs(ctx, r.From, r.To)
+		if err != nil {
+			s.log.Error("failed to retrieve missing comic IDs from database", "source", r.Source, "error", err)
+			return nil, err
+		}
+		missing = append(missing, ids...)
 	}
 
+	return missing, nil
+}
+
+// полная сверка всех сохранённых ID с диапазонами источников
+func (s *Service) reconcileIn(ctx context.Context, ranges []SourceRange) ([]int, error) {
 	IDs, err := s.db.IDs(ctx)
 	if err != nil {
 		s.log.Error("failed to retrieve existing comic IDs from database", "error", err)
-		return err
+		return nil, err
 	}
 
-	slices.Sort(IDs)
+	tombstones, err := s.db.Tombstones(ctx)
+	if err != nil {
+		s.log.Error("failed to retrieve tombstones from database", "error", err)
+		return nil, err
+	}
 
-	in1 := make(chan int)
+	// несуществующие комиксы (например, #404) повторно не запрашиваем
+	IDs = append(IDs, tombstones...)
+	slices.Sort(IDs)
 
-	go func() {
-		for i := 1; i <= lastID; i++ {
-			if ctx.Err() != nil {
-				break
+	var missing []int
+	for _, r := range ranges {
+		for id := r.From; id <= r.To; id++ {
+			if _, ok := slices.BinarySearch(IDs, id); !ok {
+				missing = append(missing, id)
 			}
-			in1 <- i
 		}
+	}
 
-		close(in1)
+	return missing, nil
+}
+
+func (s *Service) failedIDs(ctx context.Context) ([]int, error) {
+	failures, err := s.db.Failures(ctx)
+	if err != nil {
+		s.log.Error("failed to retrieve failed comics from database", "error", err)
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(failures))
+	for _, failure := range failures {
+		ids = append(ids, failure.ID)
+	}
+
+	return ids, nil
+}
+
+// keep отсеивает комиксы после загрузки, nil - пропускать все;
+// cp заполняет plan, nil - запуск без отметки обхода
+func (s *Service) run(
+	ctx context.Context, record UpdateRun, plan func(context.Context) ([]int, error), keep func(XKCDInfo) bool,
+	cp *Checkpoint,
+) (err error) {
+	unlock, lost, err := s.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// аренду перехватила другая реплика - дальше качать нельзя
+	go func() {
+		select {
+		case <-lost:
+			cancel(ErrLeaseLost)
+		case <-ctx.Done():
+		}
 	}()
 
-	var wg sync.WaitGroup
-	wg.Add(s.concurrency)
+	m := s.startRun(func() { cancel(ErrCancelled) })
+	defer func() {
+		s.finishRun(s.addRun(context.WithoutCancel(ctx), record, err))
+		// сохранённое даже при отмене должно попасть в индекс поиска
+		if s.progress().Stored > 0 {
+			s.changed(context.WithoutCancel(ctx), string(record.Mode))
+		}
+	}()
 
-	out1 := make([]chan XKCDInfo, s.concurrency)
-	for i := 0; i < s.concurrency; i++ {
-		out1[i] = make(chan XKCDInfo)
-		// XKCD GET() Получить JSON
-		go s.xkcdGet(ctx, IDs, in1, out1[i])
+	missing, err := plan(ctx)
+	if err != nil {
+		if errors.Is(context.Cause(ctx), ErrCancelled) {
+			s.log.Info("update cancelled while planning")
+			return ErrCancelled
+		}
+		return err
 	}
 
-	in2 := merge1(out1...)
+	s.planned(len(missing))
 
-	out2 := make([]chan Comics, s.concurrency)
-	for i := 0; i < s.concurrency; i++ {
-		out2[i] = make(chan Comics)
-		// WORDS.NORM() Нормализация
-		go s.norm(ctx, in2, out2[i])
+	var t *tracker
+	if cp != nil {
+		t = s.startCheckpoint(context.WithoutCancel(ctx), *cp, missing)
+		defer func() { s.finishCheckpoint(context.WithoutCancel(ctx), t, err) }()
 	}
 
-	in3 := merge2(out2...)
+	// у каждой стадии один входной канал на всех её воркеров
+	in1 := make(chan int, s.pipeline.Fetch.Buffer)
+	in2 := make(chan XKCDInfo, s.pipeline.Norm.Buffer)
+	in3 := make(chan Comics, s.pipeline.Add.Buffer)
+	s.watchQueues(m, in1, in2, in3)
+
+	go func() {
+		defer close(in1)
 
-	for i := 0; i < s.concurrency; i++ {
-		// DATABASE.ADD() Сохранение в БД
-		go s.add(ctx, in3, &wg)
+		for _, id := range missing {
+			select {
+			case in1 <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// XKCD GET() Получить JSON
+	spawn(s.pipeline.Fetch.Workers, in2, func() { s.xkcdGet(ctx, cancel, keep, m, t, in1, in2) })
+
+	// WORDS.NORM() Нормализация
+	spawn(s.pipeline.Norm.Workers, in3, func() { s.norm(ctx, m, t, in2, in3) })
+
+	// DATABASE.ADD() Сохранение в БД
+	// уже нормализованные комиксы сохраняем и после отмены
+	var wg sync.WaitGroup
+	wg.Add(s.pipeline.Add.Workers)
+	for range s.pipeline.Add.Workers {
+		go s.add(context.WithoutCancel(ctx), m, t, in3, &wg)
 	}
 
 	wg.Wait()
 
+	if ctx.Err() != nil {
+		if cause := context.Cause(ctx); errors.Is(cause, ErrUpstreamUnavailable) {
+			s.log.Error("update aborted, XKCD is unavailable", "error", cause)
+			return cause
+		} else if errors.Is(cause, ErrLeaseLost) {
+			s.log.Error("update aborted, lease is lost", "error", cause)
+			return cause
+		}
+		s.log.Info("update cancelled", "stored", s.progress().Stored)
+		return ErrCancelled
+	}
+
 	return nil
 }
 
-func (s *Service) xkcdGet(ctx context.Context, ids []int, in chan int, out chan XKCDInfo) {
+func (s *Service) xkcdGet(
+	ctx context.Context, abort context.CancelCauseFunc, keep func(XKCDInfo) bool, m *meters, t *tracker,
+	in chan int, out chan XKCDInfo,
+) {
 	for id := range in {
 		if ctx.Err() != nil {
 			break
 		}
-		if _, ok := slices.BinarySearch(ids, id); !ok {
-			xkcd, err := s.xkcd.Get(ctx, id)
-			if err != nil {
-				s.log.Error("failed to fetch comic from XKCD API", "comic_id", id, "error", err)
+
+		name, source, sourceID, ok := s.sources.Lookup(id)
+		if !ok {
+			s.log.Error("no source for comic", "comic_id", id)
+			s.fail(ctx, id, StageFetch, fmt.Errorf("%w: no source for comic %d", ErrNotFound, id))
+			t.commit(id)
+			continue
+		}
+
+		started := time.Now()
+		xkcd, err := source.Get(ctx, sourceID)
+		m.fetch.observe(1, started)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			// upstream лежит - нет смысла дёргать его по каждому комиксу
+			if errors.Is(err, ErrUpstreamUnavailable) {
+				abort(err)
+				break
+			}
+			if errors.Is(err, ErrNotFound) {
+				s.tombstone(ctx, id)
+				t.commit(id)
 				continue
 			}
+			s.log.Error("failed to fetch comic from source", "source", name, "comic_id", id, "error", err)
+			s.fail(ctx, id, StageFetch, err)
+			t.commit(id)
+			continue
+		}
+		// в БД комикс лежит под ID из диапазона источника
+		xkcd.ID, xkcd.Source = id, name
+		s.track(func(p *UpdateProgress) { p.Fetched++ })
 
-			out <- xkcd
+		if keep != nil && !keep(xkcd) {
+			s.track(func(p *UpdateProgress) { p.Unchanged++ })
+			t.commit(id)
+			continue
 		}
+
+		out <- xkcd
 	}
-	close(out)
 }
 
-func (s *Service) norm(ctx context.Context, in chan XKCDInfo, out chan Comics) {
+func (s *Service) norm(ctx context.Context, m *meters, t *tracker, in chan XKCDInfo, out chan Comics) {
 	for xkcd := range in {
+		started := time.Now()
 		words, err := s.words.Norm(ctx, xkcd.Description)
+		m.norm.observe(1, started)
 		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
 			s.log.Error("failed to process comic keywords", "comic_id", xkcd.ID, "error", err)
+			s.fail(ctx, xkcd.ID, StageNorm, err)
+			t.commit(xkcd.ID)
 			continue
 		}
-
-		out <- Comics{ID: xkcd.ID, URL: xkcd.URL, Words: words}
+		s.track(func(p *UpdateProgress) { p.Normalized++ })
+
+		out <- Comics{
+			ID:       xkcd.ID,
+			URL:      xkcd.URL,
+			Words:    words,
+			Hash:     xkcd.Hash(),
+			Source:   xkcd.Source,
+			Metadata: xkcd.Metadata,
+		}
 	}
-
-	close(out)
 }
 
-func (s *Service) add(ctx context.Context, in chan Comics, wg *sync.WaitGroup) {
+// комиксы копятся в пачку и пишутся в БД по заполнению или по таймеру
+func (s *Service) add(ctx context.Context, m *meters, t *tracker, in chan Comics, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	for comics := range in {
-		err := s.db.Add(ctx, comics)
-		if err != nil {
-			s.log.Error("failed to persist comic in database", "comic_id", comics.ID, "error", err)
-			continue
+	ticker := time.NewTicker(s.batch.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Comics, 0, s.batch.Size)
+	flush := func() {
+		if len(batch) > 0 {
+			started := time.Now()
+			s.store(ctx, batch)
+			m.add.observe(len(batch), started)
+			// и сохранённый, и упавший при записи комикс обработан
+			for _, comics := range batch {
+				t.commit(comics.ID)
+			}
+			batch = batch[:0]
 		}
 	}
-}
-
-func merge1(in ...chan XKCDInfo) chan XKCDInfo {
-	out := make(chan XKCDInfo)
 
-	var wg sync.WaitGroup
-	wg.Add(len(in))
-	for _, ch := range in {
-		go func() {
-			defer wg.Done()
-			for xkcd := range ch {
-				out <- xkcd
+	for {
+		select {
+		case comics, ok := <-in:
+			if !ok {
+				flush()
+				return
 			}
-		}()
+			batch = append(batch, comics)
+			if len(batch) >= s.batch.Size {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
 	}
-
-	go func() {
-		wg.Wait()
-		close(out)
-	}()
-
-	return out
 }
 
-func merge2(in ...chan Comics) chan Comics {
-	out := make(chan Comics)
+func (s *Service) store(ctx context.Context, batch []Comics) {
+	if err := s.db.AddBatch(ctx, batch); err != nil {
+		s.log.Error("failed to persist comics batch in database", "size", len(batch), "error", err)
+		for _, comics := range batch {
+			s.fail(ctx, comics.ID, StageAdd, err)
+		}
+		return
+	}
+	s.track(func(p *UpdateProgress) { p.Stored += len(batch) })
+}
 
-	var wg sync.WaitGroup
-	wg.Add(len(in))
+func (s *Service) tombstone(ctx context.Context, id int) {
+	s.log.Info("comic does not exist, skipping it in next updates", "comic_id", id)
+	// такого комикса нет, это не работа конвейера
+	s.track(func(p *UpdateProgress) { p.Total-- })
 
-	for _, ch := range in {
-		go func() {
-			defer wg.Done()
-			for comics := range ch {
-				out <- comics
-			}
-		}()
+	if err := s.db.AddTombstone(context.WithoutCancel(ctx), id); err != nil {
+		s.log.Error("failed to record tombstone", "comic_id", id, "error", err)
 	}
+}
 
-	go func() {
-		wg.Wait()
-		close(out)
-	}()
+// ошибка попадает в журнал, чтобы комикс можно было перезапросить через RetryFailed
+func (s *Service) fail(ctx context.Context, id int, stage FailureStage, err error) {
+	s.track(func(p *UpdateProgress) { p.Failed++ })
 
-	return out
+	failure := Failure{ID: id, Stage: stage, Error: err.Error()}
+	if err := s.db.AddFailure(context.WithoutCancel(ctx), failure); err != nil {
+		s.log.Error("failed to record comic failure", "comic_id", id, "stage", stage, "error", err)
+	}
 }
 
 func (s *Service) Stats(ctx context.Context) (ServiceStats, error) {
-	lastID, err := s.xkcd.LastID(ctx)
-	comicsTotal := lastID - 1 // т.к. ресурс под индексом 404 not found
+	ranges, err := s.sources.Ranges(ctx)
+	if err != nil {
+		s.log.Error("failed to fetch last comic IDs from sources", "error", err)
+		return ServiceStats{}, err
+	}
 
+	tombstones, err := s.db.Tombstones(ctx)
 	if err != nil {
-		s.log.Error("failed to fetch last comic ID from XKCD", "error", err)
+		s.log.Error("failed to retrieve tombstones from database", "error", err)
 		return ServiceStats{}, err
 	}
+	comicsTotal := -len(tombstones)
+	for _, r := range ranges {
+		comicsTotal += r.To - r.From + 1
+	}
 
 	DBstat, err := s.db.Stats(ctx)
 	if err != nil {
@@ -212,14 +477,189 @@ func (s *Service) Stats(ctx context.Context) (ServiceStats, error) {
 	return ServiceStats{DBStats: DBstat, ComicsTotal: comicsTotal}, nil
 }
 
-func (s *Service) Status(ctx context.Context) ServiceStatus {
-	if s.updateNow {
-		return StatusRunning
-	} else {
-		return StatusIdle
+func (s *Service) Failures(ctx context.Context) ([]Failure, error) {
+	return s.db.Failures(ctx)
+}
+
+func (s *Service) Status(ctx context.Context) ServiceState {
+	s.stateMx.RLock()
+	state := s.state
+	retries := s.retries
+	s.stateMx.RUnlock()
+
+	state.XKCD = s.sources.State()
+	state.XKCD.Retries -= retries
+	state.Pipeline = s.pipelineStats()
+	if s.lease == nil {
+		return state
 	}
+
+	holder, err := s.lease.Holder(ctx)
+	if err != nil {
+		s.log.Warn("failed to retrieve update lease holder", "error", err)
+		return state
+	}
+	state.Replica = holder.Replica
+	// обновление идёт на другой реплике, её прогресс нам не виден
+	if holder.Replica != "" && !state.Status.Active() {
+		state.Status = StatusRunning
+		state.Progress = UpdateProgress{StartedAt: holder.AcquiredAt}
+	}
+	return state
 }
 
-func (s *Service) Drop(ctx context.Context) error {
-	return s.db.Drop(ctx)
+func (s *Service) progress() UpdateProgress {
+	s.stateMx.RLock()
+	defer s.stateMx.RUnlock()
+
+	return s.state.Progress
+}
+
+func (s *Service) Cancel(ctx context.Context) (ServiceState, error) {
+	// статус меняется под той же блокировкой, иначе запуск мог бы успеть завершиться
+	s.stateMx.Lock()
+	cancel, done := s.cancel, s.done
+	if cancel != nil {
+		s.state.Status = StatusCancelling
+	}
+	s.stateMx.Unlock()
+
+	if cancel == nil {
+		return ServiceState{}, ErrNotFound
+	}
+
+	s.log.Info("cancelling update")
+	cancel()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ServiceState{}, ctx.Err()
+	}
+
+	return s.Status(ctx), nil
+}
+
+func (s *Service) startRun(cancel context.CancelFunc) *meters {
+	s.stateMx.Lock()
+	defer s.stateMx.Unlock()
+
+	s.state = ServiceState{
+		Status:   StatusRunning,
+		Progress: UpdateProgress{StartedAt: time.Now()},
+		LastRun:  s.state.LastRun,
+	}
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.meters = newMeters(s.pipeline)
+	s.retries = s.sources.State().Retries
+	return s.meters
+}
+
+// watchQueues даёт метрикам доступ к глубине входных очередей стадий
+func (s *Service) watchQueues(m *meters, fetch chan int, norm chan XKCDInfo, add chan Comics) {
+	s.stateMx.Lock()
+	defer s.stateMx.Unlock()
+
+	m.fetch.queue = func() int { return len(fetch) }
+	m.norm.queue = func() int { return len(norm) }
+	m.add.queue = func() int { return len(add) }
+}
+
+// pipelineStats отдаёт метрики текущего или последнего запуска,
+// до первого запуска - только настройки стадий
+func (s *Service) pipelineStats() PipelineStats {
+	s.stateMx.RLock()
+	defer s.stateMx.RUnlock()
+
+	if s.meters == nil {
+		return newMeters(s.pipeline).stats()
+	}
+	return s.meters.stats()
+}
+
+// planned фиксирует, сколько комиксов выбрано для загрузки
+func (s *Service) planned(total int) {
+	s.stateMx.Lock()
+	defer s.stateMx.Unlock()
+
+	s.state.Progress.Total = total
+}
+
+// прогресс и итог последнего запуска остаются доступными после его завершения
+func (s *Service) finishRun(run UpdateRun) {
+	s.stateMx.Lock()
+	defer s.stateMx.Unlock()
+
+	switch run.Status {
+	case RunCancelled:
+		s.state.Status = StatusCancelled
+	case RunFailed:
+		s.state.Status = StatusFailed
+	default:
+		s.state.Status = StatusIdle
+	}
+	s.state.LastRun = run
+	s.meters.finished = run.FinishedAt
+	s.cancel = nil
+	close(s.done)
+	s.done = nil
+}
+
+// changed сообщает поиску, что комиксы в БД поменялись; без уведомления
+// индекс всё равно перестроится по своему таймеру
+func (s *Service) changed(ctx context.Context, reason string) {
+	if err := s.db.NotifyChanged(ctx, reason); err != nil {
+		s.log.Warn("failed to notify about changed comics", "reason", reason, "error", err)
+	}
+}
+
+// addRun записывает завершённый запуск в журнал; сбой журнала не делает
+// неудачным само обновление
+func (s *Service) addRun(ctx context.Context, record UpdateRun, err error) UpdateRun {
+	progress := s.progress()
+
+	record.StartedAt = progress.StartedAt
+	record.FinishedAt = time.Now()
+	record.Stored = progress.Stored
+	record.Failed = progress.Failed
+	record.Skipped = max(progress.Total-progress.Stored-progress.Failed, 0)
+
+	switch {
+	case err == nil:
+		record.Status = RunCompleted
+	case errors.Is(err, ErrCancelled):
+		record.Status = RunCancelled
+	default:
+		record.Status = RunFailed
+		record.Error = err.Error()
+	}
+
+	if err := s.db.AddRun(ctx, record); err != nil {
+		s.log.Error("failed to record update run", "error", err)
+	}
+	return record
+}
+
+// больше записей журнала за один запрос не отдаём
+const maxHistoryLimit = 100
+
+// History отдаёт журнал запусков постранично, от новых к старым
+func (s *Service) History(ctx context.Context, opts HistoryOptions) (History, error) {
+	if opts.Limit < 1 || opts.Limit > maxHistoryLimit || opts.Offset < 0 {
+		return History{}, fmt.Errorf("%w: wrong page limit %d offset %d", ErrBadArguments, opts.Limit, opts.Offset)
+	}
+
+	runs, total, err := s.db.Runs(ctx, opts.Limit, opts.Offset)
+	if err != nil {
+		return History{}, err
+	}
+	return History{Runs: runs, Total: total}, nil
+}
+
+func (s *Service) track(update func(*UpdateProgress)) {
+	s.stateMx.Lock()
+	defer s.stateMx.Unlock()
+
+	update(&s.state.Progress)
 }
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -14,15 +16,67 @@ import (
 
 var logger = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
 
+// по одному комиксу в пачке, чтобы ожидания в моках не зависели от порядка
+var batch = BatchOptions{Size: 1, FlushInterval: time.Second}
+
+// expectRun ожидает запись запуска в журнал с заданными режимом и итогом
+func expectRun(db *MockDB, mode RunMode, status RunStatus) {
+	db.EXPECT().AddRun(gomock.Any(), gomock.Cond(func(run UpdateRun) bool {
+		return run.Mode == mode && run.Status == status && run.Trigger == TriggerManual
+	}))
+}
+
+// expectCheckpoints разрешает запуску Update вести отметку обхода
+func expectCheckpoints(db *MockDB) {
+	db.EXPECT().StartCheckpoint(gomock.Any(), gomock.Any()).Return(int64(1), nil).AnyTimes()
+	db.EXPECT().SaveCheckpoint(gomock.Any(), int64(1), gomock.Any()).Return(nil).AnyTimes()
+	db.EXPECT().FinishCheckpoint(gomock.Any(), int64(1)).Return(nil).AnyTimes()
+}
+
+// pipeline - n воркеров на каждой стадии без буферов
+func pipeline(n int) PipelineOptions {
+	stage := StageOptions{Workers: n}
+	return PipelineOptions{Fetch: stage, Norm: stage, Add: stage}
+}
+
+func single(t *testing.T, source Source) *Registry {
+	sources := NewRegistry()
+	require.NoError(t, sources.Register("xkcd", 0, source))
+	return sources
+}
+
 func TestNewService_InvalidConcurrency(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	db := NewMockDB(ctrl)
-	xkcd := NewMockXKCD(ctrl)
+	xkcd := NewMockSource(ctrl)
 	words := NewMockWords(ctrl)
 
-	_, err := NewService(logger, db, xkcd, words, 0)
+	_, err := NewService(logger, db, single(t, xkcd), words, pipeline(0), batch, 0, nil)
+	require.Error(t, err)
+}
+
+func TestNewService_InvalidBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	_, err := NewService(logger, db, single(t, xkcd), words, pipeline(1), BatchOptions{Size: 0, FlushInterval: time.Second}, 0, nil)
+	require.Error(t, err)
+
+	_, err = NewService(logger, db, single(t, xkcd), words, pipeline(1), BatchOptions{Size: 10}, 0, nil)
+	require.Error(t, err)
+}
+
+func TestNewService_NoSources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, err := NewService(logger, NewMockDB(ctrl), NewRegistry(), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
 	require.Error(t, err)
 }
 
@@ -31,10 +85,10 @@ func TestNewService_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	db := NewMockDB(ctrl)
-	xkcd := NewMockXKCD(ctrl)
+	xkcd := NewMockSource(ctrl)
 	words := NewMockWords(ctrl)
 
-	_, err := NewService(logger, db, xkcd, words, 10)
+	_, err := NewService(logger, db, single(t, xkcd), words, pipeline(10), batch, 0, nil)
 	require.NoError(t, err)
 }
 
@@ -43,18 +97,20 @@ func TestUpdate_XKCDLastIDError(t *testing.T) {
 	defer ctrl.Finish()
 
 	db := NewMockDB(ctrl)
-	xkcd := NewMockXKCD(ctrl)
+	xkcd := NewMockSource(ctrl)
 	words := NewMockWords(ctrl)
 
 	concurrency := 2
-	svc, err := NewService(logger, db, xkcd, words, concurrency)
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(concurrency), batch, 0, nil)
 	require.NoError(t, err)
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
 
 	ctx := context.Background()
 
 	xkcd.EXPECT().LastID(gomock.Any()).Return(0, errors.New("xkcd error"))
 
-	err = svc.Update(ctx)
+	expectRun(db, ModeUpdate, RunFailed)
+	err = svc.Update(ctx, UpdateOptions{})
 	require.Error(t, err)
 }
 
@@ -63,25 +119,33 @@ func TestUpdate_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	db := NewMockDB(ctrl)
-	xkcd := NewMockXKCD(ctrl)
+	xkcd := NewMockSource(ctrl)
 	words := NewMockWords(ctrl)
 
 	concurrency := 10
-	svc, err := NewService(logger, db, xkcd, words, concurrency)
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(concurrency), batch, 0, nil)
 	require.NoError(t, err)
+	expectCheckpoints(db)
+	db.EXPECT().NotifyChanged(gomock.Any(), "update").Return(nil)
 
 	ctx := context.Background()
 	lastID := 1000
 
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
 	xkcd.EXPECT().LastID(gomock.Any()).Return(lastID, nil)
 
-	db.EXPECT().IDs(gomock.Any()).Return([]int{}, nil)
+	missing := make([]int, 0, lastID)
+	for id := 1; id <= lastID; id++ {
+		missing = append(missing, id)
+	}
+	db.EXPECT().MissingIDs(gomock.Any(), 1, lastID).Return(missing, nil)
 
 	for id := 1; id <= lastID; id++ {
 		comic := XKCDInfo{
 			ID:          id,
 			URL:         fmt.Sprintf("url%d", id),
 			Description: fmt.Sprintf("comic %d", id),
+			Metadata:    Metadata{Title: fmt.Sprintf("title %d", id)},
 		}
 		xkcd.EXPECT().Get(gomock.Any(), id).Return(comic, nil)
 
@@ -89,15 +153,314 @@ func TestUpdate_Success(t *testing.T) {
 			Return([]string{"comic", fmt.Sprintf("%d", id)}, nil)
 
 		comics := Comics{
-			ID:    id,
-			URL:   fmt.Sprintf("url%d", id),
-			Words: []string{"comic", fmt.Sprintf("%d", id)},
+			ID:       id,
+			URL:      fmt.Sprintf("url%d", id),
+			Words:    []string{"comic", fmt.Sprintf("%d", id)},
+			Hash:     comic.Hash(),
+			Source:   "xkcd",
+			Metadata: Metadata{Title: fmt.Sprintf("title %d", id)},
 		}
-		db.EXPECT().Add(gomock.Any(), comics).Return(nil)
+		db.EXPECT().AddBatch(gomock.Any(), []Comics{comics}).Return(nil)
 	}
 
-	err = svc.Update(ctx)
+	expectRun(db, ModeUpdate, RunCompleted)
+	err = svc.Update(ctx, UpdateOptions{})
+	require.NoError(t, err)
+
+	progress := svc.Status(ctx).Progress
+	require.Equal(t, lastID, progress.Total)
+	require.Equal(t, lastID, progress.Fetched)
+	require.Equal(t, lastID, progress.Normalized)
+	require.Equal(t, lastID, progress.Stored)
+	require.Equal(t, 0, progress.Failed)
+}
+
+func TestUpdate_Progress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(2), batch, 0, nil)
+	require.NoError(t, err)
+	expectCheckpoints(db)
+	db.EXPECT().NotifyChanged(gomock.Any(), "update").Return(nil)
+
+	ctx := context.Background()
+
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	xkcd.EXPECT().LastID(gomock.Any()).Return(4, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1, 4).Return([]int{2, 3, 4}, nil)
+
+	xkcd.EXPECT().Get(gomock.Any(), 2).Return(XKCDInfo{ID: 2, Description: "two"}, nil)
+	xkcd.EXPECT().Get(gomock.Any(), 3).Return(XKCDInfo{}, errors.New("xkcd error"))
+	xkcd.EXPECT().Get(gomock.Any(), 4).Return(XKCDInfo{ID: 4, Description: "four"}, nil)
+
+	words.EXPECT().Norm(gomock.Any(), "two").Return([]string{"two"}, nil)
+	words.EXPECT().Norm(gomock.Any(), "four").Return(nil, errors.New("words error"))
+
+	db.EXPECT().AddBatch(gomock.Any(), []Comics{{ID: 2, Words: []string{"two"}, Hash: XKCDInfo{ID: 2, Description: "two"}.Hash(), Source: "xkcd"}}).Return(nil)
+
+	db.EXPECT().AddFailure(gomock.Any(), Failure{ID: 3, Stage: StageFetch, Error: "xkcd error"}).Return(nil)
+	db.EXPECT().AddFailure(gomock.Any(), Failure{ID: 4, Stage: StageNorm, Error: "words error"}).Return(nil)
+
+	expectRun(db, ModeUpdate, RunCompleted)
+	require.NoError(t, svc.Update(ctx, UpdateOptions{}))
+
+	state := svc.Status(ctx)
+	require.Equal(t, StatusIdle, state.Status)
+	require.Equal(t, UpdateProgress{
+		Total:      3,
+		Fetched:    2,
+		Normalized: 1,
+		Stored:     1,
+		Failed:     2,
+		StartedAt:  state.Progress.StartedAt,
+	}, state.Progress)
+}
+
+func TestUpdate_Reconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+	expectCheckpoints(db)
+	db.EXPECT().NotifyChanged(gomock.Any(), "reconcile").Return(nil)
+
+	ctx := context.Background()
+
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	xkcd.EXPECT().LastID(gomock.Any()).Return(4, nil)
+	db.EXPECT().IDs(gomock.Any()).Return([]int{1}, nil)
+	db.EXPECT().Tombstones(gomock.Any()).Return([]int{2}, nil)
+
+	xkcd.EXPECT().Get(gomock.Any(), 3).Return(XKCDInfo{}, ErrNotFound)
+	db.EXPECT().AddTombstone(gomock.Any(), 3).Return(nil)
+
+	xkcd.EXPECT().Get(gomock.Any(), 4).Return(XKCDInfo{ID: 4, Description: "four"}, nil)
+	words.EXPECT().Norm(gomock.Any(), "four").Return([]string{"four"}, nil)
+	db.EXPECT().AddBatch(gomock.Any(), []Comics{{ID: 4, Words: []string{"four"}, Hash: XKCDInfo{ID: 4, Description: "four"}.Hash(), Source: "xkcd"}}).Return(nil)
+
+	expectRun(db, ModeReconcile, RunCompleted)
+	require.NoError(t, svc.Update(ctx, UpdateOptions{Reconcile: true}))
+
+	progress := svc.Status(ctx).Progress
+	require.Equal(t, 1, progress.Total)
+	require.Equal(t, 1, progress.Stored)
+	require.Equal(t, 0, progress.Failed)
+}
+
+func TestUpdate_Sources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	local := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	sources := NewRegistry()
+	require.NoError(t, sources.Register("xkcd", 0, xkcd))
+	require.NoError(t, sources.Register("local", 1000, local))
+
+	svc, err := NewService(logger, db, sources, words, pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	local.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	expectCheckpoints(db)
+	db.EXPECT().NotifyChanged(gomock.Any(), "update").Return(nil)
+
+	xkcd.EXPECT().LastID(gomock.Any()).Return(2, nil)
+	local.EXPECT().LastID(gomock.Any()).Return(1, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1, 2).Return([]int{2}, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1001, 1001).Return([]int{1001}, nil)
+
+	two := XKCDInfo{ID: 2, Description: "two"}
+	xkcd.EXPECT().Get(gomock.Any(), 2).Return(two, nil)
+	// источник отдаёт свой номер, в БД комикс попадает со сдвигом
+	fixture := XKCDInfo{ID: 1, Description: "fixture"}
+	local.EXPECT().Get(gomock.Any(), 1).Return(fixture, nil)
+
+	words.EXPECT().Norm(gomock.Any(), "two").Return([]string{"two"}, nil)
+	words.EXPECT().Norm(gomock.Any(), "fixture").Return([]string{"fixture"}, nil)
+
+	db.EXPECT().AddBatch(gomock.Any(), []Comics{{ID: 2, Words: []string{"two"}, Hash: two.Hash(), Source: "xkcd"}}).
+		Return(nil)
+	db.EXPECT().AddBatch(gomock.Any(), []Comics{{ID: 1001, Words: []string{"fixture"}, Hash: fixture.Hash(), Source: "local"}}).
+		Return(nil)
+
+	expectRun(db, ModeUpdate, RunCompleted)
+	require.NoError(t, svc.Update(context.Background(), UpdateOptions{}))
+}
+
+func TestUpdate_MissingIDsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+
+	expected := errors.New("db error")
+	xkcd.EXPECT().LastID(gomock.Any()).Return(10, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1, 10).Return(nil, expected)
+
+	expectRun(db, ModeUpdate, RunFailed)
+	require.ErrorIs(t, svc.Update(context.Background(), UpdateOptions{}), expected)
+}
+
+func TestRetryFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+	db.EXPECT().NotifyChanged(gomock.Any(), "retry").Return(nil)
+
+	ctx := context.Background()
+
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	db.EXPECT().Failures(gomock.Any()).Return([]Failure{
+		{ID: 5, Stage: StageFetch, Attempts: 1},
+		{ID: 9, Stage: StageAdd, Attempts: 2},
+	}, nil)
+
+	xkcd.EXPECT().Get(gomock.Any(), 5).Return(XKCDInfo{ID: 5, Description: "five"}, nil)
+	xkcd.EXPECT().Get(gomock.Any(), 9).Return(XKCDInfo{ID: 9, Description: "nine"}, nil)
+	words.EXPECT().Norm(gomock.Any(), "five").Return([]string{"five"}, nil)
+	words.EXPECT().Norm(gomock.Any(), "nine").Return([]string{"nine"}, nil)
+	db.EXPECT().AddBatch(gomock.Any(), []Comics{{ID: 5, Words: []string{"five"}, Hash: XKCDInfo{ID: 5, Description: "five"}.Hash(), Source: "xkcd"}}).Return(nil)
+	db.EXPECT().AddBatch(gomock.Any(), []Comics{{ID: 9, Words: []string{"nine"}, Hash: XKCDInfo{ID: 9, Description: "nine"}.Hash(), Source: "xkcd"}}).Return(errors.New("db error"))
+	db.EXPECT().AddFailure(gomock.Any(), Failure{ID: 9, Stage: StageAdd, Error: "db error"}).Return(nil)
+
+	expectRun(db, ModeRetry, RunCompleted)
+	require.NoError(t, svc.RetryFailed(ctx))
+
+	progress := svc.Status(ctx).Progress
+	require.Equal(t, 2, progress.Total)
+	require.Equal(t, 1, progress.Stored)
+	require.Equal(t, 1, progress.Failed)
+}
+
+func TestRetryFailed_DBError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	svc, err := NewService(logger, db, single(t, xkcd), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+
+	expected := errors.New("db error")
+	db.EXPECT().Failures(gomock.Any()).Return(nil, expected)
+
+	expectRun(db, ModeRetry, RunFailed)
+	require.ErrorIs(t, svc.RetryFailed(context.Background()), expected)
+}
+
+func TestCancel_NotRunning(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc, err := NewService(logger, NewMockDB(ctrl), single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	_, err = svc.Cancel(context.Background())
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCancel_Running(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+	expectCheckpoints(db)
+
+	ctx := context.Background()
+
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	xkcd.EXPECT().LastID(gomock.Any()).Return(3, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1, 3).Return([]int{1, 2, 3}, nil)
+
+	xkcd.EXPECT().Get(gomock.Any(), 1).Return(XKCDInfo{ID: 1, Description: "one"}, nil)
+	xkcd.EXPECT().Get(gomock.Any(), 2).DoAndReturn(func(ctx context.Context, _ int) (XKCDInfo, error) {
+		<-ctx.Done()
+		return XKCDInfo{}, ctx.Err()
+	})
+	words.EXPECT().Norm(gomock.Any(), "one").Return([]string{"one"}, nil)
+	db.EXPECT().AddBatch(gomock.Any(), []Comics{{ID: 1, Words: []string{"one"}, Hash: XKCDInfo{ID: 1, Description: "one"}.Hash(), Source: "xkcd"}}).Return(nil)
+
+	expectRun(db, ModeUpdate, RunCancelled)
+	// сохранённый до отмены комикс тоже должен попасть в индекс
+	db.EXPECT().NotifyChanged(gomock.Any(), "update").Return(nil)
+	result := make(chan error)
+	go func() {
+		result <- svc.Update(ctx, UpdateOptions{})
+	}()
+
+	require.Eventually(t, func() bool {
+		return svc.Status(ctx).Progress.Stored == 1
+	}, time.Second, time.Millisecond)
+
+	state, err := svc.Cancel(ctx)
 	require.NoError(t, err)
+	require.Equal(t, StatusCancelled, state.Status)
+	require.Equal(t, 1, state.Progress.Stored)
+	require.Equal(t, 0, state.Progress.Failed)
+
+	require.ErrorIs(t, <-result, ErrCancelled)
+}
+
+func TestUpdate_UpstreamUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+	expectCheckpoints(db)
+
+	ctx := context.Background()
+
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerOpen}).AnyTimes()
+	xkcd.EXPECT().LastID(gomock.Any()).Return(100, nil)
+	db.EXPECT().MissingIDs(gomock.Any(), 1, 100).Return([]int{98, 99, 100}, nil)
+
+	xkcd.EXPECT().Get(gomock.Any(), 98).Return(XKCDInfo{}, ErrUpstreamUnavailable)
+
+	expectRun(db, ModeUpdate, RunFailed)
+	require.ErrorIs(t, svc.Update(ctx, UpdateOptions{}), ErrUpstreamUnavailable)
+
+	state := svc.Status(ctx)
+	require.Equal(t, StatusFailed, state.Status)
+	require.Equal(t, RunFailed, state.LastRun.Status)
+	require.Equal(t, ErrUpstreamUnavailable.Error(), state.LastRun.Error)
+	require.Equal(t, 3, state.Progress.Total)
+	require.Equal(t, 0, state.Progress.Failed)
+	require.Equal(t, BreakerOpen, state.XKCD.Breaker)
 }
 
 func TestStats_Success(t *testing.T) {
@@ -105,11 +468,11 @@ func TestStats_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	db := NewMockDB(ctrl)
-	xkcd := NewMockXKCD(ctrl)
+	xkcd := NewMockSource(ctrl)
 	words := NewMockWords(ctrl)
 
 	concurrency := 1
-	svc, err := NewService(logger, db, xkcd, words, concurrency)
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(concurrency), batch, 0, nil)
 	require.NoError(t, err)
 
 	ctx := context.Background()
@@ -117,6 +480,7 @@ func TestStats_Success(t *testing.T) {
 	expectedTotal := lastID - 1
 
 	xkcd.EXPECT().LastID(gomock.Any()).Return(lastID, nil)
+	db.EXPECT().Tombstones(gomock.Any()).Return([]int{404}, nil)
 
 	dummyDBStats := DBStats{
 		WordsTotal:    100,
@@ -138,20 +502,249 @@ func TestStatus(t *testing.T) {
 	defer ctrl.Finish()
 
 	db := NewMockDB(ctrl)
-	xkcd := NewMockXKCD(ctrl)
+	xkcd := NewMockSource(ctrl)
 	words := NewMockWords(ctrl)
 
 	concurrency := 1
-	svc, err := NewService(logger, db, xkcd, words, concurrency)
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(concurrency), batch, 0, nil)
 	require.NoError(t, err)
 
+	xkcd.EXPECT().State().Return(XKCDState{Retries: 2, Breaker: BreakerOpen}).AnyTimes()
+
 	status1 := svc.Status(context.Background())
-	require.Equal(t, StatusIdle, status1)
+	require.Equal(t, StatusIdle, status1.Status)
+	require.Equal(t, XKCDState{Retries: 2, Breaker: BreakerOpen}, status1.XKCD)
 
-	svc.updateNow = true
+	svc.startRun(func() {})
 
+	// пока выбираются комиксы, запуск уже идёт
 	status2 := svc.Status(context.Background())
-	require.Equal(t, StatusRunning, status2)
+	require.Equal(t, StatusRunning, status2.Status)
+	require.False(t, status2.Progress.StartedAt.IsZero())
+
+	svc.planned(3)
+	status2 = svc.Status(context.Background())
+	require.Equal(t, StatusRunning, status2.Status)
+	require.Equal(t, 3, status2.Progress.Total)
+
+	svc.track(func(p *UpdateProgress) { p.Stored++ })
+	run := UpdateRun{Mode: ModeUpdate, Status: RunCompleted, FinishedAt: time.Now(), Stored: 1}
+	svc.finishRun(run)
+
+	status3 := svc.Status(context.Background())
+	require.Equal(t, StatusIdle, status3.Status)
+	require.Equal(t, 1, status3.Progress.Stored)
+	require.Equal(t, run, status3.LastRun)
+
+	// итог прошлого запуска виден и во время следующего
+	svc.startRun(func() {})
+	require.Equal(t, run, svc.Status(context.Background()).LastRun)
+	svc.finishRun(UpdateRun{Status: RunFailed, Error: "xkcd error"})
+
+	status4 := svc.Status(context.Background())
+	require.Equal(t, StatusFailed, status4.Status)
+	require.Equal(t, "xkcd error", status4.LastRun.Error)
+}
+
+func TestStatus_RetriesPerRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	xkcd := NewMockSource(ctrl)
+	svc, err := NewService(logger, NewMockDB(ctrl), single(t, xkcd), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	// источник считает повторы с запуска процесса
+	gomock.InOrder(
+		xkcd.EXPECT().State().Return(XKCDState{Retries: 5, Breaker: BreakerClosed}),
+		xkcd.EXPECT().State().Return(XKCDState{Retries: 5, Breaker: BreakerClosed}),
+		xkcd.EXPECT().State().Return(XKCDState{Retries: 7, Breaker: BreakerClosed}),
+		xkcd.EXPECT().State().Return(XKCDState{Retries: 7, Breaker: BreakerClosed}),
+	)
+
+	require.Equal(t, 5, svc.Status(context.Background()).XKCD.Retries)
+
+	svc.startRun(func() {})
+	require.Equal(t, 2, svc.Status(context.Background()).XKCD.Retries)
+
+	// после запуска видны повторы последнего запуска
+	svc.finishRun(UpdateRun{Status: RunCompleted})
+	require.Equal(t, 2, svc.Status(context.Background()).XKCD.Retries)
+}
+
+func TestRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	words := NewMockWords(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+	db.EXPECT().NotifyChanged(gomock.Any(), "refresh").Return(nil)
+
+	ctx := context.Background()
+	xkcd.EXPECT().State().Return(XKCDState{}).AnyTimes()
+
+	same := XKCDInfo{ID: 1, Description: "one", Metadata: Metadata{Alt: "alt"}}
+	fixed := XKCDInfo{ID: 2, Description: "two", Metadata: Metadata{Alt: "fixed alt"}}
+
+	db.EXPECT().Hashes(gomock.Any(), 1, 2).Return(map[int]string{
+		1: same.Hash(),
+		2: XKCDInfo{ID: 2, Description: "two", Metadata: Metadata{Alt: "typo alt"}}.Hash(),
+	}, nil)
+	xkcd.EXPECT().Get(gomock.Any(), 1).Return(same, nil)
+	xkcd.EXPECT().Get(gomock.Any(), 2).Return(fixed, nil)
+	words.EXPECT().Norm(gomock.Any(), "two").Return([]string{"two"}, nil)
+	db.EXPECT().AddBatch(gomock.Any(), []Comics{{
+		ID: 2, Words: []string{"two"}, Hash: fixed.Hash(), Source: "xkcd", Metadata: fixed.Metadata,
+	}}).Return(nil)
+
+	db.EXPECT().AddRun(gomock.Any(), gomock.Cond(func(run UpdateRun) bool {
+		return run.Mode == ModeRefresh && run.Stored == 1 && run.Skipped == 1
+	}))
+	result, err := svc.Refresh(ctx, RefreshOptions{From: 1, To: 2})
+	require.NoError(t, err)
+	require.Equal(t, RefreshResult{Checked: 2, Changed: 1}, result)
+
+	progress := svc.Status(ctx).Progress
+	require.Equal(t, 2, progress.Fetched)
+	require.Equal(t, 1, progress.Unchanged)
+	require.Equal(t, 1, progress.Stored)
+}
+
+func TestRefresh_BadRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc, err := NewService(logger, NewMockDB(ctrl), single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	for _, opts := range []RefreshOptions{{From: -1}, {To: -1}, {From: 10, To: 5}} {
+		_, err := svc.Refresh(context.Background(), opts)
+		require.ErrorIs(t, err, ErrBadArguments)
+	}
+}
+
+func TestRefresh_DBError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+	svc, err := NewService(logger, db, single(t, xkcd), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+
+	db.EXPECT().Hashes(gomock.Any(), 0, 0).Return(nil, errors.New("db error"))
+
+	expectRun(db, ModeRefresh, RunFailed)
+	_, err = svc.Refresh(context.Background(), RefreshOptions{})
+	require.Error(t, err)
+}
+
+func TestXKCDInfo_Hash(t *testing.T) {
+	info := XKCDInfo{ID: 1, URL: "url", Description: "description", Metadata: Metadata{Transcript: "text"}}
+	require.Equal(t, info.Hash(), info.Hash())
+
+	changed := info
+	changed.Transcript = "fixed text"
+	require.NotEqual(t, info.Hash(), changed.Hash())
+
+	// разделители не дают сдвинуть текст между полями
+	shifted := info
+	shifted.URL, shifted.Description = "urld", "escription"
+	require.NotEqual(t, info.Hash(), shifted.Hash())
+}
+
+func TestAdd_FlushBySize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1),
+		BatchOptions{Size: 2, FlushInterval: time.Hour}, 0, nil)
+	require.NoError(t, err)
+
+	gomock.InOrder(
+		db.EXPECT().AddBatch(gomock.Any(), []Comics{{ID: 1}, {ID: 2}}).Return(nil),
+		db.EXPECT().AddBatch(gomock.Any(), []Comics{{ID: 3}}).Return(nil),
+	)
+
+	in := make(chan Comics, 3)
+	in <- Comics{ID: 1}
+	in <- Comics{ID: 2}
+	in <- Comics{ID: 3}
+	close(in)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	svc.add(context.Background(), newMeters(svc.pipeline), nil, in, &wg)
+	wg.Wait()
+
+	require.Equal(t, 3, svc.state.Progress.Stored)
+}
+
+func TestAdd_FlushByInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1),
+		BatchOptions{Size: 10, FlushInterval: 10 * time.Millisecond}, 0, nil)
+	require.NoError(t, err)
+
+	flushed := make(chan struct{})
+	db.EXPECT().AddBatch(gomock.Any(), []Comics{{ID: 1}}).
+		DoAndReturn(func(context.Context, []Comics) error {
+			close(flushed)
+			return nil
+		})
+
+	in := make(chan Comics)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go svc.add(context.Background(), newMeters(svc.pipeline), nil, in, &wg)
+
+	in <- Comics{ID: 1}
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Fatal("batch was not flushed by interval")
+	}
+
+	// канал закрыт при пустой пачке - лишней записи быть не должно
+	close(in)
+	wg.Wait()
+}
+
+func TestAdd_BatchError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1),
+		BatchOptions{Size: 2, FlushInterval: time.Hour}, 0, nil)
+	require.NoError(t, err)
+
+	db.EXPECT().AddBatch(gomock.Any(), []Comics{{ID: 1}, {ID: 2}}).Return(errors.New("db error"))
+	db.EXPECT().AddFailure(gomock.Any(), Failure{ID: 1, Stage: StageAdd, Error: "db error"}).Return(nil)
+	db.EXPECT().AddFailure(gomock.Any(), Failure{ID: 2, Stage: StageAdd, Error: "db error"}).Return(nil)
+
+	in := make(chan Comics, 2)
+	in <- Comics{ID: 1}
+	in <- Comics{ID: 2}
+	close(in)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	svc.add(context.Background(), newMeters(svc.pipeline), nil, in, &wg)
+	wg.Wait()
+
+	progress := svc.state.Progress
+	require.Equal(t, 0, progress.Stored)
+	require.Equal(t, 2, progress.Failed)
 }
 
 func TestDrop(t *testing.T) {
@@ -159,16 +752,76 @@ func TestDrop(t *testing.T) {
 	defer ctrl.Finish()
 
 	db := NewMockDB(ctrl)
-	xkcd := NewMockXKCD(ctrl)
+	xkcd := NewMockSource(ctrl)
 	words := NewMockWords(ctrl)
 
 	concurrency := 1
-	svc, err := NewService(logger, db, xkcd, words, concurrency)
+	svc, err := NewService(logger, db, single(t, xkcd), words, pipeline(concurrency), batch, 0, nil)
+	require.NoError(t, err)
+	db.EXPECT().NotifyChanged(gomock.Any(), "drop").Return(nil)
+
+	ctx := context.Background()
+	db.EXPECT().Drop(gomock.Any(), "").Return(Snapshot{}, nil)
+
+	snapshot, err := svc.Drop(ctx)
+	require.NoError(t, err)
+	require.Empty(t, snapshot.Name)
+}
+
+func TestUpdate_LastIDFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
 	require.NoError(t, err)
 
 	ctx := context.Background()
-	db.EXPECT().Drop(gomock.Any()).Return(nil)
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	xkcd.EXPECT().LastID(gomock.Any()).Return(0, errors.New("xkcd error"))
+	expectRun(db, ModeUpdate, RunFailed)
 
-	err = svc.Drop(ctx)
+	require.Error(t, svc.Update(ctx, UpdateOptions{}))
+
+	// ошибка видна в статусе и после ответа на сам запрос
+	state := svc.Status(ctx)
+	require.Equal(t, StatusFailed, state.Status)
+	require.Contains(t, state.LastRun.Error, "xkcd error")
+	require.False(t, state.LastRun.FinishedAt.IsZero())
+}
+
+func TestCancel_Planning(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	xkcd := NewMockSource(ctrl)
+
+	svc, err := NewService(logger, db, single(t, xkcd), NewMockWords(ctrl), pipeline(1), batch, 0, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	xkcd.EXPECT().State().Return(XKCDState{Breaker: BreakerClosed}).AnyTimes()
+	planning := make(chan struct{})
+	xkcd.EXPECT().LastID(gomock.Any()).DoAndReturn(func(ctx context.Context) (int, error) {
+		close(planning)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	expectRun(db, ModeUpdate, RunCancelled)
+
+	result := make(chan error)
+	go func() {
+		result <- svc.Update(ctx, UpdateOptions{})
+	}()
+
+	<-planning
+	require.Equal(t, StatusRunning, svc.Status(ctx).Status)
+
+	state, err := svc.Cancel(ctx)
 	require.NoError(t, err)
+	require.Equal(t, StatusCancelled, state.Status)
+	require.ErrorIs(t, <-result, ErrCancelled)
 }
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Drop удаляет все комиксы. Если снимки включены, комиксы сначала сохраняются
+// в снимок drop-<время>, а лишние старые снимки удаляются. Возвращает снимок,
+// пустой, если сохранять было нечего.
+func (s *Service) Drop(ctx context.Context) (Snapshot, error) {
+	// иначе идущее обновление допишет комиксы в очищенную таблицу
+	unlock, _, err := s.lock(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer unlock()
+
+	if s.snapshots == 0 {
+		snapshot, err := s.db.Drop(ctx, "")
+		if err != nil {
+			return Snapshot{}, err
+		}
+		s.changed(ctx, "drop")
+		return snapshot, nil
+	}
+
+	name := "drop-" + time.Now().UTC().Format("20060102T150405.000Z")
+	snapshot, err := s.db.Drop(ctx, name)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	s.log.Info("comics dropped", "snapshot", snapshot.Name, "comics", snapshot.Comics)
+	s.changed(ctx, "drop")
+
+	// комиксы уже удалены и сохранены, лишний снимок не повод для ошибки
+	if err := s.db.PruneSnapshots(ctx, s.snapshots); err != nil {
+		s.log.Warn("failed to prune snapshots", "error", err)
+	}
+	return snapshot, nil
+}
+
+func (s *Service) Snapshots(ctx context.Context) ([]Snapshot, error) {
+	return s.db.Snapshots(ctx)
+}
+
+// Restore заменяет текущие комиксы содержимым снимка
+func (s *Service) Restore(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty snapshot name", ErrBadArguments)
+	}
+
+	// восстановление не смешиваем с обновлением
+	unlock, _, err := s.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.db.Restore(ctx, name); err != nil {
+		return err
+	}
+	s.log.Info("snapshot restored", "snapshot", name)
+	s.changed(ctx, "restore")
+	return nil
+}
+
+func (s *Service) DeleteSnapshot(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty snapshot name", ErrBadArguments)
+	}
+	return s.db.DeleteSnapshot(ctx, name)
+}
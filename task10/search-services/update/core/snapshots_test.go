@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDrop_Snapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 3, nil)
+	require.NoError(t, err)
+	db.EXPECT().NotifyChanged(gomock.Any(), "drop").Return(nil)
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var name string
+	db.EXPECT().Drop(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, snapshot string) (Snapshot, error) {
+		name = snapshot
+		return Snapshot{Name: snapshot, Comics: 10, CreatedAt: created}, nil
+	})
+	// ошибка чистки старых снимков не ломает Drop
+	db.EXPECT().PruneSnapshots(gomock.Any(), 3).Return(errors.New("db error"))
+
+	snapshot, err := svc.Drop(context.Background())
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(name, "drop-"), name)
+	require.Equal(t, Snapshot{Name: name, Comics: 10, CreatedAt: created}, snapshot)
+
+	expected := errors.New("db error")
+	db.EXPECT().Drop(gomock.Any(), gomock.Any()).Return(Snapshot{}, expected)
+
+	_, err = svc.Drop(context.Background())
+	require.ErrorIs(t, err, expected)
+}
+
+func TestNewService_Snapshots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, err := NewService(logger, NewMockDB(ctrl), single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, -1, nil)
+	require.Error(t, err)
+}
+
+func TestRestore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 3, nil)
+	require.NoError(t, err)
+	db.EXPECT().NotifyChanged(gomock.Any(), "restore").Return(nil)
+
+	ctx := context.Background()
+
+	db.EXPECT().Restore(gomock.Any(), "drop-1").Return(nil)
+	require.NoError(t, svc.Restore(ctx, "drop-1"))
+
+	db.EXPECT().Restore(gomock.Any(), "missing").Return(ErrNotFound)
+	require.ErrorIs(t, svc.Restore(ctx, "missing"), ErrNotFound)
+
+	require.ErrorIs(t, svc.Restore(ctx, ""), ErrBadArguments)
+
+	// пока идёт обновление, восстанавливать нельзя
+	svc.mx.Lock()
+	_, err = svc.Drop(ctx)
+	require.ErrorIs(t, err, ErrAlreadyExists)
+	require.ErrorIs(t, svc.Restore(ctx, "drop-1"), ErrAlreadyExists)
+	svc.mx.Unlock()
+}
+
+func TestSnapshots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := NewMockDB(ctrl)
+	svc, err := NewService(logger, db, single(t, NewMockSource(ctrl)), NewMockWords(ctrl), pipeline(1), batch, 3, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	snapshots := []Snapshot{{Name: "drop-2", Comics: 2}, {Name: "drop-1", Comics: 1}}
+	db.EXPECT().Snapshots(gomock.Any()).Return(snapshots, nil)
+
+	result, err := svc.Snapshots(ctx)
+	require.NoError(t, err)
+	require.Equal(t, snapshots, result)
+
+	db.EXPECT().DeleteSnapshot(gomock.Any(), "drop-1").Return(nil)
+	require.NoError(t, svc.DeleteSnapshot(ctx, "drop-1"))
+	require.ErrorIs(t, svc.DeleteSnapshot(ctx, ""), ErrBadArguments)
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"os"
@@ -12,6 +14,7 @@ import (
 	"google.golang.org/grpc/reflection"
 	updatepb "yadro.com/course/proto/update"
 	"yadro.com/course/update/adapters/db"
+	"yadro.com/course/update/adapters/dir"
 	updategrpc "yadro.com/course/update/adapters/grpc"
 	"yadro.com/course/update/adapters/words"
 	"yadro.com/course/update/adapters/xkcd"
@@ -27,10 +30,18 @@ func main() {
 	flag.Parse()
 	cfg := config.MustLoad(configPath)
 
+	// без аргументов запускается сервер, иначе выполняется разовая команда (см. runCommand)
+	command := flag.Arg(0)
+
 	// logger
-	log := mustMakeLogger(cfg.LogLevel)
+	// архив может идти в stdout, поэтому логи команд пишутся в stderr
+	logOut := os.Stdout
+	if command != "" {
+		logOut = os.Stderr
+	}
+	log := mustMakeLogger(cfg.LogLevel, logOut)
 
-	log.Info("starting server")
+	log.Info("starting", "command", command, "replica", cfg.Replica)
 	log.Debug("debug messages are enabled")
 
 	// database adapter
@@ -45,10 +56,10 @@ func main() {
 		os.Exit(1)
 	}
 
-	// xkcd adapter
-	xkcd, err := xkcd.NewClient(cfg.XKCD.URL, cfg.XKCD.Timeout, log)
+	// comic sources
+	sources, err := newSources(cfg, log)
 	if err != nil {
-		log.Error("failed create XKCD client", "error", err)
+		log.Error("failed create comic sources", "error", err)
 		os.Exit(1)
 	}
 
@@ -60,19 +71,79 @@ func main() {
 	}
 
 	// service
-	updater, err := core.NewService(log, storage, xkcd, words, cfg.XKCD.Concurrency)
+	pipeline := core.PipelineOptions{
+		Fetch: core.StageOptions{Workers: cfg.XKCD.Concurrency, Buffer: cfg.Pipeline.FetchBuffer},
+		Norm:  core.StageOptions{Workers: cfg.Pipeline.NormWorkers, Buffer: cfg.Pipeline.NormBuffer},
+		Add:   core.StageOptions{Workers: cfg.Pipeline.AddWorkers, Buffer: cfg.Pipeline.AddBuffer},
+	}
+	updater, err := core.NewService(log, storage, sources, words, pipeline, core.BatchOptions{
+		Size:          cfg.Batch.Size,
+		FlushInterval: cfg.Batch.FlushInterval,
+	}, cfg.Snapshots.Keep, db.NewLease(storage, cfg.Replica))
 	if err != nil {
 		log.Error("failed create Update service", "error", err)
 		os.Exit(1)
 	}
 
-	if err := run(log, updater, cfg); err != nil {
+	if command != "" {
+		if err := runCommand(log, updater, command, flag.Arg(1)); err != nil {
+			log.Error("command failed", "command", command, "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// scheduler
+	scheduler, err := core.NewUpdateScheduler(log, updater, cfg.XKCD.CheckPeriod)
+	if err != nil {
+		log.Error("failed create Update scheduler", "error", err)
+		os.Exit(1)
+	}
+
+	if err := run(log, updater, scheduler, cfg); err != nil {
 		log.Error("failed to serve", "erorr", err)
 		os.Exit(1)
 	}
 }
 
-func run(log *slog.Logger, updater *core.Service, cfg config.Config) error {
+func newSources(cfg config.Config, log *slog.Logger) (*core.Registry, error) {
+	sources := core.NewRegistry()
+
+	for _, src := range cfg.Sources {
+		var source core.Source
+		var err error
+
+		switch src.Type {
+		case config.SourceXKCD:
+			// подкаталог кэша на каждый upstream клиент заводит сам
+			source, err = xkcd.NewClient(src.URL, cfg.XKCD.Timeout, xkcd.Options{
+				Retries:          cfg.XKCD.Retries,
+				Backoff:          cfg.XKCD.RetryBackoff,
+				MaxBackoff:       cfg.XKCD.RetryMaxBackoff,
+				BreakerThreshold: cfg.XKCD.BreakerThreshold,
+				BreakerCooldown:  cfg.XKCD.BreakerCooldown,
+				RPS:              cfg.XKCD.RPS,
+				Burst:            cfg.XKCD.Burst,
+				CacheDir:         cfg.XKCD.CacheDir,
+			}, log)
+		case config.SourceDir:
+			source, err = dir.New(src.Path, log)
+		default:
+			err = fmt.Errorf("unknown source type %q", src.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", src.Name, err)
+		}
+
+		if err := sources.Register(src.Name, src.IDOffset, source); err != nil {
+			return nil, err
+		}
+	}
+
+	return sources, nil
+}
+
+func run(log *slog.Logger, updater *core.Service, scheduler *core.UpdateScheduler, cfg config.Config) error {
 
 	// grpc server
 	listener, err := net.Listen("tcp", cfg.Address)
@@ -82,13 +153,15 @@ func run(log *slog.Logger, updater *core.Service, cfg config.Config) error {
 	}
 
 	s := grpc.NewServer()
-	updatepb.RegisterUpdateServer(s, updategrpc.NewServer(updater))
+	updatepb.RegisterUpdateServer(s, updategrpc.NewServer(updater, scheduler))
 	reflection.Register(s)
 
 	// context for Ctrl-C
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
+	scheduler.Start(ctx)
+
 	go func() {
 		<-ctx.Done()
 		log.Debug("shutting down server")
@@ -98,7 +171,7 @@ func run(log *slog.Logger, updater *core.Service, cfg config.Config) error {
 	return s.Serve(listener)
 }
 
-func mustMakeLogger(logLevel string) *slog.Logger {
+func mustMakeLogger(logLevel string, out io.Writer) *slog.Logger {
 	var level slog.Level
 	switch logLevel {
 	case "DEBUG":
@@ -110,6 +183,6 @@ func mustMakeLogger(logLevel string) *slog.Logger {
 	default:
 		panic("unknown log level: " + logLevel)
 	}
-	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	handler := slog.NewTextHandler(out, &slog.HandlerOptions{Level: level})
 	return slog.New(handler)
 }